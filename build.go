@@ -3,25 +3,31 @@ package mri
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Masterminds/semver"
+	"github.com/paketo-buildpacks/mri/facts"
 	"github.com/paketo-buildpacks/packit/v2"
-	"github.com/paketo-buildpacks/packit/v2/cargo"
 	"github.com/paketo-buildpacks/packit/v2/chronos"
 	"github.com/paketo-buildpacks/packit/v2/pexec"
 	"github.com/paketo-buildpacks/packit/v2/postal"
-	"github.com/paketo-buildpacks/packit/v2/sbom"
 	"github.com/paketo-buildpacks/packit/v2/scribe"
+	"github.com/paketo-buildpacks/packit/v2/servicebindings"
 )
 
 //go:generate faux --interface EntryResolver --output fakes/entry_resolver.go
 //go:generate faux --interface DependencyManager --output fakes/dependency_manager.go
 //go:generate faux --interface Executable --output fakes/executable.go
 //go:generate faux --interface SBOMGenerator --output fakes/sbom_generator.go
+//go:generate faux --interface BindingResolver --output fakes/binding_resolver.go
+//go:generate faux --interface InstallationVerifier --output fakes/installation_verifier.go
+//go:generate faux --interface Compiler --output fakes/compiler.go
+//go:generate faux --interface Transport --output fakes/transport.go
 
 type EntryResolver interface {
 	Resolve(string, []packit.BuildpackPlanEntry, []interface{}) (packit.BuildpackPlanEntry, []packit.BuildpackPlanEntry)
@@ -39,14 +45,33 @@ type Executable interface {
 }
 
 type SBOMGenerator interface {
-	GenerateFromDependency(dependency postal.Dependency, dir string) (sbom.SBOM, error)
+	GenerateFromDependency(dependency postal.Dependency, dir string) (InstallationSBOM, error)
+}
+
+type BindingResolver interface {
+	Resolve(typ, provider, platformDir string) ([]servicebindings.Binding, error)
+}
+
+type InstallationVerifier interface {
+	Verify(layerPath string, dependency postal.Dependency) (VerificationReport, error)
+}
+
+type Compiler interface {
+	Compile(dependency postal.Dependency, layerPath string, flags []string) error
+}
+
+type Transport interface {
+	Drop(root, uri string) (io.ReadCloser, error)
 }
 
 func Build(
 	entries EntryResolver,
 	dependencies DependencyManager,
+	bindings BindingResolver,
+	compiler Compiler,
 	gem Executable,
 	sbomGenerator SBOMGenerator,
+	verifier InstallationVerifier,
 	logger scribe.Emitter,
 	clock chronos.Clock,
 ) packit.BuildFunc {
@@ -54,7 +79,16 @@ func Build(
 		logger.Title("%s %s", context.BuildpackInfo.Name, context.BuildpackInfo.Version)
 		logger.Process("Resolving MRI version")
 
-		entry, allEntries := entries.Resolve("mri", context.Plan.Entries, []interface{}{"BP_MRI_VERSION", "buildpack.yml"})
+		entry, allEntries := entries.Resolve("mri", context.Plan.Entries, []interface{}{
+			"BP_MRI_VERSION",
+			BuildpackYMLSource,
+			GemfileSource,
+			GemfileLockSource,
+			RubyVersionSource,
+			ToolVersionsSource,
+			MiseTOMLSource,
+			GemspecSource,
+		})
 		logger.Candidates(allEntries)
 
 		// NOTE: this is to override that the dependency is called "ruby" in the
@@ -63,18 +97,195 @@ func Build(
 		entry.Name = "ruby"
 		version, _ := entry.Metadata["version"].(string)
 
-		dependency, err := dependencies.Resolve(filepath.Join(context.CNBPath, "buildpack.toml"), entry.Name, version, context.Stack)
+		dependency, custom, err := resolveCustomArtifact(bindings, context.Platform.Path, context.CNBPath, context.Stack)
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		compile, err := LoadCompileConfig()
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		resolution, err := LoadVersionResolution()
 		if err != nil {
 			return packit.BuildResult{}, err
 		}
 
+		var compiled bool
+		if !custom {
+			requirements := VersionRequirements(allEntries)
+			if resolution == IntersectResolution && len(requirements) > 1 {
+				catalog, err := NewDependencyCatalog(filepath.Join(context.CNBPath, "buildpack.toml"), entry.Name, context.Stack)
+				if err != nil {
+					return packit.BuildResult{}, err
+				}
+
+				if compile {
+					catalog = catalog.WithSources()
+				}
+
+				version, err = catalog.Intersect(requirements, clock.Now())
+				if err != nil {
+					return packit.BuildResult{}, err
+				}
+
+				logger.Subprocess("Resolved the intersection of the requested versions to %s:", version)
+				for _, requirement := range requirements {
+					logger.Action("%s requests %q", requirement.Source, requirement.Constraint)
+				}
+				logger.Break()
+			}
+
+			if IsVersionAlias(version) {
+				catalog, err := NewDependencyCatalog(filepath.Join(context.CNBPath, "buildpack.toml"), entry.Name, context.Stack)
+				if err != nil {
+					return packit.BuildResult{}, err
+				}
+
+				// Versions that are only available as source are compiled when no
+				// precompiled artifact matches, so they can be selected as well.
+				if compile {
+					catalog = catalog.WithSources()
+				}
+
+				alias := version
+				version, err = catalog.ResolveAlias(alias, clock.Now())
+				if err != nil {
+					return packit.BuildResult{}, err
+				}
+
+				logger.Subprocess("Resolved version alias %q to %s", alias, version)
+				logger.Break()
+			}
+
+			dependency, err = dependencies.Resolve(filepath.Join(context.CNBPath, "buildpack.toml"), entry.Name, version, context.Stack)
+			if err != nil {
+				// Explain the failure using the buildpack.toml when it can be read;
+				// otherwise the original error is the best we can do.
+				catalog, catalogErr := NewDependencyCatalog(filepath.Join(context.CNBPath, "buildpack.toml"), entry.Name, context.Stack)
+				if catalogErr != nil {
+					return packit.BuildResult{}, err
+				}
+
+				if !compile {
+					return packit.BuildResult{}, catalog.Explain(version, err)
+				}
+
+				source, sourceErr := catalog.ResolveSource(version)
+				if sourceErr != nil {
+					return packit.BuildResult{}, fmt.Errorf("%w\n\nfailed to compile MRI from source: %w", catalog.Explain(version, err), sourceErr)
+				}
+
+				dependency = SourceDependency(source)
+				dependency.Stacks = []string{context.Stack}
+				compiled = true
+			}
+		}
+
 		// NOTE: this is to override that the dependency is called "ruby" in the
 		// buildpack.toml. We can remove this once we update our own dependencies
 		// and can name it however we like.
 		dependency.ID = "mri"
 		dependency.Name = "MRI"
 
-		logger.SelectedDependency(entry, dependency, clock.Now())
+		eolPolicy, err := LoadEOLPolicy()
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		if custom {
+			logger.Subprocess("Selected MRI version (using %s binding): %s", CustomArtifactBindingType, dependency.Version)
+
+			if version != "" && !IsVersionAlias(version) {
+				constraint, err := semver.NewConstraint(version)
+				if err == nil && !constraint.Check(semver.MustParse(dependency.Version)) {
+					logger.Subprocess("WARNING: MRI %s from the %s binding does not satisfy the requested version %q.", dependency.Version, CustomArtifactBindingType, version)
+				}
+			}
+		} else {
+			source, ok := entry.Metadata["version-source"].(string)
+			if !ok {
+				source = "<unknown>"
+			}
+			logger.Subprocess("Selected %s version (using %s): %s", dependency.Name, source, dependency.Version)
+		}
+
+		// scribe's SelectedDependency warns within a fixed 30 days of the
+		// deprecation date, so the warnings are printed here instead to follow
+		// $BP_MRI_EOL_WARNING_DAYS.
+		switch {
+		case eolPolicy.IsEOL(dependency, clock.Now()):
+			logger.Action("Version %s of %s is deprecated.", dependency.Version, dependency.Name)
+			logger.Action("Migrate your application to a supported version of %s.", dependency.Name)
+		case eolPolicy.IsNearEOL(dependency, clock.Now()):
+			logger.Action("Version %s of %s will be deprecated after %s.", dependency.Version, dependency.Name, dependency.DeprecationDate.Format("2006-01-02"))
+			logger.Action("Migrate your application to a supported version of %s before this time.", dependency.Name)
+		}
+		logger.Break()
+
+		if compiled {
+			logger.Subprocess("No precompiled MRI %s is available for the %s stack, it will be compiled from source", dependency.Version, context.Stack)
+			logger.Break()
+		}
+
+		err = eolPolicy.Check(dependency, clock.Now())
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		yjit, err := LoadYJITConfig()
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		if yjit.Enabled && !SupportsYJIT(dependency.Version, dependency.Arch) {
+			logger.Subprocess("WARNING: YJIT is not supported by MRI %s on this architecture and will not be enabled.", dependency.Version)
+			logger.Subprocess("YJIT requires MRI 3.2 or later on amd64 or arm64.")
+			logger.Break()
+
+			yjit = YJITConfig{}
+		}
+
+		slim, err := LoadSlimLaunchConfig()
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		verify, err := LoadVerifyConfig()
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		reportEnabled, err := LoadBuildReportConfig()
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		provenanceEnabled, err := LoadProvenanceConfig()
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		vex, err := LoadVEX(filepath.Join(context.CNBPath, VEXFile))
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+		vulnerabilities := vex.Applicable(dependency)
+
+		disallowDowngrade, err := LoadDisallowDowngradeConfig()
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		origin := BuildpackTOMLOrigin
+		switch {
+		case custom:
+			origin = CustomArtifactOrigin
+		case compiled:
+			origin = CompiledSourceOrigin
+		}
+		buildReport := NewBuildReport(context, entry, allEntries, dependency, origin)
 
 		source, _ := entry.Metadata["version-source"].(string)
 		if source == "buildpack.yml" {
@@ -93,6 +304,12 @@ func Build(
 		logger.Debug.Subprocess(mriLayer.Path)
 		logger.Debug.Break()
 
+		previousVersion, _ := mriLayer.Metadata[facts.VersionKey].(string)
+		change := VersionChange{From: previousVersion, To: dependency.Version}
+		if change.IsDowngrade() && disallowDowngrade {
+			return packit.BuildResult{}, DowngradeError{change}
+		}
+
 		legacySBOM := dependencies.GenerateBillOfMaterials(dependency)
 		launch, build := entries.MergeLayerTypes("mri", context.Plan.Entries)
 
@@ -106,7 +323,11 @@ func Build(
 			launchMetadata.BOM = legacySBOM
 		}
 
-		cachedChecksum, ok := mriLayer.Metadata[DepKey].(string)
+		var additionalLayers []packit.Layer
+
+		// At launch, the memory settings are tuned to the container limits by
+		// the optimize-memory exec.d executable.
+		execD := []string{filepath.Join(context.CNBPath, "bin", "optimize-memory")}
 
 		dependencyChecksum := dependency.Checksum
 
@@ -115,14 +336,97 @@ func Build(
 			dependencyChecksum = dependency.SHA256
 		}
 
-		if ok && cargo.Checksum(dependencyChecksum).MatchString(cachedChecksum) {
+		// Compiled installations are keyed by the source they were built from
+		// and the options it was configured with.
+		var configureFlags []string
+		if compiled {
+			configureFlags = ConfigureFlags(dependency.Version, yjit.Enabled)
+			dependencyChecksum = dependency.SourceChecksum
+		}
+
+		// A compiled installation has the same source checksum and configure
+		// flags on every stack and architecture, so these are inputs too.
+		inputs := CacheInputs{
+			"dependency": dependencyInput(dependencyChecksum),
+			"yjit":       yjit.String(),
+			"verify":     strconv.FormatBool(verify),
+			"stack":      context.Stack,
+			"arch":       targetArch(dependency.Arch),
+		}
+
+		if compiled {
+			inputs["configure-flags"] = strings.Join(configureFlags, " ")
+		}
+
+		// The VEX statements only end up in the SBOM, which is written along
+		// with the layer, so they are recorded when there are any.
+		if len(vulnerabilities) > 0 {
+			inputs["vex"], err = vexInput(vulnerabilities)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+		}
+
+		invalidated := inputs.Invalidated(mriLayer.Metadata)
+		if invalidated != "" && len(mriLayer.Metadata) > 0 {
+			logger.Debug.Process("Cached layer %s cannot be reused: %s", mriLayer.Path, invalidated)
+			logger.Debug.Break()
+		}
+
+		if invalidated == "" {
 			logger.Process("Reusing cached layer %s", mriLayer.Path)
 			logger.Break()
 
 			mriLayer.Launch, mriLayer.Build, mriLayer.Cache = launch, build, build
+			mriLayer.ExecD = execD
+
+			err = publishFacts(&mriLayer, dependency.Version)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			if slim && launch {
+				launchLayer, err := slimLaunchLayer(context, mriLayer, dependency, vulnerabilities, sbomGenerator, logger, clock)
+				if err != nil {
+					return packit.BuildResult{}, err
+				}
+
+				mriLayer.Launch, mriLayer.Cache, mriLayer.ExecD = false, true, nil
+				additionalLayers = append(additionalLayers, launchLayer)
+			}
+
+			if reportEnabled {
+				buildReport.Reused = true
+				reportLayer, err := writeBuildReport(context, buildReport)
+				if err != nil {
+					return packit.BuildResult{}, err
+				}
+
+				logger.Debug.Process("Wrote build report to %s", filepath.Join(reportLayer.Path, BuildReportFile))
+				logger.Debug.Break()
+
+				additionalLayers = append(additionalLayers, reportLayer)
+			}
+
+			if provenanceEnabled {
+				provenance, err := NewProvenance(context, dependency, origin, configureFlags, mriLayer.Path)
+				if err != nil {
+					return packit.BuildResult{}, err
+				}
+
+				provenanceLayer, err := writeProvenance(context, provenance)
+				if err != nil {
+					return packit.BuildResult{}, err
+				}
+
+				logger.Debug.Process("Wrote provenance to %s", filepath.Join(provenanceLayer.Path, ProvenanceFile))
+				logger.Debug.Break()
+
+				additionalLayers = append(additionalLayers, provenanceLayer)
+			}
 
 			return packit.BuildResult{
-				Layers: []packit.Layer{mriLayer},
+				Layers: append([]packit.Layer{mriLayer}, additionalLayers...),
 				Build:  buildMetadata,
 				Launch: launchMetadata,
 			}, nil
@@ -130,6 +434,19 @@ func Build(
 
 		logger.Process("Executing build process")
 
+		switch {
+		case change.IsUpgrade():
+			logger.Subprocess("Upgrading MRI %s → %s", change.From, change.To)
+		case change.IsDowngrade():
+			logger.Subprocess("WARNING: Downgrading MRI %s → %s", change.From, change.To)
+		default:
+			previousStack, _ := mriLayer.Metadata[StackKey].(string)
+			previousArch, _ := mriLayer.Metadata[ArchKey].(string)
+			if previousStack != "" && (previousStack != context.Stack || previousArch != targetArch(dependency.Arch)) {
+				logger.Subprocess("Reinstalling MRI for %s (%s), previously installed for %s (%s)", context.Stack, targetArch(dependency.Arch), previousStack, previousArch)
+			}
+		}
+
 		mriLayer, err = mriLayer.Reset()
 		if err != nil {
 			return packit.BuildResult{}, err
@@ -137,8 +454,16 @@ func Build(
 
 		mriLayer.Launch, mriLayer.Build, mriLayer.Cache = launch, build, build
 
-		logger.Subprocess("Installing MRI %s", dependency.Version)
 		duration, err := clock.Measure(func() error {
+			if compiled {
+				logger.Subprocess("Compiling MRI %s from source", dependency.Version)
+				logger.Debug.Subprocess("Installation path: %s", mriLayer.Path)
+				logger.Debug.Subprocess("Source URI: %s", dependency.Source)
+				logger.Debug.Subprocess("Configure flags: %s", strings.Join(configureFlags, " "))
+				return compiler.Compile(dependency, mriLayer.Path, configureFlags)
+			}
+
+			logger.Subprocess("Installing MRI %s", dependency.Version)
 			logger.Debug.Subprocess("Installation path: %s", mriLayer.Path)
 			logger.Debug.Subprocess("Source URI: %s", dependency.URI)
 			return dependencies.Deliver(dependency, context.CNBPath, mriLayer.Path, context.Platform.Path)
@@ -150,8 +475,25 @@ func Build(
 		logger.Action("Completed in %s", duration.Round(time.Millisecond))
 		logger.Break()
 
+		if verify {
+			logger.Process("Verifying MRI installation")
+			report, err := verifier.Verify(mriLayer.Path, dependency)
+			if err != nil {
+				return packit.BuildResult{}, fmt.Errorf("failed to verify MRI installation: %w", err)
+			}
+
+			for _, line := range strings.Split(report.String(), "\n") {
+				logger.Subprocess(line)
+			}
+			logger.Break()
+
+			if report.Failed() {
+				return packit.BuildResult{}, VerificationError{Version: dependency.Version, Report: report}
+			}
+		}
+
 		logger.GeneratingSBOM(mriLayer.Path)
-		var sbomContent sbom.SBOM
+		var sbomContent InstallationSBOM
 		duration, err = clock.Measure(func() error {
 			sbomContent, err = sbomGenerator.GenerateFromDependency(dependency, mriLayer.Path)
 			return err
@@ -159,6 +501,10 @@ func Build(
 		if err != nil {
 			return packit.BuildResult{}, err
 		}
+		sbomContent.Vulnerabilities = vulnerabilities
+		for _, statement := range vulnerabilities {
+			logger.Debug.Subprocess("Annotating %s as %s", statement.ID, statement.State)
+		}
 
 		logger.Action("Completed in %s", duration.Round(time.Millisecond))
 		logger.Break()
@@ -170,31 +516,114 @@ func Build(
 		}
 
 		mriLayer.Metadata = map[string]interface{}{
-			DepKey: dependency.Checksum,
+			DepKey:   dependency.Checksum,
+			YJITKey:  yjit.String(),
+			StackKey: context.Stack,
+			ArchKey:  targetArch(dependency.Arch),
 		}
 
-		logger.Debug.Process("Adding %s to the $PATH", filepath.Join(mriLayer.Path, "bin"))
-		logger.Debug.Break()
-		if err = os.Setenv("PATH", fmt.Sprintf("%s:%s", filepath.Join(mriLayer.Path, "bin"), os.Getenv("PATH"))); err != nil {
-			return packit.BuildResult{}, err
+		for key, value := range inputs.Metadata() {
+			mriLayer.Metadata[key] = value
 		}
 
-		buffer := bytes.NewBuffer(nil)
-		err = gem.Execute(pexec.Execution{
-			Args:   []string{"env", "path"},
-			Stdout: buffer,
-		})
+		if custom {
+			mriLayer.Metadata[CustomArtifactKey] = dependency.URI
+		}
+
+		if compiled {
+			mriLayer.Metadata[SourceChecksumKey] = dependency.SourceChecksum
+			mriLayer.Metadata[ConfigureFlagsKey] = strings.Join(configureFlags, " ")
+		}
+
+		if yjit.Enabled {
+			logger.Debug.Process("Enabling YJIT at launch")
+			logger.Debug.Break()
+
+			mriLayer.LaunchEnv.Default("RUBY_YJIT_ENABLE", "1")
+			if yjit.ExecMemSize > 0 {
+				mriLayer.LaunchEnv.Append("RUBYOPT", fmt.Sprintf("--yjit-exec-mem-size=%d", yjit.ExecMemSize), " ")
+			}
+		}
+
+		home, _ := os.UserHomeDir()
+		gemPath, err := StaticGemPath(mriLayer.Path, home)
 		if err != nil {
 			return packit.BuildResult{}, err
 		}
 
-		mriLayer.SharedEnv.Default("GEM_PATH", strings.TrimSpace(buffer.String()))
-		mriLayer.SharedEnv.Default("MALLOC_ARENA_MAX", "2")
+		// Fall back to asking RubyGems when the layout of the installation is
+		// not recognized.
+		if gemPath == "" {
+			logger.Debug.Process("Running 'gem env path' with %s on the $PATH", filepath.Join(mriLayer.Path, "bin"))
+			logger.Debug.Break()
+
+			buffer := bytes.NewBuffer(nil)
+			err = gem.Execute(pexec.Execution{
+				Args:   []string{"env", "path"},
+				Env:    append(os.Environ(), fmt.Sprintf("PATH=%s%c%s", filepath.Join(mriLayer.Path, "bin"), os.PathListSeparator, os.Getenv("PATH"))),
+				Stdout: buffer,
+			})
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			gemPath = strings.TrimSpace(buffer.String())
+		}
+
+		mriLayer.SharedEnv.Default("GEM_PATH", gemPath)
+		mriLayer.BuildEnv.Default("MALLOC_ARENA_MAX", "2")
+		mriLayer.ExecD = execD
+
+		err = publishFacts(&mriLayer, dependency.Version)
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
 
 		logger.EnvironmentVariables(mriLayer)
 
+		// In slim launch mode, the full MRI layer is only used at build time and
+		// is cached so that the launch layer can be recreated from it.
+		if slim && launch {
+			launchLayer, err := slimLaunchLayer(context, mriLayer, dependency, vulnerabilities, sbomGenerator, logger, clock)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			mriLayer.Launch, mriLayer.Cache, mriLayer.ExecD = false, true, nil
+			additionalLayers = append(additionalLayers, launchLayer)
+		}
+
+		if reportEnabled {
+			reportLayer, err := writeBuildReport(context, buildReport)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			logger.Debug.Process("Wrote build report to %s", filepath.Join(reportLayer.Path, BuildReportFile))
+			logger.Debug.Break()
+
+			additionalLayers = append(additionalLayers, reportLayer)
+		}
+
+		if provenanceEnabled {
+			provenance, err := NewProvenance(context, dependency, origin, configureFlags, mriLayer.Path)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			provenanceLayer, err := writeProvenance(context, provenance)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			logger.Debug.Process("Wrote provenance to %s", filepath.Join(provenanceLayer.Path, ProvenanceFile))
+			logger.Debug.Break()
+
+			additionalLayers = append(additionalLayers, provenanceLayer)
+		}
+
 		return packit.BuildResult{
-			Layers: []packit.Layer{mriLayer},
+			Layers: append([]packit.Layer{mriLayer}, additionalLayers...),
 			Build:  buildMetadata,
 			Launch: launchMetadata,
 		}, nil
@@ -0,0 +1,127 @@
+package mri
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+)
+
+// BuildReportFile is the name of the report in the mri-report layer.
+const BuildReportFile = "report.json"
+
+// The origins of the installed MRI dependency recorded in the build report.
+const (
+	BuildpackTOMLOrigin  = "buildpack.toml"
+	CustomArtifactOrigin = "mri-artifact binding"
+	CompiledSourceOrigin = "compiled from source"
+)
+
+// LoadBuildReportConfig reports whether $BP_MRI_BUILD_REPORT requests that a
+// machine-readable report of the version resolution be written.
+func LoadBuildReportConfig() (bool, error) {
+	value, ok := os.LookupEnv("BP_MRI_BUILD_REPORT")
+	if !ok {
+		return false, nil
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for $BP_MRI_BUILD_REPORT: %w", err)
+	}
+
+	return enabled, nil
+}
+
+// BuildReport describes how the MRI version was resolved and installed. It
+// records no timings, as they would change the report on every build.
+type BuildReport struct {
+	Buildpack  string                 `json:"buildpack"`
+	Candidates []BuildReportCandidate `json:"candidates"`
+	Selected   BuildReportDependency  `json:"selected"`
+	Reused     bool                   `json:"reused"`
+}
+
+// BuildReportCandidate is a version requested by one of the version sources.
+type BuildReportCandidate struct {
+	Source  string `json:"source"`
+	Version string `json:"version"`
+}
+
+// BuildReportDependency is the MRI dependency that was selected.
+type BuildReportDependency struct {
+	Version         string `json:"version"`
+	Source          string `json:"source,omitempty"`
+	Origin          string `json:"origin"`
+	Checksum        string `json:"checksum,omitempty"`
+	Stack           string `json:"stack"`
+	Arch            string `json:"arch"`
+	DeprecationDate string `json:"deprecation-date,omitempty"`
+}
+
+// NewBuildReport describes the selection of the given dependency among the
+// versions requested by the build plan entries.
+func NewBuildReport(context packit.BuildContext, entry packit.BuildpackPlanEntry, allEntries []packit.BuildpackPlanEntry, dependency postal.Dependency, origin string) BuildReport {
+	report := BuildReport{
+		Buildpack:  fmt.Sprintf("%s@%s", context.BuildpackInfo.ID, context.BuildpackInfo.Version),
+		Candidates: []BuildReportCandidate{},
+		Selected: BuildReportDependency{
+			Version:  dependency.Version,
+			Origin:   origin,
+			Checksum: dependency.Checksum,
+			Stack:    context.Stack,
+			Arch:     targetArch(dependency.Arch),
+		},
+	}
+
+	for _, candidate := range allEntries {
+		source, _ := candidate.Metadata["version-source"].(string)
+		version, _ := candidate.Metadata["version"].(string)
+		report.Candidates = append(report.Candidates, BuildReportCandidate{Source: source, Version: version})
+	}
+
+	report.Selected.Source, _ = entry.Metadata["version-source"].(string)
+
+	if origin == CompiledSourceOrigin {
+		report.Selected.Checksum = dependency.SourceChecksum
+	}
+
+	if !dependency.DeprecationDate.IsZero() {
+		report.Selected.DeprecationDate = dependency.DeprecationDate.Format("2006-01-02")
+	}
+
+	return report
+}
+
+// writeBuildReport writes the report into its own launch layer. The report
+// changes with the requested versions and whether the MRI layer was reused,
+// neither of which says anything about the installation in the MRI layer.
+func writeBuildReport(context packit.BuildContext, report BuildReport) (packit.Layer, error) {
+	layer, err := context.Layers.Get(MRIReport)
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	layer, err = layer.Reset()
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	layer.Launch = true
+
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	err = os.WriteFile(filepath.Join(layer.Path, BuildReportFile), append(content, '\n'), 0644)
+	if err != nil {
+		return packit.Layer{}, fmt.Errorf("failed to write build report: %w", err)
+	}
+
+	return layer, nil
+}
@@ -0,0 +1,109 @@
+package mri_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/paketo-buildpacks/mri"
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testBuildReport(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		buildContext packit.BuildContext
+		entries      []packit.BuildpackPlanEntry
+		dependency   postal.Dependency
+	)
+
+	it.Before(func() {
+		buildContext = packit.BuildContext{
+			Stack: "io.buildpacks.stacks.noble",
+			BuildpackInfo: packit.BuildpackInfo{
+				ID:      "paketo-buildpacks/mri",
+				Version: "1.2.3",
+			},
+		}
+
+		entries = []packit.BuildpackPlanEntry{
+			{Name: "mri", Metadata: map[string]interface{}{"version-source": "BP_MRI_VERSION", "version": "3.4.*"}},
+			{Name: "mri", Metadata: map[string]interface{}{"version-source": "Gemfile.lock", "version": ">= 3.4.1, < 3.5.0"}},
+			{Name: "mri", Metadata: map[string]interface{}{"build": true}},
+		}
+
+		dependency = postal.Dependency{
+			Version:         "3.4.8",
+			Checksum:        "sha256:some-checksum",
+			SourceChecksum:  "sha256:some-source-checksum",
+			Arch:            "arm64",
+			DeprecationDate: time.Date(2028, 3, 31, 0, 0, 0, 0, time.UTC),
+		}
+	})
+
+	context("NewBuildReport", func() {
+		it("describes the candidates and the selected dependency", func() {
+			report := mri.NewBuildReport(buildContext, entries[0], entries, dependency, mri.BuildpackTOMLOrigin)
+			Expect(report).To(Equal(mri.BuildReport{
+				Buildpack: "paketo-buildpacks/mri@1.2.3",
+				Candidates: []mri.BuildReportCandidate{
+					{Source: "BP_MRI_VERSION", Version: "3.4.*"},
+					{Source: "Gemfile.lock", Version: ">= 3.4.1, < 3.5.0"},
+					{},
+				},
+				Selected: mri.BuildReportDependency{
+					Version:         "3.4.8",
+					Source:          "BP_MRI_VERSION",
+					Origin:          "buildpack.toml",
+					Checksum:        "sha256:some-checksum",
+					Stack:           "io.buildpacks.stacks.noble",
+					Arch:            "arm64",
+					DeprecationDate: "2028-03-31",
+				},
+			}))
+		})
+
+		context("when MRI was compiled from source", func() {
+			it("records the source checksum", func() {
+				report := mri.NewBuildReport(buildContext, entries[0], entries, dependency, mri.CompiledSourceOrigin)
+				Expect(report.Selected.Origin).To(Equal("compiled from source"))
+				Expect(report.Selected.Checksum).To(Equal("sha256:some-source-checksum"))
+			})
+		})
+
+		context("when the dependency does not declare an architecture", func() {
+			it.Before(func() {
+				dependency.Arch = ""
+				t.Setenv("CNB_TARGET_ARCH", "amd64")
+			})
+
+			it("uses the target architecture", func() {
+				report := mri.NewBuildReport(buildContext, entries[0], entries, dependency, mri.BuildpackTOMLOrigin)
+				Expect(report.Selected.Arch).To(Equal("amd64"))
+			})
+		})
+	})
+
+	context("LoadBuildReportConfig", func() {
+		it("is disabled by default", func() {
+			enabled, err := mri.LoadBuildReportConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(enabled).To(BeFalse())
+		})
+
+		context("when $BP_MRI_BUILD_REPORT is invalid", func() {
+			it.Before(func() {
+				t.Setenv("BP_MRI_BUILD_REPORT", "some-bad-value")
+			})
+
+			it("returns an error", func() {
+				_, err := mri.LoadBuildReportConfig()
+				Expect(err).To(MatchError(ContainSubstring("invalid value for $BP_MRI_BUILD_REPORT")))
+			})
+		})
+	})
+}
@@ -2,15 +2,20 @@ package mri_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
-	"regexp"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/paketo-buildpacks/mri"
+	"github.com/paketo-buildpacks/mri/facts"
 	"github.com/paketo-buildpacks/mri/fakes"
 	"github.com/paketo-buildpacks/packit/v2"
 	"github.com/paketo-buildpacks/packit/v2/chronos"
@@ -19,8 +24,8 @@ import (
 	"github.com/paketo-buildpacks/packit/v2/paketosbom"
 	"github.com/paketo-buildpacks/packit/v2/pexec"
 	"github.com/paketo-buildpacks/packit/v2/postal"
-	"github.com/paketo-buildpacks/packit/v2/sbom"
 	"github.com/paketo-buildpacks/packit/v2/scribe"
+	"github.com/paketo-buildpacks/packit/v2/servicebindings"
 	"github.com/sclevine/spec"
 
 	. "github.com/onsi/gomega"
@@ -37,8 +42,11 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 
 		entryResolver     *fakes.EntryResolver
 		dependencyManager *fakes.DependencyManager
+		bindingResolver   *fakes.BindingResolver
+		compiler          *fakes.Compiler
 		gem               *fakes.Executable
 		sbomGenerator     *fakes.SBOMGenerator
+		verifier          *fakes.InstallationVerifier
 
 		build        packit.BuildFunc
 		buildContext packit.BuildContext
@@ -82,7 +90,11 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 
 		// Syft SBOM
 		sbomGenerator = &fakes.SBOMGenerator{}
-		sbomGenerator.GenerateFromDependencyCall.Returns.SBOM = sbom.SBOM{}
+		sbomGenerator.GenerateFromDependencyCall.Returns.InstallationSBOM = mri.InstallationSBOM{Dependency: postal.Dependency{ID: "mri", Name: "MRI"}}
+
+		verifier = &fakes.InstallationVerifier{}
+		bindingResolver = &fakes.BindingResolver{}
+		compiler = &fakes.Compiler{}
 
 		clock = chronos.DefaultClock
 
@@ -97,8 +109,11 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		build = mri.Build(
 			entryResolver,
 			dependencyManager,
+			bindingResolver,
+			compiler,
 			gem,
 			sbomGenerator,
+			verifier,
 			logEmitter,
 			clock,
 		)
@@ -109,7 +124,7 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			BuildpackInfo: packit.BuildpackInfo{
 				Name:        "Some Buildpack",
 				Version:     "0.1.2",
-				SBOMFormats: []string{sbom.CycloneDXFormat, sbom.SPDXFormat},
+				SBOMFormats: []string{mri.CycloneDXMediaType, mri.SPDXMediaType},
 			},
 			Plan: packit.BuildpackPlan{
 				Entries: []packit.BuildpackPlanEntry{
@@ -144,12 +159,15 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		Expect(layer.Path).To(Equal(filepath.Join(layersDir, "mri")))
 
 		Expect(layer.SharedEnv).To(Equal(packit.Environment{
+			"GEM_PATH.default": "/some/mri/gems/path",
+		}))
+		Expect(layer.BuildEnv).To(Equal(packit.Environment{
 			"MALLOC_ARENA_MAX.default": "2",
-			"GEM_PATH.default":         "/some/mri/gems/path",
+			"MRI_HOME.override":        filepath.Join(layersDir, "mri"),
 		}))
-		Expect(layer.BuildEnv).To(BeEmpty())
 		Expect(layer.LaunchEnv).To(BeEmpty())
 		Expect(layer.ProcessLaunchEnv).To(BeEmpty())
+		Expect(layer.ExecD).To(Equal([]string{filepath.Join(cnbDir, "bin", "optimize-memory")}))
 
 		Expect(layer.Build).To(BeFalse())
 		Expect(layer.Launch).To(BeFalse())
@@ -157,6 +175,18 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 
 		Expect(layer.Metadata).To(Equal(map[string]interface{}{
 			"dependency-sha": "",
+			"yjit":           "disabled",
+			"stack":          "some-stack",
+			"arch":           runtime.GOARCH,
+			"home":           filepath.Join(layersDir, "mri"),
+			"schema-version": int64(1),
+			"inputs": map[string]interface{}{
+				"dependency": mri.CacheInputs{"dependency": ""}.Digests()["dependency"],
+				"yjit":       mri.CacheInputs{"yjit": "disabled"}.Digests()["yjit"],
+				"verify":     mri.CacheInputs{"verify": "false"}.Digests()["verify"],
+				"stack":      mri.CacheInputs{"stack": "some-stack"}.Digests()["stack"],
+				"arch":       mri.CacheInputs{"arch": runtime.GOARCH}.Digests()["arch"],
+			},
 		}))
 
 		Expect(layer.SBOM.Formats()).To(HaveLen(2))
@@ -167,62 +197,74 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		content, err := io.ReadAll(cdx.Content)
 		Expect(err).NotTo(HaveOccurred())
 		Expect(string(content)).To(MatchJSON(`{
-			"$schema": "http://cyclonedx.org/schema/bom-1.3.schema.json",
+			"$schema": "http://cyclonedx.org/schema/bom-1.5.schema.json",
 			"bomFormat": "CycloneDX",
+			"specVersion": "1.5",
+			"serialNumber": "urn:uuid:bb106424-5efb-4320-9e50-1e92e57c268f",
+			"version": 1,
 			"metadata": {
-				"tools": [
-					{
-						"name": "",
-						"vendor": "anchore"
-					}
-				]
+				"tools": {
+					"components": [
+						{
+							"type": "application",
+							"name": "paketo-buildpacks/mri"
+						}
+					]
+				}
 			},
-			"specVersion": "1.3",
-			"version": 1
+			"components": [
+				{
+					"bom-ref": "8963749a5153bdac",
+					"type": "application",
+					"name": "MRI",
+					"cpe": "cpe:2.3:-:-:-:-:-:-:-:-:-:-:-"
+				}
+			]
 		}`))
 
 		Expect(spdx.Extension).To(Equal("spdx.json"))
 		content, err = io.ReadAll(spdx.Content)
 		Expect(err).NotTo(HaveOccurred())
-
-		versionPattern := regexp.MustCompile(`"licenseListVersion": "\d+\.\d+"`)
-		contentReplaced := versionPattern.ReplaceAllString(string(content), `"licenseListVersion": "x.x"`)
-		uuidRegex := regexp.MustCompile(`[0-9a-fA-F]{8}-([0-9a-fA-F]{4}-){3}[0-9a-fA-F]{12}`)
-		contentReplaced = uuidRegex.ReplaceAllString(contentReplaced, "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx")
-
-		Expect(string(contentReplaced)).To(MatchJSON(`{
+		Expect(string(content)).To(MatchJSON(`{
+			"spdxVersion": "SPDX-2.3",
+			"dataLicense": "CC0-1.0",
 			"SPDXID": "SPDXRef-DOCUMENT",
+			"name": "unknown",
+			"documentNamespace": "https://paketo.io/spdx/mri/bb106424-5efb-4320-9e50-1e92e57c268f",
 			"creationInfo": {
-				"created": "0001-01-01T00:00:00Z",
+				"created": "1980-01-01T00:00:01Z",
 				"creators": [
-					"Organization: Anchore, Inc",
-					"Tool: -"
-				],
-				"licenseListVersion": "x.x"
+					"Organization: Paketo Buildpacks",
+					"Tool: paketo-buildpacks/mri"
+				]
 			},
-			"dataLicense": "CC0-1.0",
-			"documentNamespace": "https://paketo.io/unknown-source-type/unknown-xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx",
-			"name": "unknown",
 			"packages": [
-                {
-                  "SPDXID": "SPDXRef-DocumentRoot-Unknown-",
-                  "copyrightText": "NOASSERTION",
-                  "downloadLocation": "NOASSERTION",
-                  "filesAnalyzed": false,
-                  "licenseConcluded": "NOASSERTION",
-                  "licenseDeclared": "NOASSERTION",
-                  "name": "",
-                  "supplier": "NOASSERTION"
-                }
+				{
+					"name": "MRI",
+					"SPDXID": "SPDXRef-Package-binary-MRI-8963749a5153bdac",
+					"supplier": "NOASSERTION",
+					"downloadLocation": "NOASSERTION",
+					"filesAnalyzed": false,
+					"licenseConcluded": "NOASSERTION",
+					"licenseDeclared": "NOASSERTION",
+					"copyrightText": "NOASSERTION",
+					"externalRefs": [
+						{
+							"referenceCategory": "SECURITY",
+							"referenceType": "cpe23Type",
+							"referenceLocator": "cpe:2.3:-:-:-:-:-:-:-:-:-:-:-"
+						}
+					],
+					"primaryPackagePurpose": "APPLICATION"
+				}
 			],
 			"relationships": [
-                {
-                  "relatedSpdxElement": "SPDXRef-DocumentRoot-Unknown-",
-                  "relationshipType": "DESCRIBES",
-                  "spdxElementId": "SPDXRef-DOCUMENT"
-                }
-              ],
-			"spdxVersion": "SPDX-2.2"
+				{
+					"spdxElementId": "SPDXRef-DOCUMENT",
+					"relationshipType": "DESCRIBES",
+					"relatedSpdxElement": "SPDXRef-Package-binary-MRI-8963749a5153bdac"
+				}
+			]
 		}`))
 
 		Expect(filepath.Join(layersDir, "mri")).To(BeADirectory())
@@ -237,6 +279,16 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 				},
 			},
 		}))
+		Expect(entryResolver.ResolveCall.Receives.InterfaceSlice).To(Equal([]interface{}{
+			"BP_MRI_VERSION",
+			"buildpack.yml",
+			"Gemfile",
+			"Gemfile.lock",
+			".ruby-version",
+			".tool-versions",
+			"mise.toml",
+			"gemspec",
+		}))
 
 		Expect(entryResolver.MergeLayerTypesCall.Receives.BuildpackPlanEntrySlice).To(Equal([]packit.BuildpackPlanEntry{
 			{
@@ -266,6 +318,8 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		Expect(sbomGenerator.GenerateFromDependencyCall.Receives.Dir).To(Equal(filepath.Join(layersDir, "mri")))
 
 		Expect(gem.ExecuteCall.Receives.Execution.Args).To(Equal([]string{"env", "path"}))
+		Expect(gem.ExecuteCall.Receives.Execution.Env).To(ContainElement(HavePrefix(fmt.Sprintf("PATH=%s:", filepath.Join(layersDir, "mri", "bin")))))
+		Expect(os.Getenv("PATH")).NotTo(ContainSubstring(layersDir))
 
 		Expect(buffer.String()).To(ContainSubstring("Some Buildpack 0.1.2"))
 		Expect(buffer.String()).To(ContainSubstring("Resolving MRI version"))
@@ -311,49 +365,1110 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			Expect(result.Layers).To(HaveLen(1))
 			layer := result.Layers[0]
 
-			Expect(layer.Name).To(Equal("mri"))
+			Expect(layer.Name).To(Equal("mri"))
+
+			Expect(layer.Build).To(BeTrue())
+			Expect(layer.Launch).To(BeTrue())
+			Expect(layer.Cache).To(BeTrue())
+
+			Expect(result.Build.BOM).To(Equal(
+				[]packit.BOMEntry{
+					{
+						Name: "mri",
+						Metadata: paketosbom.BOMMetadata{
+							Version: "mri-dependency-version",
+							Checksum: paketosbom.BOMChecksum{
+								Algorithm: paketosbom.SHA256,
+								Hash:      "mri-dependency-sha",
+							},
+							URI: "mri-dependency-uri",
+						},
+					},
+				},
+			))
+
+			Expect(result.Launch.BOM).To(Equal(
+				[]packit.BOMEntry{
+					{
+						Name: "mri",
+						Metadata: paketosbom.BOMMetadata{
+							Version: "mri-dependency-version",
+							Checksum: paketosbom.BOMChecksum{
+								Algorithm: paketosbom.SHA256,
+								Hash:      "mri-dependency-sha",
+							},
+							URI: "mri-dependency-uri",
+						},
+					},
+				},
+			))
+		})
+	})
+
+	context("when BP_MRI_VERSION_RESOLUTION is intersect and several entries request a version", func() {
+		it.Before(func() {
+			t.Setenv("BP_MRI_VERSION_RESOLUTION", "intersect")
+
+			Expect(os.WriteFile(filepath.Join(cnbDir, "buildpack.toml"), []byte(`
+[[metadata.dependencies]]
+  id = "ruby"
+  version = "3.3.11"
+  stacks = ["some-stack"]
+
+[[metadata.dependencies]]
+  id = "ruby"
+  version = "3.4.8"
+  stacks = ["some-stack"]
+`), 0600)).To(Succeed())
+
+			entryResolver.ResolveCall.Returns.BuildpackPlanEntry = packit.BuildpackPlanEntry{
+				Name:     "mri",
+				Metadata: map[string]interface{}{"version-source": "BP_MRI_VERSION", "version": "3.*"},
+			}
+			entryResolver.ResolveCall.Returns.BuildpackPlanEntrySlice = []packit.BuildpackPlanEntry{
+				{Name: "mri", Metadata: map[string]interface{}{"version-source": "BP_MRI_VERSION", "version": "3.*"}},
+				{Name: "mri", Metadata: map[string]interface{}{"version": "3.3.*"}},
+			}
+		})
+
+		it("resolves the newest version that satisfies every entry", func() {
+			_, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(dependencyManager.ResolveCall.Receives.Version).To(Equal("3.3.11"))
+
+			Expect(buffer.String()).To(ContainSubstring("Resolved the intersection of the requested versions to 3.3.11:"))
+			Expect(buffer.String()).To(ContainSubstring(`BP_MRI_VERSION requests "3.*"`))
+			Expect(buffer.String()).To(ContainSubstring(`build plan requests "3.3.*"`))
+		})
+
+		context("when no version satisfies every entry", func() {
+			it.Before(func() {
+				entryResolver.ResolveCall.Returns.BuildpackPlanEntrySlice = []packit.BuildpackPlanEntry{
+					{Name: "mri", Metadata: map[string]interface{}{"version-source": "BP_MRI_VERSION", "version": "3.4.*"}},
+					{Name: "mri", Metadata: map[string]interface{}{"version-source": "Gemfile", "version": "3.3.*"}},
+				}
+			})
+
+			it("returns an error that explains each requested version", func() {
+				_, err := build(buildContext)
+
+				var incompatible mri.IncompatibleVersionsError
+				Expect(errors.As(err, &incompatible)).To(BeTrue())
+				Expect(err).To(MatchError(ContainSubstring(`BP_MRI_VERSION requests "3.4.*", satisfied by 3.4.8`)))
+				Expect(err).To(MatchError(ContainSubstring(`Gemfile requests "3.3.*", satisfied by 3.3.11`)))
+				Expect(dependencyManager.ResolveCall.CallCount).To(Equal(0))
+			})
+		})
+	})
+
+	context("when the version is an alias", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(cnbDir, "buildpack.toml"), []byte(`
+[metadata]
+  [metadata.default-versions]
+    ruby = "3.3.*"
+
+  [[metadata.dependencies]]
+    id = "ruby"
+    version = "3.3.11"
+    stacks = ["some-stack"]
+
+  [[metadata.dependencies]]
+    id = "ruby"
+    version = "3.4.8"
+    stacks = ["some-stack"]
+`), 0600)).To(Succeed())
+
+			entryResolver.ResolveCall.Returns.BuildpackPlanEntry = packit.BuildpackPlanEntry{
+				Name: "mri",
+				Metadata: map[string]interface{}{
+					"version-source": "BP_MRI_VERSION",
+					"version":        "latest",
+				},
+			}
+		})
+
+		it("resolves the alias to a concrete version before resolving the dependency", func() {
+			_, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(dependencyManager.ResolveCall.Receives.Version).To(Equal("3.4.8"))
+
+			Expect(buffer.String()).To(ContainSubstring(`Resolved version alias "latest" to 3.4.8`))
+		})
+
+		context("when the alias is default", func() {
+			it.Before(func() {
+				entryResolver.ResolveCall.Returns.BuildpackPlanEntry.Metadata["version"] = "default"
+			})
+
+			it("resolves the default version", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.ResolveCall.Receives.Version).To(Equal("3.3.11"))
+
+				Expect(buffer.String()).To(ContainSubstring(`Resolved version alias "default" to 3.3.11`))
+			})
+		})
+	})
+
+	context("when the version will reach end-of-life within the warning window", func() {
+		it.Before(func() {
+			t.Setenv("BP_MRI_EOL_WARNING_DAYS", "90")
+
+			dependencyManager.ResolveCall.Returns.Dependency = postal.Dependency{
+				ID:              "ruby",
+				Name:            "Ruby",
+				Version:         "3.2.9",
+				DeprecationDate: time.Now().AddDate(0, 0, 60),
+			}
+		})
+
+		it("warns about the upcoming deprecation", func() {
+			_, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(strings.Count(buffer.String(), "Version 3.2.9 of MRI will be deprecated after")).To(Equal(1))
+			Expect(buffer.String()).To(ContainSubstring("Migrate your application to a supported version of MRI before this time."))
+		})
+	})
+
+	context("when the version has reached end-of-life", func() {
+		it.Before(func() {
+			dependencyManager.ResolveCall.Returns.Dependency = postal.Dependency{
+				ID:              "ruby",
+				Name:            "Ruby",
+				Version:         "3.1.7",
+				DeprecationDate: time.Now().AddDate(0, 0, -1),
+			}
+		})
+
+		it("warns about the deprecation and continues the build", func() {
+			_, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(buffer.String()).To(ContainSubstring("Version 3.1.7 of MRI is deprecated."))
+		})
+	})
+
+	context("when the version will reach end-of-life outside a shorter warning window", func() {
+		it.Before(func() {
+			t.Setenv("BP_MRI_EOL_WARNING_DAYS", "7")
+
+			dependencyManager.ResolveCall.Returns.Dependency = postal.Dependency{
+				ID:              "ruby",
+				Name:            "Ruby",
+				Version:         "3.2.9",
+				DeprecationDate: time.Now().AddDate(0, 0, 20),
+			}
+		})
+
+		it("does not warn about the upcoming deprecation", func() {
+			_, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(buffer.String()).To(ContainSubstring("Selected MRI version (using buildpack.yml): 3.2.9"))
+			Expect(buffer.String()).NotTo(ContainSubstring("will be deprecated after"))
+		})
+	})
+
+	context("when BP_MRI_YJIT is set", func() {
+		it.Before(func() {
+			t.Setenv("BP_MRI_YJIT", "true")
+			t.Setenv("BP_MRI_YJIT_EXEC_MEM_SIZE", "64")
+
+			dependencyManager.ResolveCall.Returns.Dependency = postal.Dependency{
+				ID:      "ruby",
+				Name:    "Ruby",
+				Version: "3.4.8",
+				Arch:    "arm64",
+			}
+		})
+
+		it("enables YJIT in the launch environment", func() {
+			result, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			layer := result.Layers[0]
+			Expect(layer.LaunchEnv).To(Equal(packit.Environment{
+				"RUBY_YJIT_ENABLE.default": "1",
+				"RUBYOPT.append":           "--yjit-exec-mem-size=64",
+				"RUBYOPT.delim":            " ",
+			}))
+			Expect(layer.Metadata).To(HaveKeyWithValue("yjit", "enabled (exec-mem-size=64)"))
+		})
+
+		context("when the cached layer was built without YJIT", func() {
+			it.Before(func() {
+				Expect(writeLayerMetadata(filepath.Join(layersDir, "mri.toml"), cachedMetadata(mri.CacheInputs{
+					"dependency": "sha256:some-sha",
+					"yjit":       "disabled",
+					"verify":     "false",
+					"stack":      "some-stack",
+					"arch":       runtime.GOARCH,
+				}, nil))).To(Succeed())
+
+				dependencyManager.ResolveCall.Returns.Dependency.Checksum = "sha256:some-sha"
+			})
+
+			it("rebuilds the layer", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.DeliverCall.CallCount).To(Equal(1))
+			})
+		})
+
+		context("when the version does not support YJIT", func() {
+			it.Before(func() {
+				dependencyManager.ResolveCall.Returns.Dependency.Version = "3.1.7"
+			})
+
+			it("warns and does not enable YJIT", func() {
+				result, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				layer := result.Layers[0]
+				Expect(layer.LaunchEnv).To(BeEmpty())
+				Expect(layer.Metadata).To(HaveKeyWithValue("yjit", "disabled"))
+
+				Expect(buffer.String()).To(ContainSubstring("WARNING: YJIT is not supported by MRI 3.1.7 on this architecture and will not be enabled."))
+			})
+		})
+	})
+
+	context("when the installation records its ABI version and platform", func() {
+		it.Before(func() {
+			dependencyManager.ResolveCall.Returns.Dependency = postal.Dependency{ID: "ruby", Name: "Ruby", Version: "3.4.8"}
+			dependencyManager.DeliverCall.Stub = func(dependency postal.Dependency, cnbPath, layerPath, platformPath string) error {
+				err := os.MkdirAll(filepath.Join(layerPath, "lib", "ruby", "3.4.0", "x86_64-linux"), os.ModePerm)
+				if err != nil {
+					return err
+				}
+
+				return os.WriteFile(filepath.Join(layerPath, "lib", "ruby", "3.4.0", "x86_64-linux", "rbconfig.rb"), []byte(`CONFIG["ruby_version"] = "3.4.0"
+CONFIG["arch"] = "x86_64-linux"
+`), 0600)
+			}
+		})
+
+		it("publishes the installation facts to later buildpacks", func() {
+			result, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			layer := result.Layers[0]
+			Expect(layer.BuildEnv).To(Equal(packit.Environment{
+				"MALLOC_ARENA_MAX.default": "2",
+				"MRI_VERSION.override":     "3.4.8",
+				"MRI_ABI_VERSION.override": "3.4.0",
+				"MRI_HOME.override":        filepath.Join(layersDir, "mri"),
+				"MRI_PLATFORM.override":    "x86_64-linux",
+			}))
+			Expect(layer.Metadata).To(HaveKeyWithValue("version", "3.4.8"))
+			Expect(layer.Metadata).To(HaveKeyWithValue("abi-version", "3.4.0"))
+			Expect(layer.Metadata).To(HaveKeyWithValue("home", filepath.Join(layersDir, "mri")))
+			Expect(layer.Metadata).To(HaveKeyWithValue("platform", "x86_64-linux"))
+		})
+	})
+
+	context("when the gem path can be derived from the installation", func() {
+		it.Before(func() {
+			dependencyManager.DeliverCall.Stub = func(dependency postal.Dependency, cnbPath, layerPath, platformPath string) error {
+				return os.MkdirAll(filepath.Join(layerPath, "lib", "ruby", "gems", "3.4.0"), os.ModePerm)
+			}
+
+			t.Setenv("HOME", "/")
+			t.Setenv("XDG_DATA_HOME", "")
+		})
+
+		it("sets the GEM_PATH without running gem", func() {
+			result, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[0].SharedEnv).To(Equal(packit.Environment{
+				"GEM_PATH.default": fmt.Sprintf("/.local/share/gem/ruby/3.4.0:%s", filepath.Join(layersDir, "mri", "lib", "ruby", "gems", "3.4.0")),
+			}))
+
+			Expect(gem.ExecuteCall.CallCount).To(Equal(0))
+		})
+	})
+
+	context("when an mri-artifact binding is provided", func() {
+		it.Before(func() {
+			bindingResolver.ResolveCall.Returns.BindingSlice = []servicebindings.Binding{
+				{
+					Name: "hardened-ruby",
+					Path: "/platform/bindings/hardened-ruby",
+					Type: "mri-artifact",
+					Entries: map[string]*servicebindings.Entry{
+						"uri":      servicebindings.NewWithValue([]byte("https://artifacts.example.com/ruby-3.4.8.tgz\n")),
+						"checksum": servicebindings.NewWithValue([]byte("sha256:some-checksum")),
+						"version":  servicebindings.NewWithValue([]byte("3.4.8")),
+						"licenses": servicebindings.NewWithValue([]byte("Ruby, BSD-2-Clause")),
+						"cpe":      servicebindings.NewWithValue([]byte("cpe:2.3:a:ruby-lang:ruby:3.4.8:*:*:*:*:*:*:*")),
+					},
+				},
+			}
+		})
+
+		it("installs the artifact from the binding", func() {
+			result, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(bindingResolver.ResolveCall.Receives.Typ).To(Equal("mri-artifact"))
+			Expect(bindingResolver.ResolveCall.Receives.PlatformDir).To(Equal("platform"))
+
+			Expect(dependencyManager.ResolveCall.CallCount).To(Equal(0))
+			Expect(dependencyManager.DeliverCall.Receives.Dependency).To(Equal(postal.Dependency{
+				ID:       "mri",
+				Name:     "MRI",
+				Version:  "3.4.8",
+				URI:      "https://artifacts.example.com/ruby-3.4.8.tgz",
+				Checksum: "sha256:some-checksum",
+				Licenses: []string{"Ruby", "BSD-2-Clause"},
+				CPE:      "cpe:2.3:a:ruby-lang:ruby:3.4.8:*:*:*:*:*:*:*",
+				PURL:     "pkg:generic/ruby@3.4.8",
+				Stacks:   []string{"some-stack"},
+			}))
+			Expect(sbomGenerator.GenerateFromDependencyCall.Receives.Dependency.Version).To(Equal("3.4.8"))
+
+			Expect(result.Layers[0].Metadata).To(HaveKeyWithValue("custom-artifact", "https://artifacts.example.com/ruby-3.4.8.tgz"))
+			Expect(result.Layers[0].Metadata).To(HaveKeyWithValue("dependency-sha", "sha256:some-checksum"))
+
+			Expect(buffer.String()).To(ContainSubstring("Selected MRI version (using mri-artifact binding): 3.4.8"))
+			Expect(buffer.String()).To(ContainSubstring(`WARNING: MRI 3.4.8 from the mri-artifact binding does not satisfy the requested version "2.5.x".`))
+		})
+
+		context("when the binding provides a path to the artifact", func() {
+			it.Before(func() {
+				binding := &bindingResolver.ResolveCall.Returns.BindingSlice[0]
+				delete(binding.Entries, "uri")
+				binding.Entries["path"] = servicebindings.NewWithValue([]byte("ruby-3.4.8.tgz"))
+				binding.Entries["checksum"] = servicebindings.NewWithValue([]byte("c6e0f1cd4a1d4ba4a59b3bd7fe3ed9a7e8cf2e14bb1ae9ef7a5d4b5f0a73c1d2"))
+			})
+
+			it("installs the artifact relative to the binding", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				dependency := dependencyManager.DeliverCall.Receives.Dependency
+				Expect(dependency.Checksum).To(Equal("sha256:c6e0f1cd4a1d4ba4a59b3bd7fe3ed9a7e8cf2e14bb1ae9ef7a5d4b5f0a73c1d2"))
+				Expect(dependency.URI).To(HavePrefix("file://"))
+				Expect(filepath.Join(cnbDir, strings.TrimPrefix(dependency.URI, "file://"))).To(Equal("/platform/bindings/hardened-ruby/ruby-3.4.8.tgz"))
+			})
+		})
+	})
+
+	context("when BP_MRI_VERIFY is set", func() {
+		it.Before(func() {
+			t.Setenv("BP_MRI_VERIFY", "true")
+
+			verifier.VerifyCall.Returns.VerificationReport = mri.VerificationReport{
+				Checks: []mri.VerificationCheck{
+					{Name: "version", Expected: "3.4.8", Actual: "3.4.8", Passed: true},
+				},
+			}
+		})
+
+		it("verifies the installed interpreter", func() {
+			_, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(verifier.VerifyCall.Receives.LayerPath).To(Equal(filepath.Join(layersDir, "mri")))
+			Expect(verifier.VerifyCall.Receives.Dependency.ID).To(Equal("mri"))
+
+			Expect(buffer.String()).To(ContainSubstring("Verifying MRI installation"))
+			Expect(buffer.String()).To(ContainSubstring("[PASS] version: expected 3.4.8, got 3.4.8"))
+		})
+	})
+
+	context("when BP_MRI_SLIM_LAUNCH is set", func() {
+		it.Before(func() {
+			t.Setenv("BP_MRI_SLIM_LAUNCH", "true")
+
+			entryResolver.MergeLayerTypesCall.Returns.Launch = true
+			entryResolver.MergeLayerTypesCall.Returns.Build = true
+
+			dependencyManager.ResolveCall.Returns.Dependency = postal.Dependency{
+				ID:       "ruby",
+				Name:     "Ruby",
+				Version:  "3.4.8",
+				Checksum: "sha256:some-sha",
+			}
+
+			dependencyManager.DeliverCall.Stub = func(dependency postal.Dependency, cnbPath, layerPath, platformPath string) error {
+				for path, content := range map[string]string{
+					"bin/ruby":                       "some-ruby-binary",
+					"include/ruby-3.4.0/ruby.h":      "some-header",
+					"lib/libruby-static.a":           "some-static-library",
+					"lib/libruby.so.3.4":             "some-shared-library",
+					"lib/ruby/3.4.0/mkmf.rb":         "some-mkmf",
+					"lib/ruby/3.4.0/json.rb":         "some-library",
+					"share/ri/3.4.0/system/cdesc":    "some-documentation",
+					"share/man/man1/ruby.1":          "some-manual",
+					"lib/ruby/gems/3.4.0/gems/.keep": "",
+				} {
+					Expect(os.MkdirAll(filepath.Join(layerPath, filepath.Dir(path)), os.ModePerm)).To(Succeed())
+					Expect(os.WriteFile(filepath.Join(layerPath, path), []byte(content), 0600)).To(Succeed())
+				}
+
+				return os.Symlink("libruby.so.3.4", filepath.Join(layerPath, "lib", "libruby.so"))
+			}
+
+			t.Setenv("HOME", "/")
+			t.Setenv("XDG_DATA_HOME", "/some/data/home")
+		})
+
+		it("installs a slimmed down copy of MRI for launch", func() {
+			result, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(2))
+
+			mriLayer := result.Layers[0]
+			Expect(mriLayer.Name).To(Equal("mri"))
+			Expect(mriLayer.Build).To(BeTrue())
+			Expect(mriLayer.Cache).To(BeTrue())
+			Expect(mriLayer.Launch).To(BeFalse())
+			Expect(mriLayer.ExecD).To(BeEmpty())
+
+			launchLayer := result.Layers[1]
+			Expect(launchLayer.Name).To(Equal("mri-launch"))
+			Expect(launchLayer.Path).To(Equal(filepath.Join(layersDir, "mri-launch")))
+			Expect(launchLayer.Build).To(BeFalse())
+			Expect(launchLayer.Cache).To(BeFalse())
+			Expect(launchLayer.Launch).To(BeTrue())
+			Expect(launchLayer.LaunchEnv).To(Equal(packit.Environment{
+				"GEM_PATH.default": fmt.Sprintf("/some/data/home/gem/ruby/3.4.0:%s", filepath.Join(layersDir, "mri-launch", "lib", "ruby", "gems", "3.4.0")),
+			}))
+			Expect(launchLayer.ExecD).To(Equal([]string{filepath.Join(cnbDir, "bin", "optimize-memory")}))
+			Expect(launchLayer.Metadata).To(Equal(mriLayer.Metadata))
+			Expect(launchLayer.SBOM.Formats()).To(HaveLen(2))
+
+			Expect(filepath.Join(launchLayer.Path, "bin", "ruby")).To(BeARegularFile())
+			Expect(filepath.Join(launchLayer.Path, "lib", "libruby.so.3.4")).To(BeARegularFile())
+			Expect(filepath.Join(launchLayer.Path, "lib", "ruby", "3.4.0", "json.rb")).To(BeARegularFile())
+			Expect(filepath.Join(launchLayer.Path, "lib", "ruby", "gems", "3.4.0", "gems")).To(BeADirectory())
+
+			link, err := os.Readlink(filepath.Join(launchLayer.Path, "lib", "libruby.so"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(link).To(Equal("libruby.so.3.4"))
+
+			Expect(filepath.Join(launchLayer.Path, "include")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(launchLayer.Path, "lib", "libruby-static.a")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(launchLayer.Path, "lib", "ruby", "3.4.0", "mkmf.rb")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(launchLayer.Path, "share", "ri")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(launchLayer.Path, "share", "man")).NotTo(BeAnExistingFile())
+
+			Expect(buffer.String()).To(ContainSubstring("Creating slim launch layer"))
+			Expect(buffer.String()).To(ContainSubstring("Launch layer is 47 B instead of 115 B (68 B smaller)"))
+		})
+
+		context("when the launch layer was created from the cached MRI layer", func() {
+			it.Before(func() {
+				inputs := mri.CacheInputs{
+					"dependency": "sha256:some-sha",
+					"yjit":       "disabled",
+					"verify":     "false",
+					"stack":      "some-stack",
+					"arch":       runtime.GOARCH,
+				}
+
+				Expect(writeLayerMetadata(filepath.Join(layersDir, "mri.toml"), cachedMetadata(inputs, map[string]interface{}{
+					"dependency-sha": "some-sha",
+				}))).To(Succeed())
+				Expect(writeLayerMetadata(filepath.Join(layersDir, "mri-launch.toml"), cachedMetadata(inputs, map[string]interface{}{
+					"dependency-sha": "some-sha",
+					"version":        "3.4.8",
+					"home":           filepath.Join(layersDir, "mri"),
+				}))).To(Succeed())
+			})
+
+			it("reuses both layers", func() {
+				result, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Layers).To(HaveLen(2))
+				Expect(result.Layers[0].Launch).To(BeFalse())
+				Expect(result.Layers[1].Name).To(Equal("mri-launch"))
+				Expect(result.Layers[1].Launch).To(BeTrue())
+				Expect(result.Layers[1].ExecD).To(Equal([]string{filepath.Join(cnbDir, "bin", "optimize-memory")}))
+
+				Expect(dependencyManager.DeliverCall.CallCount).To(Equal(0))
+				Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("Reusing cached layer %s", filepath.Join(layersDir, "mri-launch"))))
+			})
+		})
+
+		context("when MRI is not required at launch", func() {
+			it.Before(func() {
+				entryResolver.MergeLayerTypesCall.Returns.Launch = false
+			})
+
+			it("does not create a launch layer", func() {
+				result, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Layers).To(HaveLen(1))
+				Expect(result.Layers[0].Name).To(Equal("mri"))
+			})
+		})
+	})
+
+	context("when BP_MRI_COMPILE_FROM_SOURCE is set and no precompiled artifact matches the stack", func() {
+		it.Before(func() {
+			t.Setenv("BP_MRI_COMPILE_FROM_SOURCE", "true")
+
+			Expect(os.WriteFile(filepath.Join(cnbDir, "buildpack.toml"), []byte(`
+[[metadata.dependencies]]
+  id = "ruby"
+  name = "Ruby"
+  version = "3.4.8"
+  licenses = ["BSD-2-Clause"]
+  purl = "pkg:generic/ruby@3.4.8"
+  source = "https://cache.ruby-lang.org/pub/ruby/3.4/ruby-3.4.8.tar.gz"
+  source-checksum = "sha256:some-source-sha"
+  stacks = ["other-stack"]
+`), 0600)).To(Succeed())
+
+			entryResolver.ResolveCall.Returns.BuildpackPlanEntry = packit.BuildpackPlanEntry{
+				Name: "mri",
+				Metadata: map[string]interface{}{
+					"version-source": "BP_MRI_VERSION",
+					"version":        "3.4.*",
+				},
+			}
+
+			dependencyManager.ResolveCall.Returns.Error = errors.New("failed to resolve dependency")
+		})
+
+		it("compiles MRI from source into the layer", func() {
+			result, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			layer := result.Layers[0]
+			Expect(layer.Metadata).To(Equal(map[string]interface{}{
+				"dependency-sha":  "",
+				"yjit":            "disabled",
+				"source-checksum": "sha256:some-source-sha",
+				"configure-flags": "--enable-load-relative --disable-install-doc",
+				"stack":           "some-stack",
+				"arch":            runtime.GOARCH,
+				"version":         "3.4.8",
+				"home":            filepath.Join(layersDir, "mri"),
+				"schema-version":  int64(1),
+				"inputs": mri.CacheInputs{
+					"dependency":      "sha256:some-source-sha",
+					"yjit":            "disabled",
+					"verify":          "false",
+					"stack":           "some-stack",
+					"arch":            runtime.GOARCH,
+					"configure-flags": "--enable-load-relative --disable-install-doc",
+				}.Digests(),
+			}))
+
+			Expect(compiler.CompileCall.CallCount).To(Equal(1))
+			Expect(compiler.CompileCall.Receives.LayerPath).To(Equal(filepath.Join(layersDir, "mri")))
+			Expect(compiler.CompileCall.Receives.Flags).To(Equal([]string{"--enable-load-relative", "--disable-install-doc"}))
+
+			dependency := compiler.CompileCall.Receives.Dependency
+			Expect(dependency.ID).To(Equal("mri"))
+			Expect(dependency.Version).To(Equal("3.4.8"))
+			Expect(dependency.Source).To(Equal("https://cache.ruby-lang.org/pub/ruby/3.4/ruby-3.4.8.tar.gz"))
+			Expect(dependency.SourceChecksum).To(Equal("sha256:some-source-sha"))
+			Expect(dependency.Licenses).To(Equal([]string{"BSD-2-Clause"}))
+			Expect(dependency.Stacks).To(Equal([]string{"some-stack"}))
+
+			Expect(dependencyManager.DeliverCall.CallCount).To(Equal(0))
+			Expect(sbomGenerator.GenerateFromDependencyCall.Receives.Dependency.Version).To(Equal("3.4.8"))
+
+			Expect(buffer.String()).To(ContainSubstring("No precompiled MRI 3.4.8 is available for the some-stack stack, it will be compiled from source"))
+			Expect(buffer.String()).To(ContainSubstring("Compiling MRI 3.4.8 from source"))
+		})
+
+		context("when BP_MRI_VERSION is an alias", func() {
+			it.Before(func() {
+				entryResolver.ResolveCall.Returns.BuildpackPlanEntry = packit.BuildpackPlanEntry{
+					Name:     "mri",
+					Metadata: map[string]interface{}{"version-source": "BP_MRI_VERSION", "version": "latest"},
+				}
+			})
+
+			it("resolves the alias against the versions that can be compiled", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.ResolveCall.Receives.Version).To(Equal("3.4.8"))
+				Expect(compiler.CompileCall.Receives.Dependency.Version).To(Equal("3.4.8"))
+
+				Expect(buffer.String()).To(ContainSubstring(`Resolved version alias "latest" to 3.4.8`))
+				Expect(buffer.String()).To(ContainSubstring("Compiling MRI 3.4.8 from source"))
+			})
+		})
+
+		context("when BP_MRI_VERSION_RESOLUTION is intersect", func() {
+			it.Before(func() {
+				t.Setenv("BP_MRI_VERSION_RESOLUTION", "intersect")
+
+				entryResolver.ResolveCall.Returns.BuildpackPlanEntrySlice = []packit.BuildpackPlanEntry{
+					{Name: "mri", Metadata: map[string]interface{}{"version-source": "BP_MRI_VERSION", "version": "3.4.*"}},
+					{Name: "mri", Metadata: map[string]interface{}{"version-source": "Gemfile", "version": "stable"}},
+				}
+			})
+
+			it("intersects the requests against the versions that can be compiled", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.ResolveCall.Receives.Version).To(Equal("3.4.8"))
+				Expect(compiler.CompileCall.Receives.Dependency.Version).To(Equal("3.4.8"))
+
+				Expect(buffer.String()).To(ContainSubstring("Resolved the intersection of the requested versions to 3.4.8:"))
+			})
+		})
+
+		context("when the layer was compiled from the same source and flags", func() {
+			it.Before(func() {
+				Expect(writeLayerMetadata(filepath.Join(layersDir, "mri.toml"), cachedMetadata(mri.CacheInputs{
+					"dependency":      "sha256:some-source-sha",
+					"yjit":            "disabled",
+					"verify":          "false",
+					"stack":           "some-stack",
+					"arch":            runtime.GOARCH,
+					"configure-flags": "--enable-load-relative --disable-install-doc",
+				}, nil))).To(Succeed())
+			})
+
+			it("reuses the cached layer", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(compiler.CompileCall.CallCount).To(Equal(0))
+				Expect(buffer.String()).To(ContainSubstring("Reusing cached layer"))
+			})
+		})
+
+		context("when the layer was compiled with different flags", func() {
+			it.Before(func() {
+				Expect(writeLayerMetadata(filepath.Join(layersDir, "mri.toml"), cachedMetadata(mri.CacheInputs{
+					"dependency":      "sha256:some-source-sha",
+					"yjit":            "disabled",
+					"verify":          "false",
+					"stack":           "some-stack",
+					"arch":            runtime.GOARCH,
+					"configure-flags": "--enable-load-relative",
+				}, nil))).To(Succeed())
+			})
+
+			it("compiles MRI again", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(compiler.CompileCall.CallCount).To(Equal(1))
+			})
+		})
+
+		context("when BP_MRI_YJIT is set", func() {
+			it.Before(func() {
+				t.Setenv("BP_MRI_YJIT", "true")
+				t.Setenv("CNB_TARGET_ARCH", "amd64")
+			})
+
+			it("compiles MRI with YJIT", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(compiler.CompileCall.Receives.Flags).To(Equal([]string{"--enable-load-relative", "--disable-install-doc", "--enable-yjit"}))
+			})
+		})
+	})
+
+	context("when BP_MRI_BUILD_REPORT is set", func() {
+		it.Before(func() {
+			t.Setenv("BP_MRI_BUILD_REPORT", "true")
+			t.Setenv("CNB_TARGET_ARCH", "amd64")
+
+			buildContext.BuildpackInfo.ID = "some-buildpack-id"
+			dependencyManager.ResolveCall.Returns.Dependency = postal.Dependency{
+				ID:       "ruby",
+				Name:     "Ruby",
+				Version:  "3.4.8",
+				Checksum: "sha256:some-sha",
+			}
+			entryResolver.ResolveCall.Returns.BuildpackPlanEntrySlice = []packit.BuildpackPlanEntry{
+				{Name: "mri", Metadata: map[string]interface{}{"version-source": "buildpack.yml", "version": "2.5.x"}},
+			}
+		})
+
+		it("writes a report of the version resolution into a launch layer", func() {
+			result, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(2))
+			reportLayer := result.Layers[1]
+			Expect(reportLayer.Name).To(Equal("mri-report"))
+			Expect(reportLayer.Launch).To(BeTrue())
+			Expect(reportLayer.Build).To(BeFalse())
+			Expect(reportLayer.Cache).To(BeFalse())
+
+			content, err := os.ReadFile(filepath.Join(layersDir, "mri-report", "report.json"))
+			Expect(err).NotTo(HaveOccurred())
+
+			var report mri.BuildReport
+			Expect(json.Unmarshal(content, &report)).To(Succeed())
+			Expect(report.Buildpack).To(Equal("some-buildpack-id@0.1.2"))
+			Expect(report.Candidates).To(Equal([]mri.BuildReportCandidate{{Source: "buildpack.yml", Version: "2.5.x"}}))
+			Expect(report.Selected).To(Equal(mri.BuildReportDependency{
+				Version:  "3.4.8",
+				Source:   "buildpack.yml",
+				Origin:   "buildpack.toml",
+				Checksum: "sha256:some-sha",
+				Stack:    "some-stack",
+				Arch:     "amd64",
+			}))
+			Expect(report.Reused).To(BeFalse())
+		})
+
+		context("when the MRI layer is reused", func() {
+			it.Before(func() {
+				Expect(writeLayerMetadata(filepath.Join(layersDir, "mri.toml"), cachedMetadata(mri.CacheInputs{
+					"dependency": "sha256:some-sha",
+					"yjit":       "disabled",
+					"verify":     "false",
+					"stack":      "some-stack",
+					"arch":       runtime.GOARCH,
+				}, nil))).To(Succeed())
+			})
+
+			it("records that the layer was reused", func() {
+				result, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Layers).To(HaveLen(2))
+				Expect(result.Layers[1].Name).To(Equal("mri-report"))
+
+				content, err := os.ReadFile(filepath.Join(layersDir, "mri-report", "report.json"))
+				Expect(err).NotTo(HaveOccurred())
+
+				var report mri.BuildReport
+				Expect(json.Unmarshal(content, &report)).To(Succeed())
+				Expect(report.Reused).To(BeTrue())
+			})
+
+			it("writes the same report on every rebuild", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				first, err := os.ReadFile(filepath.Join(layersDir, "mri-report", "report.json"))
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				second, err := os.ReadFile(filepath.Join(layersDir, "mri-report", "report.json"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(second).To(Equal(first))
+				Expect(string(first)).NotTo(ContainSubstring("durations"))
+			})
+		})
+	})
+
+	context("when BP_MRI_PROVENANCE is set", func() {
+		it.Before(func() {
+			t.Setenv("BP_MRI_PROVENANCE", "true")
+			t.Setenv("CNB_TARGET_ARCH", "amd64")
+
+			buildContext.BuildpackInfo.ID = "some-buildpack-id"
+			buildContext.BuildpackInfo.Homepage = "https://github.com/some-org/some-buildpack"
+			dependencyManager.ResolveCall.Returns.Dependency = postal.Dependency{
+				ID:             "ruby",
+				Name:           "Ruby",
+				Version:        "3.4.8",
+				URI:            "https://example.com/ruby_3.4.8_linux_x64_jammy.tgz",
+				Checksum:       "sha256:some-sha",
+				Source:         "https://cache.ruby-lang.org/pub/ruby/3.4/ruby-3.4.8.tar.gz",
+				SourceChecksum: "sha256:some-source-sha",
+			}
+		})
+
+		it("writes a provenance statement into a launch layer", func() {
+			result, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(2))
+			provenanceLayer := result.Layers[1]
+			Expect(provenanceLayer.Name).To(Equal("mri-provenance"))
+			Expect(provenanceLayer.Launch).To(BeTrue())
+			Expect(provenanceLayer.Build).To(BeFalse())
+			Expect(provenanceLayer.Cache).To(BeFalse())
+
+			content, err := os.ReadFile(filepath.Join(layersDir, "mri-provenance", "provenance.json"))
+			Expect(err).NotTo(HaveOccurred())
+
+			var provenance mri.Provenance
+			Expect(json.Unmarshal(content, &provenance)).To(Succeed())
+			Expect(provenance.Subject).To(Equal([]mri.ProvenanceSubject{
+				{Name: "https://example.com/ruby_3.4.8_linux_x64_jammy.tgz", Digest: map[string]string{"sha256": "some-sha"}},
+			}))
+			Expect(provenance.Predicate.BuildDefinition.BuildType).To(Equal(mri.InstallBuildType))
+			Expect(provenance.Predicate.BuildDefinition.ResolvedDependencies).To(Equal([]mri.ProvenanceResourceDescriptor{
+				{Name: "source", URI: "https://cache.ruby-lang.org/pub/ruby/3.4/ruby-3.4.8.tar.gz", Digest: map[string]string{"sha256": "some-source-sha"}},
+			}))
+			Expect(provenance.Predicate.RunDetails.Builder).To(Equal(mri.ProvenanceBuilder{
+				ID:      "https://github.com/some-org/some-buildpack",
+				Version: map[string]string{"some-buildpack-id": "0.1.2"},
+			}))
+		})
+
+		context("when the MRI layer is reused", func() {
+			it.Before(func() {
+				Expect(writeLayerMetadata(filepath.Join(layersDir, "mri.toml"), cachedMetadata(mri.CacheInputs{
+					"dependency": "sha256:some-sha",
+					"yjit":       "disabled",
+					"verify":     "false",
+					"stack":      "some-stack",
+					"arch":       runtime.GOARCH,
+				}, nil))).To(Succeed())
+			})
+
+			it("writes the provenance statement as well", func() {
+				result, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.DeliverCall.CallCount).To(Equal(0))
+				Expect(result.Layers).To(HaveLen(2))
+				Expect(result.Layers[1].Name).To(Equal("mri-provenance"))
+				Expect(filepath.Join(layersDir, "mri-provenance", "provenance.json")).To(BeARegularFile())
+			})
+		})
+	})
+
+	context("when vex.toml has statements about the dependency", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(cnbDir, "vex.toml"), []byte(`
+[[statements]]
+  id = "CVE-2025-0001"
+  cpe = "cpe:2.3:a:ruby-lang:ruby:*:*:*:*:*:*:*:*"
+  versions = "3.4.*"
+  state = "not_affected"
+  justification = "requires_environment"
+
+[[statements]]
+  id = "CVE-2025-0002"
+  cpe = "cpe:2.3:a:ruby-lang:ruby:*:*:*:*:*:*:*:*"
+  versions = "3.3.*"
+  state = "not_affected"
+  justification = "code_not_present"
+`), 0600)).To(Succeed())
+
+			dependencyManager.ResolveCall.Returns.Dependency = postal.Dependency{
+				ID:       "ruby",
+				Name:     "Ruby",
+				Version:  "3.4.8",
+				Checksum: "sha256:some-sha",
+				CPEs:     []string{"cpe:2.3:a:ruby-lang:ruby:3.4.8:*:*:*:*:*:*:*"},
+			}
+		})
+
+		it("records the applicable statements in the CycloneDX SBOM of the MRI layer", func() {
+			result, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			layer := result.Layers[0]
+			Expect(layer.SBOM.Formats()[0].Extension).To(Equal("cdx.json"))
+			content, err := io.ReadAll(layer.SBOM.Formats()[0].Content)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("CVE-2025-0001"))
+			Expect(string(content)).NotTo(ContainSubstring("CVE-2025-0002"))
+
+			Expect(layer.Metadata["inputs"]).To(HaveKey("vex"))
+		})
+
+		context("when the MRI layer was built before the statements were added", func() {
+			it.Before(func() {
+				Expect(writeLayerMetadata(filepath.Join(layersDir, "mri.toml"), cachedMetadata(mri.CacheInputs{
+					"dependency": "sha256:some-sha",
+					"yjit":       "disabled",
+					"verify":     "false",
+					"stack":      "some-stack",
+					"arch":       runtime.GOARCH,
+				}, nil))).To(Succeed())
+			})
+
+			it("rebuilds the layer so that its SBOM is annotated", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.DeliverCall.CallCount).To(Equal(1))
+			})
+		})
+	})
+
+	context("when the cached layer holds a different version", func() {
+		it.Before(func() {
+			t.Setenv("CNB_TARGET_ARCH", "amd64")
+
+			dependencyManager.ResolveCall.Returns.Dependency = postal.Dependency{
+				ID:       "ruby",
+				Name:     "Ruby",
+				Version:  "3.3.12",
+				Checksum: "sha256:some-new-sha",
+			}
+		})
+
+		context("when the new version is newer", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(layersDir, "mri.toml"), []byte(`[metadata]
+dependency-sha = "sha256:some-old-sha"
+version = "3.3.11"
+stack = "some-stack"
+arch = "amd64"
+`), 0600)).To(Succeed())
+			})
+
+			it("logs the upgrade", func() {
+				result, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(buffer.String()).To(ContainSubstring("Upgrading MRI 3.3.11 → 3.3.12"))
+				Expect(result.Layers[0].Metadata).To(HaveKeyWithValue("version", "3.3.12"))
+				Expect(result.Layers[0].Metadata).To(HaveKeyWithValue("stack", "some-stack"))
+				Expect(result.Layers[0].Metadata).To(HaveKeyWithValue("arch", "amd64"))
+			})
+		})
+
+		context("when the new version is older", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(layersDir, "mri.toml"), []byte(`[metadata]
+dependency-sha = "sha256:some-old-sha"
+version = "3.4.8"
+`), 0600)).To(Succeed())
+			})
+
+			it("warns about the downgrade", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(buffer.String()).To(ContainSubstring("WARNING: Downgrading MRI 3.4.8 → 3.3.12"))
+			})
+
+			context("when BP_MRI_DISALLOW_DOWNGRADE is set", func() {
+				it.Before(func() {
+					t.Setenv("BP_MRI_DISALLOW_DOWNGRADE", "true")
+				})
+
+				it("fails the build without touching the cached layer", func() {
+					_, err := build(buildContext)
+
+					var downgrade mri.DowngradeError
+					Expect(errors.As(err, &downgrade)).To(BeTrue())
+					Expect(downgrade.VersionChange).To(Equal(mri.VersionChange{From: "3.4.8", To: "3.3.12"}))
+
+					Expect(dependencyManager.DeliverCall.CallCount).To(Equal(0))
+					Expect(filepath.Join(layersDir, "mri.toml")).To(BeARegularFile())
+				})
+			})
+		})
+
+		context("when the cached layer was installed for another stack", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(layersDir, "mri.toml"), []byte(`[metadata]
+dependency-sha = "sha256:some-old-sha"
+version = "3.3.12"
+stack = "other-stack"
+arch = "amd64"
+`), 0600)).To(Succeed())
+			})
+
+			it("logs why MRI is reinstalled", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(buffer.String()).To(ContainSubstring("Reinstalling MRI for some-stack (amd64), previously installed for other-stack (amd64)"))
+			})
+		})
+	})
+
+	context("when an input changed since the layer was cached", func() {
+		it.Before(func() {
+			t.Setenv("BP_MRI_VERIFY", "true")
+			verifier.VerifyCall.Returns.VerificationReport = mri.VerificationReport{}
+
+			Expect(writeLayerMetadata(filepath.Join(layersDir, "mri.toml"), cachedMetadata(mri.CacheInputs{
+				"dependency": "sha256:some-sha",
+				"yjit":       "disabled",
+				"verify":     "false",
+				"stack":      "some-stack",
+				"arch":       runtime.GOARCH,
+			}, nil))).To(Succeed())
+
+			dependencyManager.ResolveCall.Returns.Dependency.Checksum = "sha256:some-sha"
+
+			build = mri.Build(entryResolver, dependencyManager, bindingResolver, compiler, gem, sbomGenerator, verifier, scribe.NewEmitter(buffer).WithLevel("DEBUG"), clock)
+		})
+
+		it("rebuilds the layer and logs which input invalidated it", func() {
+			_, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(dependencyManager.DeliverCall.CallCount).To(Equal(1))
+			Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("Cached layer %s cannot be reused: inputs changed: verify", filepath.Join(layersDir, "mri"))))
+		})
+	})
 
-			Expect(layer.Build).To(BeTrue())
-			Expect(layer.Launch).To(BeTrue())
-			Expect(layer.Cache).To(BeTrue())
+	for _, testCase := range []struct {
+		name  string
+		input string
+		stack string
+		arch  string
+	}{
+		{"stack", "stack", "other-stack", runtime.GOARCH},
+		{"architecture", "arch", "some-stack", "other-arch"},
+	} {
+		testCase := testCase
+
+		context(fmt.Sprintf("when the layer was cached with the same inputs for another %s", testCase.name), func() {
+			it.Before(func() {
+				Expect(writeLayerMetadata(filepath.Join(layersDir, "mri.toml"), cachedMetadata(mri.CacheInputs{
+					"dependency": "sha256:some-sha",
+					"yjit":       "disabled",
+					"verify":     "false",
+					"stack":      testCase.stack,
+					"arch":       testCase.arch,
+				}, map[string]interface{}{
+					mri.DepKey:   "some-sha",
+					mri.StackKey: testCase.stack,
+					mri.ArchKey:  testCase.arch,
+				}))).To(Succeed())
+
+				dependencyManager.ResolveCall.Returns.Dependency.Checksum = "sha256:some-sha"
+
+				build = mri.Build(entryResolver, dependencyManager, bindingResolver, compiler, gem, sbomGenerator, verifier, scribe.NewEmitter(buffer).WithLevel("DEBUG"), clock)
+			})
 
-			Expect(result.Build.BOM).To(Equal(
-				[]packit.BOMEntry{
-					{
-						Name: "mri",
-						Metadata: paketosbom.BOMMetadata{
-							Version: "mri-dependency-version",
-							Checksum: paketosbom.BOMChecksum{
-								Algorithm: paketosbom.SHA256,
-								Hash:      "mri-dependency-sha",
-							},
-							URI: "mri-dependency-uri",
-						},
-					},
-				},
-			))
+			it("reinstalls MRI", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
 
-			Expect(result.Launch.BOM).To(Equal(
-				[]packit.BOMEntry{
-					{
-						Name: "mri",
-						Metadata: paketosbom.BOMMetadata{
-							Version: "mri-dependency-version",
-							Checksum: paketosbom.BOMChecksum{
-								Algorithm: paketosbom.SHA256,
-								Hash:      "mri-dependency-sha",
-							},
-							URI: "mri-dependency-uri",
-						},
-					},
-				},
-			))
+				Expect(dependencyManager.DeliverCall.CallCount).To(Equal(1))
+				Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("Cached layer %s cannot be reused: inputs changed: %s", filepath.Join(layersDir, "mri"), testCase.input)))
+				Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("Reinstalling MRI for some-stack (%s), previously installed for %s (%s)", runtime.GOARCH, testCase.stack, testCase.arch)))
+			})
 		})
-	})
+	}
 
 	context("when there is a dependency cache match", func() {
 		it.Before(func() {
-			err := os.WriteFile(filepath.Join(layersDir, "mri.toml"), []byte("[metadata]\ndependency-sha = \"some-sha\"\n"), 0600)
+			err := writeLayerMetadata(filepath.Join(layersDir, "mri.toml"), cachedMetadata(mri.CacheInputs{
+				"dependency": "sha256:some-sha",
+				"yjit":       "disabled",
+				"verify":     "false",
+				"stack":      "some-stack",
+				"arch":       runtime.GOARCH,
+			}, map[string]interface{}{
+				mri.DepKey: "some-sha",
+			}))
 			Expect(err).NotTo(HaveOccurred())
 
 			entryResolver.MergeLayerTypesCall.Returns.Launch = false
@@ -392,17 +1507,29 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			Expect(result).To(Equal(packit.BuildResult{
 				Layers: []packit.Layer{
 					{
-						Name:             "mri",
-						Path:             filepath.Join(layersDir, "mri"),
-						SharedEnv:        packit.Environment{},
-						BuildEnv:         packit.Environment{},
+						Name:      "mri",
+						Path:      filepath.Join(layersDir, "mri"),
+						SharedEnv: packit.Environment{},
+						BuildEnv: packit.Environment{
+							"MRI_HOME.override": filepath.Join(layersDir, "mri"),
+						},
 						LaunchEnv:        packit.Environment{},
 						ProcessLaunchEnv: map[string]packit.Environment{},
+						ExecD:            []string{filepath.Join(cnbDir, "bin", "optimize-memory")},
 						Build:            true,
 						Launch:           false,
 						Cache:            true,
 						Metadata: map[string]interface{}{
-							mri.DepKey: "some-sha",
+							mri.DepKey:           "some-sha",
+							mri.SchemaVersionKey: int64(1),
+							mri.InputsKey: mri.CacheInputs{
+								"dependency": "sha256:some-sha",
+								"yjit":       "disabled",
+								"verify":     "false",
+								"stack":      "some-stack",
+								"arch":       runtime.GOARCH,
+							}.Digests(),
+							facts.HomeKey: filepath.Join(layersDir, "mri"),
 						},
 					},
 				},
@@ -467,6 +1594,372 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 
+		context("when the buildpack.toml cannot be parsed to resolve an alias", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(cnbDir, "buildpack.toml"), []byte("%%%"), 0600)).To(Succeed())
+
+				entryResolver.ResolveCall.Returns.BuildpackPlanEntry = packit.BuildpackPlanEntry{
+					Name: "mri",
+					Metadata: map[string]interface{}{
+						"version-source": "BP_MRI_VERSION",
+						"version":        "stable",
+					},
+				}
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring("expected")))
+			})
+		})
+
+		context("when no version matches the alias", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(cnbDir, "buildpack.toml"), []byte(""), 0600)).To(Succeed())
+
+				entryResolver.ResolveCall.Returns.BuildpackPlanEntry = packit.BuildpackPlanEntry{
+					Name: "mri",
+					Metadata: map[string]interface{}{
+						"version-source": "BP_MRI_VERSION",
+						"version":        "stable",
+					},
+				}
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring(`no MRI version matches the "stable" alias on stack "some-stack"`)))
+			})
+		})
+
+		context("when a dependency cannot be resolved for the stack", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(cnbDir, "buildpack.toml"), []byte(`
+[[metadata.dependencies]]
+  id = "ruby"
+  version = "3.4.8"
+  stacks = ["some-stack"]
+
+[[metadata.dependencies]]
+  id = "ruby"
+  version = "4.0.1"
+  stacks = ["other-stack"]
+`), 0600)).To(Succeed())
+
+				entryResolver.ResolveCall.Returns.BuildpackPlanEntry = packit.BuildpackPlanEntry{
+					Name: "mri",
+					Metadata: map[string]interface{}{
+						"version-source": "BP_MRI_VERSION",
+						"version":        "4.0.*",
+					},
+				}
+
+				dependencyManager.ResolveCall.Returns.Error = errors.New("failed to resolve dependency")
+			})
+
+			it("returns an error that explains which versions are available", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring(`failed to satisfy MRI version constraint "4.0.*" on stack "some-stack"`)))
+				Expect(err).To(MatchError(ContainSubstring("Available versions: 3.4.8")))
+				Expect(err).To(MatchError(ContainSubstring(`Versions matching "4.0.*" are only built for: other-stack`)))
+				Expect(err).To(MatchError(ContainSubstring(`Closest matching constraint: "3.4.*"`)))
+
+				var unsatisfied mri.UnsatisfiedVersionError
+				Expect(errors.As(err, &unsatisfied)).To(BeTrue())
+				Expect(errors.Unwrap(err)).To(MatchError("failed to resolve dependency"))
+			})
+		})
+
+		context("when the version has reached end-of-life and BP_MRI_FAIL_ON_EOL is set", func() {
+			it.Before(func() {
+				t.Setenv("BP_MRI_FAIL_ON_EOL", "true")
+
+				dependencyManager.ResolveCall.Returns.Dependency = postal.Dependency{
+					ID:              "ruby",
+					Name:            "Ruby",
+					Version:         "3.1.7",
+					DeprecationDate: time.Date(2025, time.March, 31, 0, 0, 0, 0, time.UTC),
+				}
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError("MRI 3.1.7 reached end-of-life on 2025-03-31 and $BP_MRI_FAIL_ON_EOL is set: select a supported version of MRI"))
+				Expect(dependencyManager.DeliverCall.CallCount).To(Equal(0))
+			})
+		})
+
+		context("when BP_MRI_EOL_WARNING_DAYS is invalid", func() {
+			it.Before(func() {
+				t.Setenv("BP_MRI_EOL_WARNING_DAYS", "a month")
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(`invalid value for $BP_MRI_EOL_WARNING_DAYS: "a month" is not a non-negative number of days`))
+			})
+		})
+
+		context("when BP_MRI_YJIT is invalid", func() {
+			it.Before(func() {
+				t.Setenv("BP_MRI_YJIT", "maybe")
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring("invalid value for $BP_MRI_YJIT")))
+			})
+		})
+
+		context("when the mri-artifact bindings cannot be resolved", func() {
+			it.Before(func() {
+				bindingResolver.ResolveCall.Returns.Error = errors.New("failed to load bindings")
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError("failed to resolve mri-artifact binding: failed to load bindings"))
+			})
+		})
+
+		context("when there is more than one mri-artifact binding", func() {
+			it.Before(func() {
+				bindingResolver.ResolveCall.Returns.BindingSlice = []servicebindings.Binding{{Name: "first"}, {Name: "second"}}
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError("found 2 mri-artifact bindings, expected at most one"))
+			})
+		})
+
+		context("when the mri-artifact binding is incomplete", func() {
+			it.Before(func() {
+				bindingResolver.ResolveCall.Returns.BindingSlice = []servicebindings.Binding{
+					{
+						Name: "hardened-ruby",
+						Entries: map[string]*servicebindings.Entry{
+							"uri":     servicebindings.NewWithValue([]byte("https://artifacts.example.com/ruby-3.4.8.tgz")),
+							"version": servicebindings.NewWithValue([]byte("3.4.8")),
+						},
+					},
+				}
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(`binding "hardened-ruby" is missing the required "checksum" entry`))
+			})
+		})
+
+		context("when the mri-artifact binding has an invalid checksum", func() {
+			it.Before(func() {
+				bindingResolver.ResolveCall.Returns.BindingSlice = []servicebindings.Binding{
+					{
+						Name: "hardened-ruby",
+						Entries: map[string]*servicebindings.Entry{
+							"uri":      servicebindings.NewWithValue([]byte("https://artifacts.example.com/ruby-3.4.8.tgz")),
+							"version":  servicebindings.NewWithValue([]byte("3.4.8")),
+							"checksum": servicebindings.NewWithValue([]byte("not-a-checksum")),
+						},
+					},
+				}
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(`binding "hardened-ruby" has an invalid checksum "not-a-checksum": expected <algorithm>:<hash>`))
+			})
+		})
+
+		context("when the mri-artifact binding does not locate the artifact", func() {
+			it.Before(func() {
+				bindingResolver.ResolveCall.Returns.BindingSlice = []servicebindings.Binding{
+					{
+						Name: "hardened-ruby",
+						Entries: map[string]*servicebindings.Entry{
+							"version":  servicebindings.NewWithValue([]byte("3.4.8")),
+							"checksum": servicebindings.NewWithValue([]byte("sha256:some-checksum")),
+						},
+					},
+				}
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(`binding "hardened-ruby" must provide either a "uri" or a "path" entry`))
+			})
+		})
+
+		context("when the installed interpreter fails verification", func() {
+			it.Before(func() {
+				t.Setenv("BP_MRI_VERIFY", "true")
+
+				dependencyManager.ResolveCall.Returns.Dependency.Version = "3.4.8"
+				verifier.VerifyCall.Returns.VerificationReport = mri.VerificationReport{
+					Checks: []mri.VerificationCheck{
+						{Name: "version", Expected: "3.4.8", Actual: "3.4.7", Passed: false},
+						{Name: "extension openssl", Expected: "loaded", Actual: "cannot load such file -- openssl", Passed: false},
+					},
+				}
+			})
+
+			it("returns an error with the report", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(`MRI 3.4.8 failed verification:
+  [FAIL] version: expected 3.4.8, got 3.4.7
+  [FAIL] extension openssl: expected loaded, got cannot load such file -- openssl`))
+
+				var verificationErr mri.VerificationError
+				Expect(errors.As(err, &verificationErr)).To(BeTrue())
+				Expect(verificationErr.Report.Checks).To(HaveLen(2))
+			})
+		})
+
+		context("when the installed interpreter cannot be verified", func() {
+			it.Before(func() {
+				t.Setenv("BP_MRI_VERIFY", "true")
+				verifier.VerifyCall.Returns.Error = errors.New("failed to run ruby")
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError("failed to verify MRI installation: failed to run ruby"))
+			})
+		})
+
+		context("when BP_MRI_VERIFY is invalid", func() {
+			it.Before(func() {
+				t.Setenv("BP_MRI_VERIFY", "always")
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring("invalid value for $BP_MRI_VERIFY")))
+			})
+		})
+
+		context("when BP_MRI_SLIM_LAUNCH is invalid", func() {
+			it.Before(func() {
+				t.Setenv("BP_MRI_SLIM_LAUNCH", "slightly")
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring("invalid value for $BP_MRI_SLIM_LAUNCH")))
+			})
+		})
+
+		context("when BP_MRI_VERSION_RESOLUTION is invalid", func() {
+			it.Before(func() {
+				t.Setenv("BP_MRI_VERSION_RESOLUTION", "some-bad-value")
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring("invalid value for $BP_MRI_VERSION_RESOLUTION")))
+			})
+		})
+
+		context("when BP_MRI_BUILD_REPORT is invalid", func() {
+			it.Before(func() {
+				t.Setenv("BP_MRI_BUILD_REPORT", "some-bad-value")
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring("invalid value for $BP_MRI_BUILD_REPORT")))
+			})
+		})
+
+		context("when vex.toml is invalid", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(cnbDir, "vex.toml"), []byte("[[statements]]\nid = \"CVE-1\"\n"), 0600)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring("invalid VEX statement")))
+			})
+		})
+
+		context("when BP_MRI_PROVENANCE is invalid", func() {
+			it.Before(func() {
+				t.Setenv("BP_MRI_PROVENANCE", "some-bad-value")
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring("invalid value for $BP_MRI_PROVENANCE")))
+			})
+		})
+
+		context("when BP_MRI_DISALLOW_DOWNGRADE is invalid", func() {
+			it.Before(func() {
+				t.Setenv("BP_MRI_DISALLOW_DOWNGRADE", "some-bad-value")
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring("invalid value for $BP_MRI_DISALLOW_DOWNGRADE")))
+			})
+		})
+
+		context("when BP_MRI_COMPILE_FROM_SOURCE is invalid", func() {
+			it.Before(func() {
+				t.Setenv("BP_MRI_COMPILE_FROM_SOURCE", "some-bad-value")
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring("invalid value for $BP_MRI_COMPILE_FROM_SOURCE")))
+			})
+		})
+
+		context("when BP_MRI_COMPILE_FROM_SOURCE is set and no source matches the version", func() {
+			it.Before(func() {
+				t.Setenv("BP_MRI_COMPILE_FROM_SOURCE", "true")
+
+				Expect(os.WriteFile(filepath.Join(cnbDir, "buildpack.toml"), []byte(`
+[[metadata.dependencies]]
+  id = "ruby"
+  version = "3.4.8"
+  stacks = ["other-stack"]
+`), 0600)).To(Succeed())
+
+				dependencyManager.ResolveCall.Returns.Error = errors.New("failed to resolve dependency")
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring(`failed to satisfy MRI version constraint "2.5.x"`)))
+				Expect(err).To(MatchError(ContainSubstring(`failed to compile MRI from source: no MRI source matches the version constraint "2.5.x"`)))
+			})
+		})
+
+		context("when MRI cannot be compiled from source", func() {
+			it.Before(func() {
+				t.Setenv("BP_MRI_COMPILE_FROM_SOURCE", "true")
+
+				Expect(os.WriteFile(filepath.Join(cnbDir, "buildpack.toml"), []byte(`
+[[metadata.dependencies]]
+  id = "ruby"
+  version = "2.5.9"
+  source = "https://cache.ruby-lang.org/pub/ruby/2.5/ruby-2.5.9.tar.gz"
+  source-checksum = "sha256:some-source-sha"
+  stacks = ["other-stack"]
+`), 0600)).To(Succeed())
+
+				dependencyManager.ResolveCall.Returns.Error = errors.New("failed to resolve dependency")
+				compiler.CompileCall.Returns.Error = errors.New("failed to compile MRI")
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError("failed to compile MRI"))
+			})
+		})
+
 		context("when a dependency cannot be installed", func() {
 			it.Before(func() {
 				dependencyManager.DeliverCall.Returns.Error = errors.New("failed to install dependency")
@@ -655,3 +2148,26 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		})
 	})
 }
+
+// writeLayerMetadata writes the metadata of a cached layer the way packit
+// does.
+func writeLayerMetadata(path string, metadata map[string]interface{}) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return toml.NewEncoder(file).Encode(map[string]interface{}{"metadata": metadata})
+}
+
+// cachedMetadata returns the metadata of an MRI layer built from the given
+// inputs.
+func cachedMetadata(inputs mri.CacheInputs, extra map[string]interface{}) map[string]interface{} {
+	metadata := inputs.Metadata()
+	for key, value := range extra {
+		metadata[key] = value
+	}
+
+	return metadata
+}
@@ -0,0 +1,88 @@
+package mri
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/cargo"
+)
+
+// CacheSchemaVersion is the version of the layout of the MRI layer metadata
+// that the reuse decision relies on. Bump it whenever the meaning of the
+// recorded inputs changes so that layers written by older buildpacks are
+// rebuilt.
+const CacheSchemaVersion int64 = 1
+
+// CacheInputs are the inputs that affect the contents of the MRI layer, by
+// name. The layer is only reused when all of them match those it was built
+// from.
+type CacheInputs map[string]string
+
+// dependencyInput normalizes a dependency checksum so that a bare SHA-256
+// and its algorithm-prefixed form are the same input.
+func dependencyInput(checksum string) string {
+	if checksum == "" {
+		return ""
+	}
+
+	c := cargo.Checksum(checksum)
+	return fmt.Sprintf("%s:%s", c.Algorithm(), c.Hash())
+}
+
+// Digests returns the digest of each input, as recorded in the layer
+// metadata.
+func (i CacheInputs) Digests() map[string]interface{} {
+	digests := map[string]interface{}{}
+	for name, value := range i {
+		digests[name] = fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(value)))
+	}
+
+	return digests
+}
+
+// Metadata returns the schema version and the input digests to record in the
+// layer metadata.
+func (i CacheInputs) Metadata() map[string]interface{} {
+	return map[string]interface{}{
+		SchemaVersionKey: CacheSchemaVersion,
+		InputsKey:        i.Digests(),
+	}
+}
+
+// Invalidated describes why the layer with the given metadata cannot be
+// reused, naming the inputs that changed. It returns an empty string when
+// the layer can be reused.
+func (i CacheInputs) Invalidated(metadata map[string]interface{}) string {
+	version, _ := metadata[SchemaVersionKey].(int64)
+	if version != CacheSchemaVersion {
+		if version == 0 {
+			return fmt.Sprintf("metadata schema version changed (none → %d)", CacheSchemaVersion)
+		}
+		return fmt.Sprintf("metadata schema version changed (%d → %d)", version, CacheSchemaVersion)
+	}
+
+	cached, _ := metadata[InputsKey].(map[string]interface{})
+	digests := i.Digests()
+
+	var changed []string
+	for name, digest := range digests {
+		if cached[name] != digest {
+			changed = append(changed, name)
+		}
+	}
+
+	for name := range cached {
+		if _, ok := digests[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+
+	if len(changed) == 0 {
+		return ""
+	}
+
+	sort.Strings(changed)
+	return fmt.Sprintf("inputs changed: %s", strings.Join(changed, ", "))
+}
@@ -0,0 +1,80 @@
+package mri_test
+
+import (
+	"testing"
+
+	"github.com/paketo-buildpacks/mri"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testCacheInputs(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		inputs mri.CacheInputs
+	)
+
+	it.Before(func() {
+		inputs = mri.CacheInputs{
+			"dependency": "sha256:some-sha",
+			"yjit":       "disabled",
+			"verify":     "false",
+		}
+	})
+
+	context("Metadata", func() {
+		it("records the schema version and a digest of each input", func() {
+			Expect(inputs.Metadata()).To(Equal(map[string]interface{}{
+				"schema-version": mri.CacheSchemaVersion,
+				"inputs": map[string]interface{}{
+					"dependency": "sha256:2b4abdb1ede6fa3946e9fb418594b715489d814cf3eb8f12ac6ed4621494d982",
+					"yjit":       "sha256:17eb3c0168d0d7b21ede5481150f17233427d89833ec121b4dbc4fb96cfab71e",
+					"verify":     "sha256:fcbcf165908dd18a9e49f7ff27810176db8e9f63b4352213741664245224f8aa",
+				},
+			}))
+		})
+	})
+
+	context("Invalidated", func() {
+		it("is empty when every input matches", func() {
+			Expect(inputs.Invalidated(inputs.Metadata())).To(BeEmpty())
+		})
+
+		it("names the inputs that changed", func() {
+			metadata := mri.CacheInputs{
+				"dependency": "sha256:some-sha",
+				"yjit":       "enabled",
+				"verify":     "true",
+			}.Metadata()
+
+			Expect(inputs.Invalidated(metadata)).To(Equal("inputs changed: verify, yjit"))
+		})
+
+		it("names the inputs that were added or removed", func() {
+			metadata := mri.CacheInputs{
+				"dependency":      "sha256:some-sha",
+				"yjit":            "disabled",
+				"configure-flags": "--enable-load-relative",
+			}.Metadata()
+
+			Expect(inputs.Invalidated(metadata)).To(Equal("inputs changed: configure-flags, verify"))
+		})
+
+		context("when the layer predates the schema", func() {
+			it("reports the schema version change", func() {
+				Expect(inputs.Invalidated(map[string]interface{}{"dependency-sha": "some-sha"})).To(Equal("metadata schema version changed (none → 1)"))
+			})
+		})
+
+		context("when the layer was written with another schema version", func() {
+			it("reports the schema version change", func() {
+				metadata := inputs.Metadata()
+				metadata["schema-version"] = int64(7)
+
+				Expect(inputs.Invalidated(metadata)).To(Equal("metadata schema version changed (7 → 1)"))
+			})
+		})
+	})
+}
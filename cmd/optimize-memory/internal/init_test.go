@@ -0,0 +1,14 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestUnitOptimizeMemory(t *testing.T) {
+	suite := spec.New("optimize-memory", spec.Report(report.Terminal{}))
+	suite("Run", testRun)
+	suite.Run(t)
+}
@@ -0,0 +1,176 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+const (
+	mebibyte = 1024 * 1024
+	gibibyte = 1024 * mebibyte
+
+	// cgroup v1 reports an "unlimited" memory limit as a page-aligned value
+	// close to the maximum int64. Anything this large is treated as unset.
+	unlimitedMemory = 1 << 62
+)
+
+// Run computes memory and garbage collector settings for Ruby from the
+// cgroup limits found under the given root and writes them to the output in
+// the TOML format expected from exec.d executables. Variables that are
+// already present in the environment are never overridden.
+//
+// A limit that cannot be read or parsed is treated as unset, and a warning
+// is written to warnings: a failing exec.d executable would prevent the
+// application from starting.
+func Run(environment map[string]string, output, warnings io.Writer, root string) error {
+	memoryLimit, err := readMemoryLimit(root)
+	if err != nil {
+		fmt.Fprintf(warnings, "WARNING: %s, assuming no memory limit\n", err)
+		memoryLimit = 0
+	}
+
+	cpus, err := readCPULimit(root)
+	if err != nil {
+		cpus = runtime.NumCPU()
+		fmt.Fprintf(warnings, "WARNING: %s, assuming %d CPUs\n", err, cpus)
+	}
+
+	variables := tune(memoryLimit, cpus)
+	for key := range variables {
+		if _, ok := environment[key]; ok {
+			delete(variables, key)
+		}
+	}
+
+	return toml.NewEncoder(output).Encode(variables)
+}
+
+// tune returns the settings for a container with the given memory limit in
+// bytes (zero when unlimited) and number of CPUs.
+func tune(memoryLimit int64, cpus int) map[string]string {
+	// glibc creates up to 8 malloc arenas per CPU, which fragments memory in
+	// multi-threaded servers such as Puma. Two arenas keep fragmentation low;
+	// containers with more memory can afford one arena per CPU, up to four.
+	arenas := 2
+	if memoryLimit >= gibibyte {
+		arenas = min(max(cpus, 2), 4)
+	}
+
+	variables := map[string]string{
+		"MALLOC_ARENA_MAX": strconv.Itoa(arenas),
+	}
+
+	if memoryLimit == 0 || memoryLimit >= gibibyte {
+		return variables
+	}
+
+	// Under tight limits, grow the heap in smaller steps and trigger major
+	// collections earlier so that the process stays within its limit.
+	mallocLimit := max(min(memoryLimit/32, 32*mebibyte), 4*mebibyte)
+	variables["RUBY_GC_HEAP_GROWTH_FACTOR"] = "1.1"
+	variables["RUBY_GC_HEAP_GROWTH_MAX_SLOTS"] = "100000"
+	variables["RUBY_GC_MALLOC_LIMIT_MAX"] = strconv.FormatInt(mallocLimit, 10)
+	variables["RUBY_GC_OLDMALLOC_LIMIT_MAX"] = strconv.FormatInt(mallocLimit*2, 10)
+
+	return variables
+}
+
+// readMemoryLimit returns the memory limit in bytes from the cgroup v2 or v1
+// hierarchy, or zero when no limit is set.
+func readMemoryLimit(root string) (int64, error) {
+	for _, path := range []string{
+		filepath.Join(root, "sys", "fs", "cgroup", "memory.max"),
+		filepath.Join(root, "sys", "fs", "cgroup", "memory", "memory.limit_in_bytes"),
+	} {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return 0, fmt.Errorf("failed to read memory limit: %w", err)
+		}
+
+		value := strings.TrimSpace(string(content))
+		if value == "max" {
+			return 0, nil
+		}
+
+		limit, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse memory limit %q from %s: %w", value, path, err)
+		}
+
+		if limit >= unlimitedMemory {
+			return 0, nil
+		}
+
+		return limit, nil
+	}
+
+	return 0, nil
+}
+
+// readCPULimit returns the number of CPUs available to the container from
+// the cgroup v2 or v1 CPU quota, falling back to the number of CPUs on the
+// host when no quota is set.
+func readCPULimit(root string) (int, error) {
+	cgroupV2 := filepath.Join(root, "sys", "fs", "cgroup", "cpu.max")
+	content, err := os.ReadFile(cgroupV2)
+	if err == nil {
+		fields := strings.Fields(string(content))
+		if len(fields) != 2 {
+			return 0, fmt.Errorf("failed to parse CPU limit %q from %s", strings.TrimSpace(string(content)), cgroupV2)
+		}
+
+		return cpusFromQuota(fields[0], fields[1], cgroupV2)
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return 0, fmt.Errorf("failed to read CPU limit: %w", err)
+	}
+
+	quota, err := os.ReadFile(filepath.Join(root, "sys", "fs", "cgroup", "cpu", "cpu.cfs_quota_us"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return runtime.NumCPU(), nil
+		}
+
+		return 0, fmt.Errorf("failed to read CPU limit: %w", err)
+	}
+
+	cgroupV1 := filepath.Join(root, "sys", "fs", "cgroup", "cpu", "cpu.cfs_period_us")
+	period, err := os.ReadFile(cgroupV1)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read CPU limit: %w", err)
+	}
+
+	return cpusFromQuota(strings.TrimSpace(string(quota)), strings.TrimSpace(string(period)), cgroupV1)
+}
+
+func cpusFromQuota(quota, period, path string) (int, error) {
+	if quota == "max" || quota == "-1" {
+		return runtime.NumCPU(), nil
+	}
+
+	q, err := strconv.ParseInt(quota, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse CPU quota %q from %s: %w", quota, path, err)
+	}
+
+	p, err := strconv.ParseInt(period, 10, 64)
+	if err != nil || p <= 0 {
+		return 0, fmt.Errorf("failed to parse CPU period %q from %s", period, path)
+	}
+
+	// Round partial CPUs up so that a quota of half a CPU still counts as one.
+	return int(max((q+p-1)/p, 1)), nil
+}
@@ -0,0 +1,240 @@
+package internal_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/paketo-buildpacks/mri/cmd/optimize-memory/internal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testRun(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		root     string
+		output   *bytes.Buffer
+		warnings *bytes.Buffer
+	)
+
+	writeFile := func(path, content string) {
+		path = filepath.Join(root, path)
+		Expect(os.MkdirAll(filepath.Dir(path), os.ModePerm)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+	}
+
+	it.Before(func() {
+		var err error
+		root, err = os.MkdirTemp("", "root")
+		Expect(err).NotTo(HaveOccurred())
+
+		output = bytes.NewBuffer(nil)
+		warnings = bytes.NewBuffer(nil)
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(root)).To(Succeed())
+	})
+
+	context("when the container uses cgroup v2", func() {
+		it.Before(func() {
+			writeFile("sys/fs/cgroup/memory.max", "536870912\n")
+			writeFile("sys/fs/cgroup/cpu.max", "150000 100000\n")
+		})
+
+		it("tunes the memory settings for the limits", func() {
+			err := internal.Run(map[string]string{}, output, warnings, root)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(output.String()).To(Equal(`MALLOC_ARENA_MAX = "2"
+RUBY_GC_HEAP_GROWTH_FACTOR = "1.1"
+RUBY_GC_HEAP_GROWTH_MAX_SLOTS = "100000"
+RUBY_GC_MALLOC_LIMIT_MAX = "16777216"
+RUBY_GC_OLDMALLOC_LIMIT_MAX = "33554432"
+`))
+		})
+
+		context("when the memory limit is at least 1GiB", func() {
+			it.Before(func() {
+				writeFile("sys/fs/cgroup/memory.max", "4294967296\n")
+			})
+
+			it("allows one malloc arena per CPU and leaves the GC defaults alone", func() {
+				err := internal.Run(map[string]string{}, output, warnings, root)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(output.String()).To(Equal("MALLOC_ARENA_MAX = \"2\"\n"))
+			})
+
+			context("when more CPUs are available", func() {
+				it.Before(func() {
+					writeFile("sys/fs/cgroup/cpu.max", "800000 100000\n")
+				})
+
+				it("caps the number of malloc arenas", func() {
+					err := internal.Run(map[string]string{}, output, warnings, root)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(output.String()).To(Equal("MALLOC_ARENA_MAX = \"4\"\n"))
+				})
+			})
+		})
+
+		context("when there is no memory limit", func() {
+			it.Before(func() {
+				writeFile("sys/fs/cgroup/memory.max", "max\n")
+				writeFile("sys/fs/cgroup/cpu.max", "max 100000\n")
+			})
+
+			it("only limits the malloc arenas", func() {
+				err := internal.Run(map[string]string{}, output, warnings, root)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(output.String()).To(Equal("MALLOC_ARENA_MAX = \"2\"\n"))
+			})
+		})
+
+		context("when the variables are already set", func() {
+			it("does not override them", func() {
+				err := internal.Run(map[string]string{
+					"MALLOC_ARENA_MAX":           "8",
+					"RUBY_GC_HEAP_GROWTH_FACTOR": "1.8",
+				}, output, warnings, root)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(output.String()).To(Equal(`RUBY_GC_HEAP_GROWTH_MAX_SLOTS = "100000"
+RUBY_GC_MALLOC_LIMIT_MAX = "16777216"
+RUBY_GC_OLDMALLOC_LIMIT_MAX = "33554432"
+`))
+			})
+		})
+	})
+
+	context("when the container uses cgroup v1", func() {
+		it.Before(func() {
+			writeFile("sys/fs/cgroup/memory/memory.limit_in_bytes", "268435456\n")
+			writeFile("sys/fs/cgroup/cpu/cpu.cfs_quota_us", "50000\n")
+			writeFile("sys/fs/cgroup/cpu/cpu.cfs_period_us", "100000\n")
+		})
+
+		it("tunes the memory settings for the limits", func() {
+			err := internal.Run(map[string]string{}, output, warnings, root)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(output.String()).To(Equal(`MALLOC_ARENA_MAX = "2"
+RUBY_GC_HEAP_GROWTH_FACTOR = "1.1"
+RUBY_GC_HEAP_GROWTH_MAX_SLOTS = "100000"
+RUBY_GC_MALLOC_LIMIT_MAX = "8388608"
+RUBY_GC_OLDMALLOC_LIMIT_MAX = "16777216"
+`))
+		})
+
+		context("when the memory limit is unset", func() {
+			it.Before(func() {
+				writeFile("sys/fs/cgroup/memory/memory.limit_in_bytes", "9223372036854771712\n")
+			})
+
+			it("only limits the malloc arenas", func() {
+				err := internal.Run(map[string]string{}, output, warnings, root)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(output.String()).To(Equal("MALLOC_ARENA_MAX = \"2\"\n"))
+			})
+		})
+	})
+
+	context("when the cgroup limits cannot be used", func() {
+		context("when the memory limit cannot be parsed", func() {
+			it.Before(func() {
+				writeFile("sys/fs/cgroup/memory.max", "lots\n")
+				writeFile("sys/fs/cgroup/cpu.max", "max 100000\n")
+			})
+
+			it("assumes no memory limit and warns", func() {
+				err := internal.Run(map[string]string{}, output, warnings, root)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(output.String()).To(Equal("MALLOC_ARENA_MAX = \"2\"\n"))
+				Expect(warnings.String()).To(ContainSubstring(`WARNING: failed to parse memory limit "lots"`))
+				Expect(warnings.String()).To(ContainSubstring("assuming no memory limit"))
+			})
+		})
+
+		context("when the CPU limit cannot be parsed", func() {
+			it.Before(func() {
+				writeFile("sys/fs/cgroup/memory.max", "536870912\n")
+				writeFile("sys/fs/cgroup/cpu.max", "some-quota\n")
+			})
+
+			it("assumes the CPUs of the host and warns", func() {
+				err := internal.Run(map[string]string{}, output, warnings, root)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(output.String()).To(ContainSubstring("RUBY_GC_MALLOC_LIMIT_MAX = \"16777216\""))
+				Expect(warnings.String()).To(ContainSubstring(`WARNING: failed to parse CPU limit "some-quota"`))
+				Expect(warnings.String()).To(ContainSubstring(fmt.Sprintf("assuming %d CPUs", runtime.NumCPU())))
+			})
+		})
+
+		context("when the CPU quota cannot be parsed", func() {
+			it.Before(func() {
+				writeFile("sys/fs/cgroup/cpu/cpu.cfs_quota_us", "some-quota\n")
+				writeFile("sys/fs/cgroup/cpu/cpu.cfs_period_us", "100000\n")
+			})
+
+			it("assumes the CPUs of the host and warns", func() {
+				err := internal.Run(map[string]string{}, output, warnings, root)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(output.String()).To(Equal("MALLOC_ARENA_MAX = \"2\"\n"))
+				Expect(warnings.String()).To(ContainSubstring(`WARNING: failed to parse CPU quota "some-quota"`))
+			})
+		})
+
+		context("when the CPU period file is missing", func() {
+			it.Before(func() {
+				writeFile("sys/fs/cgroup/cpu/cpu.cfs_quota_us", "50000\n")
+			})
+
+			it("assumes the CPUs of the host and warns", func() {
+				err := internal.Run(map[string]string{}, output, warnings, root)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(output.String()).To(Equal("MALLOC_ARENA_MAX = \"2\"\n"))
+				Expect(warnings.String()).To(ContainSubstring("WARNING: failed to read CPU limit"))
+			})
+		})
+
+		context("when the memory limit cannot be read", func() {
+			it.Before(func() {
+				writeFile("sys/fs/cgroup/memory.max", "536870912\n")
+				Expect(os.Chmod(filepath.Join(root, "sys/fs/cgroup/memory.max"), 0000)).To(Succeed())
+			})
+
+			it("assumes no memory limit and warns", func() {
+				err := internal.Run(map[string]string{}, output, warnings, root)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(output.String()).To(Equal("MALLOC_ARENA_MAX = \"2\"\n"))
+				Expect(warnings.String()).To(ContainSubstring("WARNING: failed to read memory limit"))
+				Expect(warnings.String()).To(ContainSubstring("permission denied"))
+			})
+		})
+	})
+
+	context("when no cgroup files exist", func() {
+		it("assumes no limits without warning", func() {
+			err := internal.Run(map[string]string{}, output, warnings, root)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(output.String()).To(Equal("MALLOC_ARENA_MAX = \"2\"\n"))
+			Expect(warnings.String()).To(BeEmpty())
+		})
+	})
+}
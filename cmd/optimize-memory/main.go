@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/paketo-buildpacks/mri/cmd/optimize-memory/internal"
+)
+
+func main() {
+	environment := map[string]string{}
+	for _, variable := range os.Environ() {
+		if key, value, ok := strings.Cut(variable, "="); ok {
+			environment[key] = value
+		}
+	}
+
+	// exec.d executables write their environment modifications to file
+	// descriptor 3. The settings are an optimization, so a failure is
+	// reported without exiting non-zero, which would abort the launch.
+	err := internal.Run(environment, os.NewFile(3, "/dev/fd/3"), os.Stderr, "/")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: failed to optimize memory settings: %s\n", err)
+	}
+}
@@ -2,7 +2,24 @@ package mri
 
 const (
 	MRI                = "mri"
+	MRILaunch          = "mri-launch"
+	MRIReport          = "mri-report"
+	MRIProvenance      = "mri-provenance"
 	BuildpackYMLSource = "buildpack.yml"
+	RubyVersionSource  = ".ruby-version"
+	GemfileSource      = "Gemfile"
+	GemfileLockSource  = "Gemfile.lock"
+	ToolVersionsSource = ".tool-versions"
+	MiseTOMLSource     = "mise.toml"
+	GemspecSource      = "gemspec"
 
-	DepKey = "dependency-sha"
+	DepKey            = "dependency-sha"
+	YJITKey           = "yjit"
+	CustomArtifactKey = "custom-artifact"
+	SourceChecksumKey = "source-checksum"
+	ConfigureFlagsKey = "configure-flags"
+	StackKey          = "stack"
+	ArchKey           = "arch"
+	SchemaVersionKey  = "schema-version"
+	InputsKey         = "inputs"
 )
@@ -0,0 +1,111 @@
+package mri
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+)
+
+// CustomArtifactBindingType is the service binding type that supplies an MRI
+// artifact to install instead of the dependencies in the buildpack.toml.
+const CustomArtifactBindingType = "mri-artifact"
+
+var sha256Checksum = regexp.MustCompile(`^[a-fA-F0-9]{64}$`)
+
+// resolveCustomArtifact builds the MRI dependency from the mri-artifact
+// binding, if there is one. The binding must provide a version, a checksum
+// and either a uri or a path to the artifact, relative to the binding when
+// not absolute. It may also provide licenses (comma separated), a cpe and a
+// purl. File paths are expressed relative to the buildpack directory because
+// postal resolves file:// URIs against it.
+func resolveCustomArtifact(bindings BindingResolver, platformDir, cnbPath, stack string) (postal.Dependency, bool, error) {
+	resolved, err := bindings.Resolve(CustomArtifactBindingType, "", platformDir)
+	if err != nil {
+		return postal.Dependency{}, false, fmt.Errorf("failed to resolve %s binding: %w", CustomArtifactBindingType, err)
+	}
+
+	if len(resolved) == 0 {
+		return postal.Dependency{}, false, nil
+	}
+
+	if len(resolved) > 1 {
+		return postal.Dependency{}, false, fmt.Errorf("found %d %s bindings, expected at most one", len(resolved), CustomArtifactBindingType)
+	}
+
+	binding := resolved[0]
+	entries := map[string]string{}
+	for key, entry := range binding.Entries {
+		value, err := entry.ReadString()
+		if err != nil {
+			return postal.Dependency{}, false, fmt.Errorf("failed to read %q entry of binding %q: %w", key, binding.Name, err)
+		}
+		entries[key] = strings.TrimSpace(value)
+	}
+
+	for _, key := range []string{"version", "checksum"} {
+		if entries[key] == "" {
+			return postal.Dependency{}, false, fmt.Errorf("binding %q is missing the required %q entry", binding.Name, key)
+		}
+	}
+
+	if _, err := semver.NewVersion(entries["version"]); err != nil {
+		return postal.Dependency{}, false, fmt.Errorf("binding %q has an invalid version %q: %w", binding.Name, entries["version"], err)
+	}
+
+	checksum := entries["checksum"]
+	if sha256Checksum.MatchString(checksum) {
+		checksum = "sha256:" + checksum
+	}
+
+	if algorithm, hash, ok := strings.Cut(checksum, ":"); !ok || algorithm == "" || hash == "" {
+		return postal.Dependency{}, false, fmt.Errorf("binding %q has an invalid checksum %q: expected <algorithm>:<hash>", binding.Name, entries["checksum"])
+	}
+
+	uri := entries["uri"]
+	switch {
+	case uri != "" && entries["path"] != "":
+		return postal.Dependency{}, false, fmt.Errorf("binding %q must provide either a %q or a %q entry, not both", binding.Name, "uri", "path")
+
+	case entries["path"] != "":
+		path := entries["path"]
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(binding.Path, path)
+		}
+
+		relative, err := filepath.Rel(cnbPath, path)
+		if err != nil {
+			return postal.Dependency{}, false, err
+		}
+		uri = "file://" + relative
+
+	case uri == "":
+		return postal.Dependency{}, false, fmt.Errorf("binding %q must provide either a %q or a %q entry", binding.Name, "uri", "path")
+	}
+
+	dependency := postal.Dependency{
+		ID:       "ruby",
+		Name:     "Ruby",
+		Version:  entries["version"],
+		URI:      uri,
+		Checksum: checksum,
+		CPE:      entries["cpe"],
+		PURL:     entries["purl"],
+		Stacks:   []string{stack},
+	}
+
+	if entries["licenses"] != "" {
+		for _, license := range strings.Split(entries["licenses"], ",") {
+			dependency.Licenses = append(dependency.Licenses, strings.TrimSpace(license))
+		}
+	}
+
+	if dependency.PURL == "" {
+		dependency.PURL = fmt.Sprintf("pkg:generic/ruby@%s", dependency.Version)
+	}
+
+	return dependency, true, nil
+}
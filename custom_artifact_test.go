@@ -0,0 +1,231 @@
+package mri_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/paketo-buildpacks/mri"
+	"github.com/paketo-buildpacks/mri/fakes"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/paketo-buildpacks/packit/v2/servicebindings"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testCustomArtifact(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		binding         servicebindings.Binding
+		bindingResolver *fakes.BindingResolver
+	)
+
+	it.Before(func() {
+		binding = servicebindings.Binding{
+			Name: "some-binding",
+			Type: mri.CustomArtifactBindingType,
+			Path: "/platform/bindings/some-binding",
+			Entries: map[string]*servicebindings.Entry{
+				"uri":      servicebindings.NewWithValue([]byte("https://artifacts.example.com/ruby-3.4.8.tgz\n")),
+				"checksum": servicebindings.NewWithValue([]byte("sha256:some-checksum")),
+				"version":  servicebindings.NewWithValue([]byte("3.4.8")),
+			},
+		}
+
+		bindingResolver = &fakes.BindingResolver{}
+		bindingResolver.ResolveCall.Stub = func(string, string, string) ([]servicebindings.Binding, error) {
+			return []servicebindings.Binding{binding}, nil
+		}
+	})
+
+	resolve := func() (postal.Dependency, bool, error) {
+		return mri.ResolveCustomArtifact(bindingResolver, "/platform", "/cnb/buildpacks/mri", "some-stack")
+	}
+
+	context("resolveCustomArtifact", func() {
+		it("builds the dependency from the binding", func() {
+			dependency, ok, err := resolve()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+
+			Expect(dependency).To(Equal(postal.Dependency{
+				ID:       "ruby",
+				Name:     "Ruby",
+				Version:  "3.4.8",
+				URI:      "https://artifacts.example.com/ruby-3.4.8.tgz",
+				Checksum: "sha256:some-checksum",
+				PURL:     "pkg:generic/ruby@3.4.8",
+				Stacks:   []string{"some-stack"},
+			}))
+
+			Expect(bindingResolver.ResolveCall.Receives.Typ).To(Equal("mri-artifact"))
+			Expect(bindingResolver.ResolveCall.Receives.PlatformDir).To(Equal("/platform"))
+		})
+
+		context("when there is no binding", func() {
+			it.Before(func() {
+				bindingResolver.ResolveCall.Stub = nil
+			})
+
+			it("returns no dependency", func() {
+				_, ok, err := resolve()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ok).To(BeFalse())
+			})
+		})
+
+		context("when the checksum is a bare sha256", func() {
+			it.Before(func() {
+				binding.Entries["checksum"] = servicebindings.NewWithValue([]byte("c6e0f1cd4a1d4ba4a59b3bd7fe3ed9a7e8cf2e14bb1ae9ef7a5d4b5f0a73c1d2\n"))
+			})
+
+			it("prefixes it with the algorithm", func() {
+				dependency, _, err := resolve()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(dependency.Checksum).To(Equal("sha256:c6e0f1cd4a1d4ba4a59b3bd7fe3ed9a7e8cf2e14bb1ae9ef7a5d4b5f0a73c1d2"))
+			})
+		})
+
+		context("when the checksum is prefixed with sha256:", func() {
+			it.Before(func() {
+				binding.Entries["checksum"] = servicebindings.NewWithValue([]byte("sha256:c6e0f1cd4a1d4ba4a59b3bd7fe3ed9a7e8cf2e14bb1ae9ef7a5d4b5f0a73c1d2"))
+			})
+
+			it("keeps it as is", func() {
+				dependency, _, err := resolve()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(dependency.Checksum).To(Equal("sha256:c6e0f1cd4a1d4ba4a59b3bd7fe3ed9a7e8cf2e14bb1ae9ef7a5d4b5f0a73c1d2"))
+			})
+		})
+
+		context("when the binding provides a relative path", func() {
+			it.Before(func() {
+				delete(binding.Entries, "uri")
+				binding.Path = "/cnb/buildpacks/mri/bindings/some-binding"
+				binding.Entries["path"] = servicebindings.NewWithValue([]byte("ruby-3.4.8.tgz"))
+			})
+
+			it("resolves it against the binding and expresses it relative to the CNB path", func() {
+				dependency, _, err := resolve()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(dependency.URI).To(Equal("file://bindings/some-binding/ruby-3.4.8.tgz"))
+			})
+		})
+
+		context("when the binding provides an absolute path", func() {
+			it.Before(func() {
+				delete(binding.Entries, "uri")
+				binding.Entries["path"] = servicebindings.NewWithValue([]byte("/cnb/buildpacks/mri/artifacts/ruby-3.4.8.tgz"))
+			})
+
+			it("expresses it relative to the CNB path", func() {
+				dependency, _, err := resolve()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(dependency.URI).To(Equal("file://artifacts/ruby-3.4.8.tgz"))
+			})
+		})
+
+		context("when the binding provides licenses, a cpe and a purl", func() {
+			it.Before(func() {
+				binding.Entries["licenses"] = servicebindings.NewWithValue([]byte("Ruby, BSD-2-Clause,GPL-2.0-only\n"))
+				binding.Entries["cpe"] = servicebindings.NewWithValue([]byte("cpe:2.3:a:ruby-lang:ruby:3.4.8:*:*:*:*:*:*:*"))
+				binding.Entries["purl"] = servicebindings.NewWithValue([]byte("pkg:generic/ruby@3.4.8?arch=amd64"))
+			})
+
+			it("splits the licenses and keeps the identifiers", func() {
+				dependency, _, err := resolve()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(dependency.Licenses).To(Equal([]string{"Ruby", "BSD-2-Clause", "GPL-2.0-only"}))
+				//nolint Ignore SA1019, informed usage of deprecated field
+				Expect(dependency.CPE).To(Equal("cpe:2.3:a:ruby-lang:ruby:3.4.8:*:*:*:*:*:*:*"))
+				Expect(dependency.PURL).To(Equal("pkg:generic/ruby@3.4.8?arch=amd64"))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the bindings cannot be resolved", func() {
+				it.Before(func() {
+					bindingResolver.ResolveCall.Stub = func(string, string, string) ([]servicebindings.Binding, error) {
+						return nil, errors.New("failed to read bindings")
+					}
+				})
+
+				it("returns an error", func() {
+					_, _, err := resolve()
+					Expect(err).To(MatchError("failed to resolve mri-artifact binding: failed to read bindings"))
+				})
+			})
+
+			context("when there is more than one binding", func() {
+				it.Before(func() {
+					bindingResolver.ResolveCall.Stub = func(string, string, string) ([]servicebindings.Binding, error) {
+						return []servicebindings.Binding{binding, {Name: "other-binding"}}, nil
+					}
+				})
+
+				it("returns an error", func() {
+					_, _, err := resolve()
+					Expect(err).To(MatchError("found 2 mri-artifact bindings, expected at most one"))
+				})
+			})
+
+			for _, key := range []string{"version", "checksum"} {
+				context("when the "+key+" is missing", func() {
+					it.Before(func() {
+						delete(binding.Entries, key)
+					})
+
+					it("returns an error", func() {
+						_, _, err := resolve()
+						Expect(err).To(MatchError(`binding "some-binding" is missing the required "` + key + `" entry`))
+					})
+				})
+			}
+
+			context("when the version is invalid", func() {
+				it.Before(func() {
+					binding.Entries["version"] = servicebindings.NewWithValue([]byte("three"))
+				})
+
+				it("returns an error", func() {
+					_, _, err := resolve()
+					Expect(err).To(MatchError(ContainSubstring(`binding "some-binding" has an invalid version "three"`)))
+				})
+			})
+
+			context("when the checksum has no algorithm", func() {
+				it.Before(func() {
+					binding.Entries["checksum"] = servicebindings.NewWithValue([]byte("some-checksum"))
+				})
+
+				it("returns an error", func() {
+					_, _, err := resolve()
+					Expect(err).To(MatchError(`binding "some-binding" has an invalid checksum "some-checksum": expected <algorithm>:<hash>`))
+				})
+			})
+
+			context("when both a uri and a path are provided", func() {
+				it.Before(func() {
+					binding.Entries["path"] = servicebindings.NewWithValue([]byte("ruby-3.4.8.tgz"))
+				})
+
+				it("returns an error", func() {
+					_, _, err := resolve()
+					Expect(err).To(MatchError(`binding "some-binding" must provide either a "uri" or a "path" entry, not both`))
+				})
+			})
+
+			context("when neither a uri nor a path is provided", func() {
+				it.Before(func() {
+					delete(binding.Entries, "uri")
+				})
+
+				it("returns an error", func() {
+					_, _, err := resolve()
+					Expect(err).To(MatchError(`binding "some-binding" must provide either a "uri" or a "path" entry`))
+				})
+			})
+		})
+	})
+}
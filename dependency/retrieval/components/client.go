@@ -0,0 +1,161 @@
+package components
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultTimeout bounds each request, including reading its body, so that
+	// a stalled connection fails the retrieval instead of hanging it.
+	DefaultTimeout = 5 * time.Minute
+
+	DefaultAttempts = 4
+	DefaultBackoff  = time.Second
+
+	DefaultUserAgent = "paketo-buildpacks/mri dependency retrieval"
+)
+
+type mirror struct {
+	origin string
+	mirror string
+}
+
+// Client is the HTTP client the retrieval components fetch feeds and
+// artifacts with. It sets a User-Agent on every request, retries transient
+// failures with an exponential backoff, and rewrites the URLs of origins that
+// have a mirror, so that the retrieval can run against a local server.
+type Client struct {
+	httpClient *http.Client
+	userAgent  string
+	attempts   int
+	backoff    time.Duration
+	mirrors    []mirror
+}
+
+func NewClient() Client {
+	return Client{
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		userAgent:  DefaultUserAgent,
+		attempts:   DefaultAttempts,
+		backoff:    DefaultBackoff,
+	}
+}
+
+// WithTimeout sets the time limit of a single request attempt.
+func (c Client) WithTimeout(timeout time.Duration) Client {
+	httpClient := *c.httpClient
+	httpClient.Timeout = timeout
+	c.httpClient = &httpClient
+	return c
+}
+
+// WithTransport sets the round tripper the requests are sent with.
+func (c Client) WithTransport(transport http.RoundTripper) Client {
+	httpClient := *c.httpClient
+	httpClient.Transport = transport
+	c.httpClient = &httpClient
+	return c
+}
+
+// WithRetries sets the number of attempts made for a request and the delay
+// before the first retry, which doubles with every following retry.
+func (c Client) WithRetries(attempts int, backoff time.Duration) Client {
+	if attempts < 1 {
+		attempts = 1
+	}
+	c.attempts = attempts
+	c.backoff = backoff
+	return c
+}
+
+func (c Client) WithUserAgent(userAgent string) Client {
+	c.userAgent = userAgent
+	return c
+}
+
+// WithMirror fetches the URLs that start with the given origin from the
+// mirror instead, keeping the rest of the URL. When several origins match a
+// URL, the mirror that was added first is used.
+func (c Client) WithMirror(origin, mirrorURL string) Client {
+	c.mirrors = append(append([]mirror{}, c.mirrors...), mirror{
+		origin: origin,
+		mirror: mirrorURL,
+	})
+	return c
+}
+
+// Resolve returns the URL the given URL is fetched from.
+func (c Client) Resolve(uri string) string {
+	for _, m := range c.mirrors {
+		if strings.HasPrefix(uri, m.origin) {
+			return m.mirror + strings.TrimPrefix(uri, m.origin)
+		}
+	}
+
+	return uri
+}
+
+// Get fetches the given URL. Timeouts, refused or dropped connections, 429
+// and 5xx responses are retried; once the attempts run out, the last response or error is
+// returned. The caller checks the status code and closes the body of the
+// response.
+func (c Client) Get(ctx context.Context, uri string) (*http.Response, error) {
+	uri = c.Resolve(uri)
+
+	delay := c.backoff
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("User-Agent", c.userAgent)
+
+		resp, err := c.httpClient.Do(req)
+		if attempt >= c.attempts || !retryable(ctx, resp, err) {
+			return resp, err
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to get %s: %w", uri, ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		// Every *url.Error is a net.Error, so only timeouts and connections
+		// that were refused or dropped are retried. Errors such as a failed
+		// TLS verification or an unknown host would fail again.
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return true
+		}
+
+		return errors.Is(err, syscall.ECONNRESET) ||
+			errors.Is(err, syscall.ECONNREFUSED) ||
+			errors.Is(err, syscall.ECONNABORTED) ||
+			errors.Is(err, io.ErrUnexpectedEOF) ||
+			errors.Is(err, io.EOF)
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
@@ -0,0 +1,235 @@
+package components_test
+
+import (
+	gocontext "context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/mri/dependency/retrieval/components"
+	"github.com/sclevine/spec"
+)
+
+func testClient(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		client    components.Client
+		server    *httptest.Server
+		requests  atomic.Int32
+		userAgent atomic.Value
+	)
+
+	it.Before(func() {
+		requests.Store(0)
+		client = components.NewClient().WithRetries(3, time.Millisecond)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			count := requests.Add(1)
+			userAgent.Store(req.Header.Get("User-Agent"))
+
+			switch req.URL.Path {
+			case "/feed":
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprint(w, "some-feed")
+			case "/flaky":
+				if count < 3 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprint(w, "some-feed")
+			case "/rate-limited":
+				w.WriteHeader(http.StatusTooManyRequests)
+			case "/missing":
+				w.WriteHeader(http.StatusNotFound)
+			case "/stalled":
+				time.Sleep(100 * time.Millisecond)
+				w.WriteHeader(http.StatusOK)
+			default:
+				t.Fatalf("unknown path: %s", req.URL.Path)
+			}
+		}))
+	})
+
+	it.After(func() {
+		server.Close()
+	})
+
+	context("Get", func() {
+		it("fetches the URL with the User-Agent", func() {
+			resp, err := client.Get(t.Context(), server.URL+"/feed")
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.Body.Close()
+
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			body, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(body)).To(Equal("some-feed"))
+			Expect(userAgent.Load()).To(Equal(components.DefaultUserAgent))
+		})
+
+		context("when a User-Agent is given", func() {
+			it.Before(func() {
+				client = client.WithUserAgent("some-agent")
+			})
+
+			it("sends it", func() {
+				resp, err := client.Get(t.Context(), server.URL+"/feed")
+				Expect(err).NotTo(HaveOccurred())
+				resp.Body.Close()
+
+				Expect(userAgent.Load()).To(Equal("some-agent"))
+			})
+		})
+
+		context("when the server fails transiently", func() {
+			it("retries until it succeeds", func() {
+				resp, err := client.Get(t.Context(), server.URL+"/flaky")
+				Expect(err).NotTo(HaveOccurred())
+				resp.Body.Close()
+
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				Expect(requests.Load()).To(Equal(int32(3)))
+			})
+		})
+
+		context("when the attempts run out", func() {
+			it("returns the last response", func() {
+				resp, err := client.Get(t.Context(), server.URL+"/rate-limited")
+				Expect(err).NotTo(HaveOccurred())
+				resp.Body.Close()
+
+				Expect(resp.StatusCode).To(Equal(http.StatusTooManyRequests))
+				Expect(requests.Load()).To(Equal(int32(3)))
+			})
+		})
+
+		context("when the response is a client error", func() {
+			it("does not retry", func() {
+				resp, err := client.Get(t.Context(), server.URL+"/missing")
+				Expect(err).NotTo(HaveOccurred())
+				resp.Body.Close()
+
+				Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+				Expect(requests.Load()).To(Equal(int32(1)))
+			})
+		})
+
+		context("when the URL has a mirror", func() {
+			it.Before(func() {
+				client = client.
+					WithMirror("https://example.com/other", "http://localhost:0").
+					WithMirror("https://example.com", server.URL)
+			})
+
+			it("fetches it from the mirror", func() {
+				Expect(client.Resolve("https://example.com/feed")).To(Equal(server.URL + "/feed"))
+				Expect(client.Resolve("https://example.org/feed")).To(Equal("https://example.org/feed"))
+
+				resp, err := client.Get(t.Context(), "https://example.com/feed")
+				Expect(err).NotTo(HaveOccurred())
+				resp.Body.Close()
+
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the request times out", func() {
+				it.Before(func() {
+					client = client.WithTimeout(10 * time.Millisecond)
+				})
+
+				it("retries and returns the error", func() {
+					_, err := client.Get(t.Context(), server.URL+"/stalled")
+					Expect(err).To(MatchError(ContainSubstring("Client.Timeout exceeded")))
+					Expect(requests.Load()).To(Equal(int32(3)))
+				})
+			})
+
+			context("when the context is cancelled during the backoff", func() {
+				it.Before(func() {
+					client = client.WithRetries(3, time.Hour)
+				})
+
+				it("stops retrying", func() {
+					ctx, cancel := gocontext.WithTimeout(t.Context(), 50*time.Millisecond)
+					defer cancel()
+
+					_, err := client.Get(ctx, server.URL+"/rate-limited")
+					Expect(err).To(MatchError(gocontext.DeadlineExceeded))
+					Expect(requests.Load()).To(Equal(int32(1)))
+				})
+			})
+
+			context("when the host does not exist", func() {
+				var attempts int
+
+				it.Before(func() {
+					client = client.WithTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+						attempts++
+						return nil, &net.DNSError{Err: "no such host", Name: req.URL.Hostname(), IsNotFound: true}
+					}))
+				})
+
+				it("does not retry", func() {
+					_, err := client.Get(t.Context(), "https://example.invalid/feed")
+					Expect(err).To(MatchError(ContainSubstring("no such host")))
+					Expect(attempts).To(Equal(1))
+				})
+			})
+
+			context("when the certificate of the server is not trusted", func() {
+				it.Before(func() {
+					server.Close()
+					server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+						requests.Add(1)
+					}))
+				})
+
+				it("does not retry", func() {
+					_, err := client.Get(t.Context(), server.URL+"/feed")
+					Expect(err).To(MatchError(ContainSubstring("certificate")))
+					Expect(requests.Load()).To(Equal(int32(0)))
+				})
+			})
+
+			context("when the connection is reset", func() {
+				var attempts int
+
+				it.Before(func() {
+					client = client.WithTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+						attempts++
+						return nil, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+					}))
+				})
+
+				it("retries", func() {
+					_, err := client.Get(t.Context(), "https://example.com/feed")
+					Expect(err).To(MatchError(ContainSubstring("connection reset by peer")))
+					Expect(attempts).To(Equal(3))
+				})
+			})
+
+			context("when the URL is invalid", func() {
+				it("does not retry", func() {
+					_, err := client.Get(t.Context(), "not-a-url")
+					Expect(err).To(MatchError(ContainSubstring("unsupported protocol scheme")))
+				})
+			})
+		})
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -1,6 +1,8 @@
 package components
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"net/http"
 
@@ -13,21 +15,29 @@ type RubyBranch struct {
 	EolDate string `yaml:"eol_date"`
 }
 
-type DeprecationDateRetriever struct{}
+type DeprecationDateRetriever struct {
+	client Client
+}
 
-func NewDeprecationDateRetriever() DeprecationDateRetriever {
-	return DeprecationDateRetriever{}
+func NewDeprecationDateRetriever(client Client) DeprecationDateRetriever {
+	return DeprecationDateRetriever{
+		client: client,
+	}
 }
 
 // GetDeprecationDate will look up if a version has an EOL date, and return it
 // if there is one, and return "" if there is not one.
-func (DeprecationDateRetriever) GetDate(feed, version string) (string, error) {
-	resp, err := http.Get(feed) // nolint
+func (d DeprecationDateRetriever) GetDate(ctx context.Context, feed, version string) (string, error) {
+	resp, err := d.client.Get(ctx, feed)
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to query %s: %d", feed, resp.StatusCode)
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		// untested
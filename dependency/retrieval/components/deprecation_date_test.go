@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	"github.com/paketo-buildpacks/mri/dependency/retrieval/components"
@@ -21,7 +22,7 @@ func testGetDeprecationDate(t *testing.T, context spec.G, it spec.S) {
 		)
 
 		it.Before(func() {
-			deprecationDateRetriever = components.NewDeprecationDateRetriever()
+			deprecationDateRetriever = components.NewDeprecationDateRetriever(components.NewClient().WithRetries(components.DefaultAttempts, time.Millisecond))
 			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 				if req.Method == http.MethodHead {
 					http.Error(w, "NotFound", http.StatusNotFound)
@@ -60,14 +61,14 @@ func testGetDeprecationDate(t *testing.T, context spec.G, it spec.S) {
 		})
 
 		it("retrieves deprecation date for version", func() {
-			date, err := deprecationDateRetriever.GetDate(server.URL, "3.2")
+			date, err := deprecationDateRetriever.GetDate(t.Context(), server.URL, "3.2")
 			Expect(err).To(Not(HaveOccurred()))
 			Expect(date).To(Equal("2022-11-01"))
 		})
 
 		context("version has no deprecation date", func() {
 			it("returns empty string", func() {
-				date, err := deprecationDateRetriever.GetDate(server.URL, "3.0")
+				date, err := deprecationDateRetriever.GetDate(t.Context(), server.URL, "3.0")
 				Expect(err).To(Not(HaveOccurred()))
 				Expect(date).To(Equal(""))
 			})
@@ -75,7 +76,7 @@ func testGetDeprecationDate(t *testing.T, context spec.G, it spec.S) {
 
 		context("version does not exist in feed", func() {
 			it("returns empty string", func() {
-				date, err := deprecationDateRetriever.GetDate(server.URL, "1.2.3")
+				date, err := deprecationDateRetriever.GetDate(t.Context(), server.URL, "1.2.3")
 				Expect(err).To(Not(HaveOccurred()))
 				Expect(date).To(Equal(""))
 			})
@@ -84,14 +85,14 @@ func testGetDeprecationDate(t *testing.T, context spec.G, it spec.S) {
 		context("failure cases", func() {
 			context("feed endpoint cannot be retrieved", func() {
 				it("returns an error", func() {
-					_, err := deprecationDateRetriever.GetDate("", "3.0")
+					_, err := deprecationDateRetriever.GetDate(t.Context(), "", "3.0")
 					Expect(err).To(MatchError(ContainSubstring("unsupported protocol scheme")))
 				})
 			})
 
 			context("endpoint body cannot be read", func() {
 				it("returns an error", func() {
-					_, err := deprecationDateRetriever.GetDate(fmt.Sprintf("%s/bad-content", server.URL), "3.0")
+					_, err := deprecationDateRetriever.GetDate(t.Context(), fmt.Sprintf("%s/bad-content", server.URL), "3.0")
 					Expect(err).To(MatchError(ContainSubstring("cannot unmarshal")))
 				})
 			})
@@ -1,12 +1,16 @@
 package fakes
 
-import "sync"
+import (
+	"context"
+	"sync"
+)
 
 type DeprecationDate struct {
 	GetDateCall struct {
 		mutex     sync.Mutex
 		CallCount int
 		Receives  struct {
+			Ctx     context.Context
 			Feed    string
 			Version string
 		}
@@ -14,18 +18,19 @@ type DeprecationDate struct {
 			String string
 			Error  error
 		}
-		Stub func(string, string) (string, error)
+		Stub func(context.Context, string, string) (string, error)
 	}
 }
 
-func (f *DeprecationDate) GetDate(param1 string, param2 string) (string, error) {
+func (f *DeprecationDate) GetDate(param1 context.Context, param2 string, param3 string) (string, error) {
 	f.GetDateCall.mutex.Lock()
 	defer f.GetDateCall.mutex.Unlock()
 	f.GetDateCall.CallCount++
-	f.GetDateCall.Receives.Feed = param1
-	f.GetDateCall.Receives.Version = param2
+	f.GetDateCall.Receives.Ctx = param1
+	f.GetDateCall.Receives.Feed = param2
+	f.GetDateCall.Receives.Version = param3
 	if f.GetDateCall.Stub != nil {
-		return f.GetDateCall.Stub(param1, param2)
+		return f.GetDateCall.Stub(param1, param2, param3)
 	}
 	return f.GetDateCall.Returns.String, f.GetDateCall.Returns.Error
 }
@@ -1,12 +1,16 @@
 package fakes
 
-import "sync"
+import (
+	"context"
+	"sync"
+)
 
 type License struct {
 	LookupLicensesCall struct {
 		mutex     sync.Mutex
 		CallCount int
 		Receives  struct {
+			Ctx            context.Context
 			DependencyName string
 			SourceURL      string
 		}
@@ -15,20 +19,21 @@ type License struct {
 			}
 			Error error
 		}
-		Stub func(string, string) ([]interface {
+		Stub func(context.Context, string, string) ([]interface {
 		}, error)
 	}
 }
 
-func (f *License) LookupLicenses(param1 string, param2 string) ([]interface {
+func (f *License) LookupLicenses(param1 context.Context, param2 string, param3 string) ([]interface {
 }, error) {
 	f.LookupLicensesCall.mutex.Lock()
 	defer f.LookupLicensesCall.mutex.Unlock()
 	f.LookupLicensesCall.CallCount++
-	f.LookupLicensesCall.Receives.DependencyName = param1
-	f.LookupLicensesCall.Receives.SourceURL = param2
+	f.LookupLicensesCall.Receives.Ctx = param1
+	f.LookupLicensesCall.Receives.DependencyName = param2
+	f.LookupLicensesCall.Receives.SourceURL = param3
 	if f.LookupLicensesCall.Stub != nil {
-		return f.LookupLicensesCall.Stub(param1, param2)
+		return f.LookupLicensesCall.Stub(param1, param2, param3)
 	}
 	return f.LookupLicensesCall.Returns.InterfaceSlice, f.LookupLicensesCall.Returns.Error
 }
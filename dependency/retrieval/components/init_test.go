@@ -9,6 +9,7 @@ import (
 
 func TestUnit(t *testing.T) {
 	suite := spec.New("retrieval-components", spec.Report(report.Terminal{}), spec.Parallel())
+	suite("Client", testClient)
 	suite("ReleaseFetcher", testReleaseFetcher)
 	suite("FindNewVersions", testFindNewVersions)
 	suite("MetadataGeneration", testMetadataGeneration)
@@ -16,5 +17,6 @@ func TestUnit(t *testing.T) {
 	suite("LicenseRetrieval", testLicenseRetrieval)
 	suite("PurlGeneration", testPurlGeneration)
 	suite("DependencyValidation", testDependencyValidation)
+	suite("VEXSeeder", testVEXSeeder)
 	suite.Run(t)
 }
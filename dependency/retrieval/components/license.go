@@ -1,6 +1,7 @@
 package components
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,19 +13,25 @@ import (
 	"github.com/paketo-buildpacks/packit/v2/vacation"
 )
 
-type LicenseRetriever struct{}
+type LicenseRetriever struct {
+	client Client
+}
 
-func NewLicenseRetriever() LicenseRetriever {
-	return LicenseRetriever{}
+func NewLicenseRetriever(client Client) LicenseRetriever {
+	return LicenseRetriever{
+		client: client,
+	}
 }
 
-func (LicenseRetriever) LookupLicenses(dependencyName, sourceURL string) ([]interface{}, error) {
+func (l LicenseRetriever) LookupLicenses(ctx context.Context, dependencyName, sourceURL string) ([]interface{}, error) {
 	// getting the dependency artifact from sourceURL
 	url := sourceURL
-	resp, err := http.Get(url) // nolint
+	resp, err := l.client.Get(ctx, url)
 	if err != nil {
 		return []interface{}{}, fmt.Errorf("failed to query url: %w", err)
 	}
+	defer resp.Body.Close()
+
 	if resp.StatusCode != http.StatusOK {
 		return []interface{}{}, fmt.Errorf("failed to query url %s with: status code %d", url, resp.StatusCode)
 	}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	"github.com/paketo-buildpacks/mri/dependency/retrieval/components"
@@ -25,7 +26,7 @@ func testLicenseRetrieval(t *testing.T, context spec.G, it spec.S) {
 
 	it.Before(func() {
 		var err error
-		licenseRetriever = components.NewLicenseRetriever()
+		licenseRetriever = components.NewLicenseRetriever(components.NewClient().WithRetries(components.DefaultAttempts, time.Millisecond))
 
 		// Set up tar files
 		buffer := bytes.NewBuffer(nil)
@@ -92,7 +93,7 @@ func testLicenseRetrieval(t *testing.T, context spec.G, it spec.S) {
 
 	context("given a dependency URL to get the license for", func() {
 		it("gets the artifact and retrieves the license from it", func() {
-			licenses, err := licenseRetriever.LookupLicenses("dependency", fmt.Sprintf("%s/default-dependency-source-url.tgz", server.URL))
+			licenses, err := licenseRetriever.LookupLicenses(t.Context(), "dependency", fmt.Sprintf("%s/default-dependency-source-url.tgz", server.URL))
 			Expect(err).NotTo(HaveOccurred())
 			Expect(licenses).To(Equal([]interface{}{"MIT", "MIT-0"}))
 		})
@@ -100,7 +101,7 @@ func testLicenseRetrieval(t *testing.T, context spec.G, it spec.S) {
 
 	context("the artifact does not contain a license", func() {
 		it("returns an empty slice of licenses and no error", func() {
-			licenses, err := licenseRetriever.LookupLicenses("dependency", fmt.Sprintf("%s/no-license.tgz", server.URL))
+			licenses, err := licenseRetriever.LookupLicenses(t.Context(), "dependency", fmt.Sprintf("%s/no-license.tgz", server.URL))
 			Expect(err).ToNot(HaveOccurred())
 			Expect(licenses).To(Equal([]interface{}{}))
 		})
@@ -109,7 +110,7 @@ func testLicenseRetrieval(t *testing.T, context spec.G, it spec.S) {
 	context("failure cases", func() {
 		context("the request to the source URL fails", func() {
 			it("returns an error and exits non-zero", func() {
-				_, err := licenseRetriever.LookupLicenses("dependency", "non-existent/url")
+				_, err := licenseRetriever.LookupLicenses(t.Context(), "dependency", "non-existent/url")
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(ContainSubstring(`failed to query url: Get "non-existent/url"`)))
 			})
@@ -117,7 +118,7 @@ func testLicenseRetrieval(t *testing.T, context spec.G, it spec.S) {
 
 		context("the status code of the response is not OK", func() {
 			it("returns an error and exits non-zero", func() {
-				_, err := licenseRetriever.LookupLicenses("dependency", fmt.Sprintf("%s/bad-url", server.URL))
+				_, err := licenseRetriever.LookupLicenses(t.Context(), "dependency", fmt.Sprintf("%s/bad-url", server.URL))
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(ContainSubstring(fmt.Sprintf(`failed to query url %s/bad-url with: status code 400`, server.URL))))
 			})
@@ -125,7 +126,7 @@ func testLicenseRetrieval(t *testing.T, context spec.G, it spec.S) {
 
 		context("the artifact cannot be decompressed", func() {
 			it("returns an error and exits non-zero", func() {
-				_, err := licenseRetriever.LookupLicenses("dependency", fmt.Sprintf("%s/non-tar-file-artifact", server.URL))
+				_, err := licenseRetriever.LookupLicenses(t.Context(), "dependency", fmt.Sprintf("%s/non-tar-file-artifact", server.URL))
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(ContainSubstring("failed to decompress source file")))
 			})
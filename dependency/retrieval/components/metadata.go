@@ -1,6 +1,7 @@
 package components
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -24,18 +25,18 @@ type PlatformTarget struct {
 
 //go:generate faux --interface License --output fakes/license.go
 type License interface {
-	LookupLicenses(dependencyName, sourceURL string) ([]interface{}, error)
+	LookupLicenses(ctx context.Context, dependencyName, sourceURL string) ([]interface{}, error)
 }
 
 //go:generate faux --interface DeprecationDate --output fakes/deprecation_date.go
 type DeprecationDate interface {
-	GetDate(feed, version string) (string, error)
+	GetDate(ctx context.Context, feed, version string) (string, error)
 }
 
 // GenerateMetadata will generate Ruby dependency-specific metadata for each given platform target
-func GenerateMetadata(release RubyRelease, platformTargets []PlatformTarget, licenseRetriever License, deprecationDate DeprecationDate) ([]Dependency, error) {
+func GenerateMetadata(ctx context.Context, release RubyRelease, platformTargets []PlatformTarget, licenseRetriever License, deprecationDate DeprecationDate) ([]Dependency, error) {
 	dependencies := []Dependency{}
-	licenses, err := licenseRetriever.LookupLicenses("ruby", release.URL.Gz)
+	licenses, err := licenseRetriever.LookupLicenses(ctx, "ruby", release.URL.Gz)
 	if err != nil {
 		return dependencies, fmt.Errorf("could not get retrieve licenses: %w", err)
 	}
@@ -47,7 +48,7 @@ func GenerateMetadata(release RubyRelease, platformTargets []PlatformTarget, lic
 		srcChecksum = "sha256:" + algorithm
 	}
 
-	date, err := deprecationDate.GetDate("https://raw.githubusercontent.com/ruby/www.ruby-lang.org/master/_data/branches.yml", release.Version)
+	date, err := deprecationDate.GetDate(ctx, "https://raw.githubusercontent.com/ruby/www.ruby-lang.org/master/_data/branches.yml", release.Version)
 	if err != nil {
 		return dependencies, err
 	}
@@ -41,7 +41,7 @@ func testMetadataGeneration(t *testing.T, context spec.G, it spec.S) {
 
 		it("retrieves all upstream releases", func() {
 			time := time.Date(2022, time.Month(11), 01, 00, 00, 00, 00, time.UTC)
-			dependencies, err := components.GenerateMetadata(release, []string{"jammy"}, licenseRetriever, deprecationDateRetriever)
+			dependencies, err := components.GenerateMetadata(t.Context(), release, []string{"jammy"}, licenseRetriever, deprecationDateRetriever)
 			Expect(err).To(Not(HaveOccurred()))
 			Expect(dependencies).To(Equal([]components.Dependency{
 				components.Dependency{
@@ -68,7 +68,7 @@ func testMetadataGeneration(t *testing.T, context spec.G, it spec.S) {
 					licenseRetriever.LookupLicensesCall.Returns.Error = errors.New("failed to lookup licenses")
 				})
 				it("returns an error", func() {
-					_, err := components.GenerateMetadata(release, []string{"jammy"}, licenseRetriever, deprecationDateRetriever)
+					_, err := components.GenerateMetadata(t.Context(), release, []string{"jammy"}, licenseRetriever, deprecationDateRetriever)
 					Expect(err).To(MatchError(ContainSubstring("failed to lookup licenses")))
 				})
 			})
@@ -78,7 +78,7 @@ func testMetadataGeneration(t *testing.T, context spec.G, it spec.S) {
 					deprecationDateRetriever.GetDateCall.Returns.Error = errors.New("failed to get deprecationDate")
 				})
 				it("returns an error", func() {
-					_, err := components.GenerateMetadata(release, []string{"jammy"}, licenseRetriever, deprecationDateRetriever)
+					_, err := components.GenerateMetadata(t.Context(), release, []string{"jammy"}, licenseRetriever, deprecationDateRetriever)
 					Expect(err).To(MatchError(ContainSubstring("failed to get deprecationDate")))
 				})
 			})
@@ -88,7 +88,7 @@ func testMetadataGeneration(t *testing.T, context spec.G, it spec.S) {
 					deprecationDateRetriever.GetDateCall.Returns.String = "bad-time"
 				})
 				it("returns an error", func() {
-					_, err := components.GenerateMetadata(release, []string{"jammy"}, licenseRetriever, deprecationDateRetriever)
+					_, err := components.GenerateMetadata(t.Context(), release, []string{"jammy"}, licenseRetriever, deprecationDateRetriever)
 					Expect(err).To(MatchError(ContainSubstring("invalid EOL date")))
 				})
 			})
@@ -102,7 +102,7 @@ func testMetadataGeneration(t *testing.T, context spec.G, it spec.S) {
 					}
 				})
 				it("returns an error", func() {
-					_, err := components.GenerateMetadata(release, []string{"jammy"}, licenseRetriever, deprecationDateRetriever)
+					_, err := components.GenerateMetadata(t.Context(), release, []string{"jammy"}, licenseRetriever, deprecationDateRetriever)
 					Expect(err).To(MatchError(ContainSubstring("Invalid Semantic Version")))
 				})
 			})
@@ -1,6 +1,7 @@
 package components
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -23,11 +24,13 @@ type RubyRelease struct {
 }
 
 type ReleaseFetcher struct {
+	client       Client
 	releaseIndex string
 }
 
-func NewReleaseFetcher(feed string) ReleaseFetcher {
+func NewReleaseFetcher(client Client, feed string) ReleaseFetcher {
 	return ReleaseFetcher{
+		client:       client,
 		releaseIndex: feed,
 	}
 }
@@ -36,17 +39,18 @@ func NewReleaseFetcher(feed string) ReleaseFetcher {
 // return all available versions in the form of a map, where the key is the
 // version (string) and the value is a struct containing obtained version
 // metadata.
-func (rf ReleaseFetcher) GetUpstreamReleases() (map[string]RubyRelease, error) {
+func (rf ReleaseFetcher) GetUpstreamReleases(ctx context.Context) (map[string]RubyRelease, error) {
 	versions := make(map[string]RubyRelease)
 
-	resp, err := http.Get(rf.releaseIndex) // nolint
+	resp, err := rf.client.Get(ctx, rf.releaseIndex)
 	if err != nil {
 		return versions, err
 	}
+	defer resp.Body.Close()
+
 	if resp.StatusCode != http.StatusOK {
 		return versions, fmt.Errorf("failed to query %s: %d", rf.releaseIndex, resp.StatusCode)
 	}
-	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	"github.com/paketo-buildpacks/mri/dependency/retrieval/components"
@@ -17,10 +18,12 @@ func testReleaseFetcher(t *testing.T, context spec.G, it spec.S) {
 	context("ReleaseFetcher", func() {
 		var (
 			releaseFetcher components.ReleaseFetcher
+			client         components.Client
 			server         *httptest.Server
 		)
 
 		it.Before(func() {
+			client = components.NewClient().WithRetries(components.DefaultAttempts, time.Millisecond)
 			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 				if req.Method == http.MethodHead {
 					http.Error(w, "NotFound", http.StatusNotFound)
@@ -83,10 +86,10 @@ func testReleaseFetcher(t *testing.T, context spec.G, it spec.S) {
 		context("GetUpstreamReleases", func() {
 			context("mock server tests for fetcher parsing logic", func() {
 				it.Before(func() {
-					releaseFetcher = components.NewReleaseFetcher(fmt.Sprintf("%s/releases", server.URL))
+					releaseFetcher = components.NewReleaseFetcher(client, fmt.Sprintf("%s/releases", server.URL))
 				})
 				it("retrieves all upstream releases", func() {
-					releases, err := releaseFetcher.GetUpstreamReleases()
+					releases, err := releaseFetcher.GetUpstreamReleases(t.Context())
 					Expect(err).To(Not(HaveOccurred()))
 					Expect(releases).To(Equal(map[string]components.RubyRelease{
 						"1.2.3": {
@@ -122,30 +125,30 @@ func testReleaseFetcher(t *testing.T, context spec.G, it spec.S) {
 				context("failure cases", func() {
 					context("version feed endpoint cannot be retrieved", func() {
 						it.Before(func() {
-							releaseFetcher = components.NewReleaseFetcher("invalid URL")
+							releaseFetcher = components.NewReleaseFetcher(client, "invalid URL")
 						})
 						it("returns an error", func() {
-							_, err := releaseFetcher.GetUpstreamReleases()
+							_, err := releaseFetcher.GetUpstreamReleases(t.Context())
 							Expect(err).To(MatchError(ContainSubstring("unsupported protocol scheme")))
 						})
 					})
 
 					context("endpoint returns a bad status code", func() {
 						it.Before(func() {
-							releaseFetcher = components.NewReleaseFetcher(fmt.Sprintf("%s/bad-endpoint", server.URL))
+							releaseFetcher = components.NewReleaseFetcher(client, fmt.Sprintf("%s/bad-endpoint", server.URL))
 						})
 						it("returns an error", func() {
-							_, err := releaseFetcher.GetUpstreamReleases()
+							_, err := releaseFetcher.GetUpstreamReleases(t.Context())
 							Expect(err).To(MatchError(ContainSubstring(fmt.Sprintf("failed to query %s/bad-endpoint: 500", server.URL))))
 						})
 					})
 
 					context("the endpoint cannot be YAML parsed", func() {
 						it.Before(func() {
-							releaseFetcher = components.NewReleaseFetcher(fmt.Sprintf("%s/bad-content", server.URL))
+							releaseFetcher = components.NewReleaseFetcher(client, fmt.Sprintf("%s/bad-content", server.URL))
 						})
 						it("returns an error", func() {
-							_, err := releaseFetcher.GetUpstreamReleases()
+							_, err := releaseFetcher.GetUpstreamReleases(t.Context())
 							Expect(err).To(MatchError(ContainSubstring("cannot unmarshal")))
 						})
 					})
@@ -154,10 +157,10 @@ func testReleaseFetcher(t *testing.T, context spec.G, it spec.S) {
 
 			context("real Ruby server test", func() {
 				it.Before(func() {
-					releaseFetcher = components.NewReleaseFetcher("https://raw.githubusercontent.com/ruby/www.ruby-lang.org/master/_data/releases.yml")
+					releaseFetcher = components.NewReleaseFetcher(client, "https://raw.githubusercontent.com/ruby/www.ruby-lang.org/master/_data/releases.yml")
 				})
 				it("retrieves all upstream releases", func() {
-					releases, err := releaseFetcher.GetUpstreamReleases()
+					releases, err := releaseFetcher.GetUpstreamReleases(t.Context())
 					Expect(err).To(Not(HaveOccurred()))
 					Expect(releases).To(Not(BeEmpty()))
 					Expect(releases["3.2.1"]).To(Equal(
@@ -175,30 +178,30 @@ func testReleaseFetcher(t *testing.T, context spec.G, it spec.S) {
 				context("failure cases", func() {
 					context("version feed endpoint cannot be retrieved", func() {
 						it.Before(func() {
-							releaseFetcher = components.NewReleaseFetcher("invalid URL")
+							releaseFetcher = components.NewReleaseFetcher(client, "invalid URL")
 						})
 						it("returns an error", func() {
-							_, err := releaseFetcher.GetUpstreamReleases()
+							_, err := releaseFetcher.GetUpstreamReleases(t.Context())
 							Expect(err).To(MatchError(ContainSubstring("unsupported protocol scheme")))
 						})
 					})
 
 					context("endpoint returns a bad status code", func() {
 						it.Before(func() {
-							releaseFetcher = components.NewReleaseFetcher(fmt.Sprintf("%s/bad-endpoint", server.URL))
+							releaseFetcher = components.NewReleaseFetcher(client, fmt.Sprintf("%s/bad-endpoint", server.URL))
 						})
 						it("returns an error", func() {
-							_, err := releaseFetcher.GetUpstreamReleases()
+							_, err := releaseFetcher.GetUpstreamReleases(t.Context())
 							Expect(err).To(MatchError(ContainSubstring(fmt.Sprintf("failed to query %s/bad-endpoint: 500", server.URL))))
 						})
 					})
 
 					context("the endpoint cannot be YAML parsed", func() {
 						it.Before(func() {
-							releaseFetcher = components.NewReleaseFetcher(fmt.Sprintf("%s/bad-content", server.URL))
+							releaseFetcher = components.NewReleaseFetcher(client, fmt.Sprintf("%s/bad-content", server.URL))
 						})
 						it("returns an error", func() {
-							_, err := releaseFetcher.GetUpstreamReleases()
+							_, err := releaseFetcher.GetUpstreamReleases(t.Context())
 							Expect(err).To(MatchError(ContainSubstring("cannot unmarshal")))
 						})
 					})
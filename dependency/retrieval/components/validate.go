@@ -1,6 +1,7 @@
 package components
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -8,13 +9,19 @@ import (
 	"github.com/paketo-buildpacks/packit/v2/cargo"
 )
 
-func Validate(release RubyRelease) (bool, error) {
-	archiveResponse, err := http.Get(release.URL.Gz)
+// Validate downloads the source tarball of the release and checks it against
+// the upstream checksum.
+func Validate(ctx context.Context, client Client, release RubyRelease) (bool, error) {
+	archiveResponse, err := client.Get(ctx, release.URL.Gz)
 	if err != nil {
 		return false, fmt.Errorf("failed to get %s: %w", release.URL.Gz, err)
 	}
 	defer archiveResponse.Body.Close()
 
+	if archiveResponse.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("failed to get %s: status code %d", release.URL.Gz, archiveResponse.StatusCode)
+	}
+
 	vr := cargo.NewValidatedReader(archiveResponse.Body, release.SHA256.Gz)
 	valid, err := vr.Valid()
 	if err != nil {
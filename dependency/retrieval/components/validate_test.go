@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	"github.com/paketo-buildpacks/mri/dependency/retrieval/components"
@@ -19,10 +20,12 @@ func testDependencyValidation(t *testing.T, context spec.G, it spec.S) {
 	var (
 		Expect = NewWithT(t).Expect
 
+		client components.Client
 		server *httptest.Server
 	)
 	it.Before(func() {
 		var err error
+		client = components.NewClient().WithRetries(components.DefaultAttempts, time.Millisecond)
 
 		// Set up tar files
 		buffer := bytes.NewBuffer(nil)
@@ -66,7 +69,7 @@ func testDependencyValidation(t *testing.T, context spec.G, it spec.S) {
 
 	context("Validate", func() {
 		it("validates the dependency checksum", func() {
-			valid, err := components.Validate(components.RubyRelease{
+			valid, err := components.Validate(t.Context(), client, components.RubyRelease{
 				URL: components.URL{
 					Gz: fmt.Sprintf("%s/file.tgz", server.URL),
 				},
@@ -81,7 +84,7 @@ func testDependencyValidation(t *testing.T, context spec.G, it spec.S) {
 
 		context("the checksums do not match", func() {
 			it("returns an error", func() {
-				valid, err := components.Validate(components.RubyRelease{
+				valid, err := components.Validate(t.Context(), client, components.RubyRelease{
 					URL: components.URL{
 						Gz: fmt.Sprintf("%s/file.tgz", server.URL),
 					},
@@ -98,7 +101,7 @@ func testDependencyValidation(t *testing.T, context spec.G, it spec.S) {
 		context("failure cases", func() {
 			context("fails to get artifact", func() {
 				it("returns an error", func() {
-					_, err := components.Validate(components.RubyRelease{
+					_, err := components.Validate(t.Context(), client, components.RubyRelease{
 						URL: components.URL{
 							Gz: "nonexistent",
 						},
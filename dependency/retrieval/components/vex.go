@@ -0,0 +1,127 @@
+package components
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// RubyCPE matches every version of MRI in the VEX statements seeded from the
+// ruby-lang.org security announcements.
+const RubyCPE = "cpe:2.3:a:ruby-lang:ruby:*:*:*:*:*:*:*:*"
+
+var cveID = regexp.MustCompile(`CVE-\d{4}-\d{4,}`)
+
+type NewsFeed struct {
+	Items []NewsItem `xml:"channel>item"`
+}
+
+type NewsItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+}
+
+type VEXSeeder struct {
+	client Client
+	feed   string
+}
+
+func NewVEXSeeder(client Client, feed string) VEXSeeder {
+	return VEXSeeder{
+		client: client,
+		feed:   feed,
+	}
+}
+
+// Seed appends an in_triage statement to the VEX file at the given path for
+// each CVE announced in the ruby-lang.org news feed that the file does not
+// have a statement about yet, and returns their IDs. The statements are
+// appended as text so that the curated statements and comments in the file
+// are left untouched. A maintainer then decides whether the builds are
+// affected.
+func (s VEXSeeder) Seed(ctx context.Context, path string) ([]string, error) {
+	var existing struct {
+		Statements []struct {
+			ID string `toml:"id"`
+		} `toml:"statements"`
+	}
+	_, err := toml.DecodeFile(path, &existing)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	known := map[string]bool{}
+	for _, statement := range existing.Statements {
+		known[statement.ID] = true
+	}
+
+	resp, err := s.client.Get(ctx, s.feed)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to query %s: %d", s.feed, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var feed NewsFeed
+	err = xml.Unmarshal(body, &feed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.feed, err)
+	}
+
+	sources := map[string]string{}
+	for _, item := range feed.Items {
+		for _, id := range cveID.FindAllString(item.Title+" "+item.Description, -1) {
+			if _, ok := sources[id]; !ok && !known[id] {
+				sources[id] = item.Link
+			}
+		}
+	}
+
+	var added []string
+	for id := range sources {
+		added = append(added, id)
+	}
+	sort.Strings(added)
+
+	if len(added) == 0 {
+		return nil, nil
+	}
+
+	var statements strings.Builder
+	for _, id := range added {
+		fmt.Fprintf(&statements, "\n[[statements]]\n  id = %q\n  cpe = %q\n  state = \"in_triage\"\n", id, RubyCPE)
+		if sources[id] != "" {
+			fmt.Fprintf(&statements, "  source = %q\n", sources[id])
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	_, err = file.WriteString(statements.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return added, nil
+}
@@ -0,0 +1,159 @@
+package components_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/mri/dependency/retrieval/components"
+	"github.com/sclevine/spec"
+)
+
+func testVEXSeeder(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("Seed", func() {
+		var (
+			client components.Client
+			server *httptest.Server
+			path   string
+		)
+
+		it.Before(func() {
+			client = components.NewClient().WithRetries(components.DefaultAttempts, time.Millisecond)
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				switch req.URL.Path {
+				case "/news.rss":
+					w.WriteHeader(http.StatusOK)
+					fmt.Fprintln(w, `<?xml version="1.0" encoding="utf-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Ruby News</title>
+    <item>
+      <title>CVE-2025-0003: ReDoS vulnerability in REXML</title>
+      <link>https://www.ruby-lang.org/en/news/cve-2025-0003/</link>
+      <description>There is a ReDoS vulnerability in REXML.</description>
+    </item>
+    <item>
+      <title>Ruby 3.4.8 Released</title>
+      <link>https://www.ruby-lang.org/en/news/ruby-3-4-8-released/</link>
+      <description>This release includes security fixes for CVE-2025-0001 and CVE-2025-0002.</description>
+    </item>
+  </channel>
+</rss>`)
+				case "/bad-content":
+					w.WriteHeader(http.StatusOK)
+					fmt.Fprintln(w, "<rss><channel>")
+				case "/non-200":
+					w.WriteHeader(http.StatusInternalServerError)
+				default:
+					t.Fatalf("unknown path: %s", req.URL.Path)
+				}
+			}))
+
+			path = filepath.Join(t.TempDir(), "vex.toml")
+			Expect(os.WriteFile(path, []byte(`# Curated statements
+[[statements]]
+  id = "CVE-2025-0001"
+  cpe = "cpe:2.3:a:ruby-lang:ruby:*:*:*:*:*:*:*:*"
+  state = "not_affected"
+  justification = "requires_environment"
+`), 0600)).To(Succeed())
+		})
+
+		it.After(func() {
+			server.Close()
+		})
+
+		it("appends a statement for each new CVE", func() {
+			added, err := components.NewVEXSeeder(client, server.URL+"/news.rss").Seed(t.Context(), path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(added).To(Equal([]string{"CVE-2025-0002", "CVE-2025-0003"}))
+
+			content, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal(`# Curated statements
+[[statements]]
+  id = "CVE-2025-0001"
+  cpe = "cpe:2.3:a:ruby-lang:ruby:*:*:*:*:*:*:*:*"
+  state = "not_affected"
+  justification = "requires_environment"
+
+[[statements]]
+  id = "CVE-2025-0002"
+  cpe = "cpe:2.3:a:ruby-lang:ruby:*:*:*:*:*:*:*:*"
+  state = "in_triage"
+  source = "https://www.ruby-lang.org/en/news/ruby-3-4-8-released/"
+
+[[statements]]
+  id = "CVE-2025-0003"
+  cpe = "cpe:2.3:a:ruby-lang:ruby:*:*:*:*:*:*:*:*"
+  state = "in_triage"
+  source = "https://www.ruby-lang.org/en/news/cve-2025-0003/"
+`))
+		})
+
+		context("when every CVE already has a statement", func() {
+			it("leaves the file untouched", func() {
+				_, err := components.NewVEXSeeder(client, server.URL+"/news.rss").Seed(t.Context(), path)
+				Expect(err).NotTo(HaveOccurred())
+
+				before, err := os.ReadFile(path)
+				Expect(err).NotTo(HaveOccurred())
+
+				added, err := components.NewVEXSeeder(client, server.URL+"/news.rss").Seed(t.Context(), path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(added).To(BeEmpty())
+
+				after, err := os.ReadFile(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(after).To(Equal(before))
+			})
+		})
+
+		context("when the file does not exist", func() {
+			it.Before(func() {
+				Expect(os.Remove(path)).To(Succeed())
+			})
+
+			it("creates it", func() {
+				added, err := components.NewVEXSeeder(client, server.URL+"/news.rss").Seed(t.Context(), path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(added).To(HaveLen(3))
+				Expect(path).To(BeARegularFile())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the file is malformed", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(path, []byte("%%%"), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := components.NewVEXSeeder(client, server.URL+"/news.rss").Seed(t.Context(), path)
+					Expect(err).To(MatchError(ContainSubstring("failed to parse")))
+				})
+			})
+
+			context("when the feed cannot be parsed", func() {
+				it("returns an error", func() {
+					_, err := components.NewVEXSeeder(client, server.URL+"/bad-content").Seed(t.Context(), path)
+					Expect(err).To(MatchError(ContainSubstring("failed to parse")))
+				})
+			})
+
+			context("when the feed returns a non-200 status", func() {
+				it("returns an error", func() {
+					_, err := components.NewVEXSeeder(client, server.URL+"/non-200").Seed(t.Context(), path)
+					Expect(err).To(MatchError(ContainSubstring("500")))
+				})
+			})
+		})
+	})
+}
@@ -1,16 +1,24 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/paketo-buildpacks/mri/dependency/retrieval/components"
 	"github.com/paketo-buildpacks/packit/v2/cargo"
 )
 
-const versionFeed = "https://raw.githubusercontent.com/ruby/www.ruby-lang.org/master/_data/releases.yml"
+const (
+	versionFeed  = "https://raw.githubusercontent.com/ruby/www.ruby-lang.org/master/_data/releases.yml"
+	securityFeed = "https://www.ruby-lang.org/en/feeds/news.rss"
+)
 
 type StackTarget struct {
 	stacks []string
@@ -46,34 +54,70 @@ func getSupportedPlatformTargets() []components.PlatformTarget {
 	return platformTargets
 }
 
+type Config struct {
+	BuildpackTomlPath string
+	Output            string
+	VEXPath           string
+}
+
 // Retrieval for gets newer upstream versions of the ruby dependency from upstream
 // and returns a metadata.json for new versions within buildpack.toml constraints
 func main() {
-	var flags struct {
-		buildpackTomlPath string
-		output            string
-	}
-
-	flag.StringVar(&flags.buildpackTomlPath, "buildpackTomlPath", "", "the path to the buildpack.toml file")
-	flag.StringVar(&flags.output, "output", "", "path to file into which an output metadata JSON will be written")
+	var (
+		config   Config
+		timeout  time.Duration
+		attempts int
+		mirrors  []string
+	)
+
+	flag.StringVar(&config.BuildpackTomlPath, "buildpackTomlPath", "", "the path to the buildpack.toml file")
+	flag.StringVar(&config.Output, "output", "", "path to file into which an output metadata JSON will be written")
+	flag.StringVar(&config.VEXPath, "vexPath", "", "optional path to the vex.toml file to seed with newly announced CVEs")
+	flag.DurationVar(&timeout, "timeout", components.DefaultTimeout, "time limit of each HTTP request")
+	flag.IntVar(&attempts, "attempts", components.DefaultAttempts, "number of attempts made for each HTTP request")
+	flag.Func("mirror", "fetch URLs starting with an origin from a mirror instead, as <origin>=<mirror> (repeatable)", func(value string) error {
+		if !strings.Contains(value, "=") {
+			return fmt.Errorf("expected <origin>=<mirror>, got %q", value)
+		}
+		mirrors = append(mirrors, value)
+		return nil
+	})
 	flag.Parse()
-	if flags.buildpackTomlPath == "" {
+	if config.BuildpackTomlPath == "" {
 		fail(errors.New(`missing required input "buildpackTomlPath"`))
 	}
-	if flags.output == "" {
+	if config.Output == "" {
 		fail(errors.New(`missing required input "output"`))
 	}
 
-	buildpackConfig, err := cargo.NewBuildpackParser().Parse(flags.buildpackTomlPath)
+	client := components.NewClient().
+		WithTimeout(timeout).
+		WithRetries(attempts, components.DefaultBackoff)
+	for _, m := range mirrors {
+		origin, mirror, _ := strings.Cut(m, "=")
+		client = client.WithMirror(origin, mirror)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := retrieve(ctx, client, config)
 	if err != nil {
 		fail(err)
 	}
+}
+
+func retrieve(ctx context.Context, client components.Client, config Config) error {
+	buildpackConfig, err := cargo.NewBuildpackParser().Parse(config.BuildpackTomlPath)
+	if err != nil {
+		return err
+	}
 
 	// Map where the key is a version and the value is a struct with version metadata
-	releaseFetcher := components.NewReleaseFetcher(versionFeed)
-	upstreamVersionMap, err := releaseFetcher.GetUpstreamReleases()
+	releaseFetcher := components.NewReleaseFetcher(client, versionFeed)
+	upstreamVersionMap, err := releaseFetcher.GetUpstreamReleases(ctx)
 	if err != nil {
-		fail(err)
+		return err
 	}
 
 	upstreamVersions := []string{}
@@ -84,7 +128,7 @@ func main() {
 	// Filter down the upstream versions against the buildpack.toml file
 	newVersions, err := components.FindNewVersions("ruby", buildpackConfig, upstreamVersions)
 	if err != nil {
-		fail(err)
+		return err
 	}
 
 	fmt.Printf("New versions: %v\n", newVersions)
@@ -93,27 +137,38 @@ func main() {
 	for _, version := range newVersions {
 		// Validate the dependency checksum matches the upstream dependency
 		// checkdum before we add it to the list of dependencies
-		valid, err := components.Validate(upstreamVersionMap[version])
+		valid, err := components.Validate(ctx, client, upstreamVersionMap[version])
 		if err != nil {
-			fail(err)
+			return err
 		}
 		if !valid {
-			fail(fmt.Errorf("failed to validate dependency checksum for version %s", version))
+			return fmt.Errorf("failed to validate dependency checksum for version %s", version)
 		}
 
-		entries, err := components.GenerateMetadata(upstreamVersionMap[version], platformTargets, components.NewLicenseRetriever(), components.NewDeprecationDateRetriever())
+		entries, err := components.GenerateMetadata(ctx, upstreamVersionMap[version], platformTargets, components.NewLicenseRetriever(client), components.NewDeprecationDateRetriever(client))
 		if err != nil {
-			fail(err)
+			return err
 		}
 		dependencies = append(dependencies, entries...)
 	}
 
-	err = components.WriteOutput(flags.output, dependencies)
+	err = components.WriteOutput(config.Output, dependencies)
 	if err != nil {
-		fail(err)
+		return err
+	}
+
+	fmt.Printf("Succeeded! Metadata written to %s\n", config.Output)
+
+	if config.VEXPath != "" {
+		added, err := components.NewVEXSeeder(client, securityFeed).Seed(ctx, config.VEXPath)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("New VEX statements: %v\n", added)
 	}
 
-	fmt.Printf("Succeeded! Metadata written to %s\n", flags.output)
+	return nil
 }
 
 func fail(err error) {
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	gocontext "context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/mri/dependency/retrieval/components"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestRetrieve(t *testing.T) {
+	spec.Run(t, "Retrieve", testRetrieve, spec.Report(report.Terminal{}))
+}
+
+func testRetrieve(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		server *httptest.Server
+		client components.Client
+		config Config
+	)
+
+	it.Before(func() {
+		buffer := bytes.NewBuffer(nil)
+		tw := tar.NewWriter(buffer)
+		Expect(tw.WriteHeader(&tar.Header{Name: "ruby-3.4.8", Mode: 0755, Typeflag: tar.TypeDir})).To(Succeed())
+
+		license, err := os.ReadFile(filepath.Join("components", "testdata", "LICENSE"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tw.WriteHeader(&tar.Header{Name: "ruby-3.4.8/LICENSE", Mode: 0644, Size: int64(len(license))})).To(Succeed())
+		_, err = tw.Write(license)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tw.Close()).To(Succeed())
+
+		tarball := buffer.Bytes()
+		sum := sha256.Sum256(tarball)
+		checksum := hex.EncodeToString(sum[:])
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case "/ruby/www.ruby-lang.org/master/_data/releases.yml":
+				fmt.Fprintf(w, `
+- version: 3.4.8
+  url:
+    gz: https://cache.ruby-lang.org/pub/ruby/3.4/ruby-3.4.8.tar.gz
+  sha256:
+    gz: %s
+
+- version: 3.4.7
+  url:
+    gz: https://cache.ruby-lang.org/pub/ruby/3.4/ruby-3.4.7.tar.gz
+  sha256:
+    gz: some-sha
+`, checksum)
+			case "/ruby/www.ruby-lang.org/master/_data/branches.yml":
+				fmt.Fprint(w, `
+- name: 3.4
+  date: 2024-12-25
+  eol_date: 2028-03-31
+`)
+			case "/pub/ruby/3.4/ruby-3.4.8.tar.gz":
+				_, _ = w.Write(tarball)
+			case "/en/feeds/news.rss":
+				fmt.Fprint(w, `<rss version="2.0"><channel>
+  <item>
+    <title>CVE-2025-0001: Some vulnerability</title>
+    <link>https://www.ruby-lang.org/en/news/cve-2025-0001/</link>
+  </item>
+</channel></rss>`)
+			default:
+				t.Fatalf("unknown path: %s", req.URL.Path)
+			}
+		}))
+
+		client = components.NewClient().
+			WithRetries(1, time.Millisecond).
+			WithMirror("https://raw.githubusercontent.com", server.URL).
+			WithMirror("https://cache.ruby-lang.org", server.URL).
+			WithMirror("https://www.ruby-lang.org", server.URL)
+
+		dir := t.TempDir()
+		config = Config{
+			BuildpackTomlPath: filepath.Join(dir, "buildpack.toml"),
+			Output:            filepath.Join(dir, "metadata.json"),
+			VEXPath:           filepath.Join(dir, "vex.toml"),
+		}
+
+		Expect(os.WriteFile(config.BuildpackTomlPath, []byte(`
+api = "0.8"
+
+[buildpack]
+  id = "paketo-buildpacks/mri"
+
+[metadata]
+  [[metadata.dependencies]]
+    id = "ruby"
+    version = "3.4.7"
+
+  [[metadata.dependency-constraints]]
+    constraint = "3.4.*"
+    id = "ruby"
+    patches = 2
+`), 0600)).To(Succeed())
+	})
+
+	it.After(func() {
+		server.Close()
+	})
+
+	it("writes the metadata of the new versions and seeds the VEX file", func() {
+		Expect(retrieve(t.Context(), client, config)).To(Succeed())
+
+		content, err := os.ReadFile(config.Output)
+		Expect(err).NotTo(HaveOccurred())
+
+		var dependencies []components.Dependency
+		Expect(json.Unmarshal(content, &dependencies)).To(Succeed())
+		Expect(dependencies).To(HaveLen(len(getSupportedPlatformTargets())))
+		for _, dependency := range dependencies {
+			Expect(dependency.Version).To(Equal("3.4.8"))
+			Expect(dependency.Source).To(Equal("https://cache.ruby-lang.org/pub/ruby/3.4/ruby-3.4.8.tar.gz"))
+			Expect(dependency.Licenses).To(ContainElement("MIT"))
+			Expect(dependency.DeprecationDate.Format(time.DateOnly)).To(Equal("2028-03-31"))
+		}
+
+		vex, err := os.ReadFile(config.VEXPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(vex)).To(ContainSubstring(`id = "CVE-2025-0001"`))
+	})
+
+	context("when the context is cancelled", func() {
+		it("stops the retrieval", func() {
+			ctx, cancel := gocontext.WithCancel(t.Context())
+			cancel()
+
+			err := retrieve(ctx, client, config)
+			Expect(err).To(MatchError(ContainSubstring("context canceled")))
+			Expect(config.Output).NotTo(BeAnExistingFile())
+		})
+	})
+}
@@ -0,0 +1,262 @@
+package mri
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/paketo-buildpacks/packit/v2/cargo"
+)
+
+// Version aliases that can be given in place of a semver constraint.
+const (
+	LatestAlias          = "latest"
+	StableAlias          = "stable"
+	OldestSupportedAlias = "oldest-supported"
+	DefaultAlias         = "default"
+)
+
+// DependencyCatalog describes the MRI dependencies that are listed in the
+// buildpack.toml and can be installed on the current stack, OS and
+// architecture.
+type DependencyCatalog struct {
+	Stack        string
+	OS           string
+	Arch         string
+	Dependencies []cargo.ConfigMetadataDependency
+
+	defaultVersion string
+	unsupported    []cargo.ConfigMetadataDependency
+}
+
+// NewDependencyCatalog parses the buildpack.toml at the given path and
+// returns the dependencies with the given id that support the stack. The
+// target platform is taken from $CNB_TARGET_OS and $CNB_TARGET_ARCH when set,
+// falling back to the platform of the running binary, as postal does.
+func NewDependencyCatalog(path, id, stack string) (DependencyCatalog, error) {
+	config, err := cargo.NewBuildpackParser().Parse(path)
+	if err != nil {
+		return DependencyCatalog{}, err
+	}
+
+	catalog := DependencyCatalog{
+		Stack:          stack,
+		OS:             os.Getenv("CNB_TARGET_OS"),
+		Arch:           os.Getenv("CNB_TARGET_ARCH"),
+		defaultVersion: config.Metadata.DefaultVersions[id],
+	}
+
+	if catalog.OS == "" {
+		catalog.OS = runtime.GOOS
+	}
+
+	if catalog.Arch == "" {
+		catalog.Arch = runtime.GOARCH
+	}
+
+	for _, dependency := range config.Metadata.Dependencies {
+		if dependency.ID != id {
+			continue
+		}
+
+		if _, err := semver.NewVersion(dependency.Version); err != nil {
+			return DependencyCatalog{}, fmt.Errorf("invalid version %q for dependency %q: %w", dependency.Version, id, err)
+		}
+
+		if !catalog.supports(dependency) {
+			catalog.unsupported = append(catalog.unsupported, dependency)
+			continue
+		}
+
+		catalog.Dependencies = append(catalog.Dependencies, dependency)
+	}
+
+	sortByVersion(catalog.Dependencies)
+	sortByVersion(catalog.unsupported)
+
+	return catalog, nil
+}
+
+// IsVersionAlias reports whether the given version is one of the supported
+// aliases rather than a semver constraint.
+func IsVersionAlias(version string) bool {
+	switch version {
+	case LatestAlias, StableAlias, OldestSupportedAlias, DefaultAlias:
+		return true
+	}
+
+	return false
+}
+
+// ResolveAlias returns the concrete version that the given alias refers to:
+//   - latest: the newest available version
+//   - stable: the newest version on a branch that has not reached its
+//     deprecation date
+//   - oldest-supported: the oldest version that has not reached its
+//     deprecation date
+//   - default: the newest version matching metadata.default-versions
+func (c DependencyCatalog) ResolveAlias(alias string, now time.Time) (string, error) {
+	var candidates []cargo.ConfigMetadataDependency
+
+	switch alias {
+	case LatestAlias:
+		candidates = c.Dependencies
+
+	case StableAlias:
+		deprecatedBranches := map[string]bool{}
+		for _, dependency := range c.Dependencies {
+			if isDeprecated(dependency, now) {
+				deprecatedBranches[branchOf(dependency.Version)] = true
+			}
+		}
+
+		for _, dependency := range c.Dependencies {
+			if !deprecatedBranches[branchOf(dependency.Version)] {
+				candidates = append(candidates, dependency)
+			}
+		}
+
+	case OldestSupportedAlias:
+		for _, dependency := range c.Dependencies {
+			if !isDeprecated(dependency, now) {
+				return dependency.Version, nil
+			}
+		}
+
+	case DefaultAlias:
+		if c.defaultVersion == "" {
+			candidates = c.Dependencies
+			break
+		}
+
+		constraint, err := semver.NewConstraint(c.defaultVersion)
+		if err != nil {
+			return "", fmt.Errorf("invalid default version %q: %w", c.defaultVersion, err)
+		}
+
+		for _, dependency := range c.Dependencies {
+			if constraint.Check(semver.MustParse(dependency.Version)) {
+				candidates = append(candidates, dependency)
+			}
+		}
+
+	default:
+		return "", fmt.Errorf("unknown version alias %q", alias)
+	}
+
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no MRI version matches the %q alias on stack %q (%s/%s)", alias, c.Stack, c.OS, c.Arch)
+	}
+
+	return candidates[len(candidates)-1].Version, nil
+}
+
+// ResolveSource returns the newest dependency matching the given constraint
+// that lists the source it was compiled from, regardless of the stack and
+// platform it was built for. An empty or default constraint refers to
+// metadata.default-versions.
+func (c DependencyCatalog) ResolveSource(constraint string) (cargo.ConfigMetadataDependency, error) {
+	if constraint == "" || constraint == DefaultAlias {
+		constraint = c.defaultVersion
+	}
+
+	if constraint == "" {
+		constraint = "*"
+	}
+
+	versionConstraint, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return cargo.ConfigMetadataDependency{}, err
+	}
+
+	var candidates []cargo.ConfigMetadataDependency
+	for _, dependency := range append(append([]cargo.ConfigMetadataDependency{}, c.Dependencies...), c.unsupported...) {
+		if dependency.Source != "" && versionConstraint.Check(semver.MustParse(dependency.Version)) {
+			candidates = append(candidates, dependency)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return cargo.ConfigMetadataDependency{}, fmt.Errorf("no MRI source matches the version constraint %q", constraint)
+	}
+
+	sortByVersion(candidates)
+
+	return candidates[len(candidates)-1], nil
+}
+
+// WithSources returns the catalog extended with the dependencies built for
+// other stacks or platforms that list the source they were compiled from, so
+// that aliases and intersections also consider the versions ResolveSource
+// can compile.
+func (c DependencyCatalog) WithSources() DependencyCatalog {
+	dependencies := append([]cargo.ConfigMetadataDependency{}, c.Dependencies...)
+	var unsupported []cargo.ConfigMetadataDependency
+	for _, dependency := range c.unsupported {
+		if dependency.Source != "" {
+			dependencies = append(dependencies, dependency)
+		} else {
+			unsupported = append(unsupported, dependency)
+		}
+	}
+	sortByVersion(dependencies)
+
+	c.Dependencies = dependencies
+	c.unsupported = unsupported
+
+	return c
+}
+
+func (c DependencyCatalog) supports(dependency cargo.ConfigMetadataDependency) bool {
+	var stackMatch bool
+	for _, stack := range dependency.Stacks {
+		if stack == c.Stack || stack == "*" {
+			stackMatch = true
+			break
+		}
+	}
+
+	if !stackMatch {
+		return false
+	}
+
+	// Dependencies that do not declare a platform are installable everywhere.
+	if dependency.OS == "" && dependency.Arch == "" {
+		return true
+	}
+
+	return dependency.OS == c.OS && dependency.Arch == c.Arch
+}
+
+// targetArch returns the given architecture, falling back to $CNB_TARGET_ARCH
+// and then to the architecture of the running binary, as postal does.
+func targetArch(arch string) string {
+	if arch == "" {
+		arch = os.Getenv("CNB_TARGET_ARCH")
+	}
+
+	if arch == "" {
+		arch = runtime.GOARCH
+	}
+
+	return arch
+}
+
+func sortByVersion(dependencies []cargo.ConfigMetadataDependency) {
+	sort.SliceStable(dependencies, func(i, j int) bool {
+		return semver.MustParse(dependencies[i].Version).LessThan(semver.MustParse(dependencies[j].Version))
+	})
+}
+
+func isDeprecated(dependency cargo.ConfigMetadataDependency, now time.Time) bool {
+	return dependency.DeprecationDate != nil && dependency.DeprecationDate.Before(now)
+}
+
+// branchOf returns the major.minor release branch of a version.
+func branchOf(version string) string {
+	v := semver.MustParse(version)
+	return fmt.Sprintf("%d.%d", v.Major(), v.Minor())
+}
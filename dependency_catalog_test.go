@@ -0,0 +1,312 @@
+package mri_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/paketo-buildpacks/mri"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testDependencyCatalog(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		cnbDir string
+		path   string
+		now    time.Time
+	)
+
+	it.Before(func() {
+		var err error
+		cnbDir, err = os.MkdirTemp("", "cnb")
+		Expect(err).NotTo(HaveOccurred())
+
+		path = filepath.Join(cnbDir, "buildpack.toml")
+		Expect(os.WriteFile(path, []byte(`api = "0.7"
+
+[metadata]
+  [metadata.default-versions]
+    ruby = "3.3.*"
+
+  [[metadata.dependencies]]
+    id = "ruby"
+    version = "3.2.9"
+    deprecation_date = 2026-03-31T00:00:00Z
+    stacks = ["some-stack"]
+    os = "linux"
+    arch = "amd64"
+
+  [[metadata.dependencies]]
+    id = "ruby"
+    version = "3.3.10"
+    deprecation_date = 2027-03-31T00:00:00Z
+    stacks = ["some-stack"]
+    os = "linux"
+    arch = "amd64"
+
+  [[metadata.dependencies]]
+    id = "ruby"
+    version = "3.3.11"
+    deprecation_date = 2027-03-31T00:00:00Z
+    stacks = ["some-stack"]
+    os = "linux"
+    arch = "amd64"
+
+  [[metadata.dependencies]]
+    id = "ruby"
+    version = "3.4.8"
+    stacks = ["some-stack"]
+    os = "linux"
+    arch = "amd64"
+
+  [[metadata.dependencies]]
+    id = "ruby"
+    version = "4.0.1"
+    stacks = ["some-stack"]
+    os = "linux"
+    arch = "amd64"
+
+  [[metadata.dependencies]]
+    id = "ruby"
+    version = "4.0.1"
+    deprecation_date = 2020-01-01T00:00:00Z
+    stacks = ["some-stack"]
+    os = "linux"
+    arch = "amd64"
+
+  [[metadata.dependencies]]
+    id = "ruby"
+    version = "4.1.0"
+    stacks = ["some-stack"]
+    os = "linux"
+    arch = "arm64"
+
+  [[metadata.dependencies]]
+    id = "ruby"
+    version = "4.2.0"
+    stacks = ["other-stack"]
+    os = "linux"
+    arch = "amd64"
+
+  [[metadata.dependencies]]
+    id = "other"
+    version = "9.9.9"
+    stacks = ["*"]
+`), 0600)).To(Succeed())
+
+		t.Setenv("CNB_TARGET_OS", "linux")
+		t.Setenv("CNB_TARGET_ARCH", "amd64")
+
+		now = time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC)
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(cnbDir)).To(Succeed())
+	})
+
+	context("NewDependencyCatalog", func() {
+		it("lists the dependencies for the stack and platform in version order", func() {
+			catalog, err := mri.NewDependencyCatalog(path, "ruby", "some-stack")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(catalog.Stack).To(Equal("some-stack"))
+			Expect(catalog.OS).To(Equal("linux"))
+			Expect(catalog.Arch).To(Equal("amd64"))
+
+			var versions []string
+			for _, dependency := range catalog.Dependencies {
+				versions = append(versions, dependency.Version)
+			}
+			Expect(versions).To(Equal([]string{"3.2.9", "3.3.10", "3.3.11", "3.4.8", "4.0.1", "4.0.1"}))
+		})
+
+		context("failure cases", func() {
+			context("when the buildpack.toml cannot be parsed", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(path, []byte("%%%"), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := mri.NewDependencyCatalog(path, "ruby", "some-stack")
+					Expect(err).To(MatchError(ContainSubstring("expected")))
+				})
+			})
+
+			context("when a dependency version is not semver", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(path, []byte(`[[metadata.dependencies]]
+  id = "ruby"
+  version = "not-a-version"
+  stacks = ["some-stack"]
+`), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := mri.NewDependencyCatalog(path, "ruby", "some-stack")
+					Expect(err).To(MatchError(ContainSubstring(`invalid version "not-a-version" for dependency "ruby"`)))
+				})
+			})
+		})
+	})
+
+	context("IsVersionAlias", func() {
+		it("recognizes the supported aliases", func() {
+			Expect(mri.IsVersionAlias("latest")).To(BeTrue())
+			Expect(mri.IsVersionAlias("stable")).To(BeTrue())
+			Expect(mri.IsVersionAlias("oldest-supported")).To(BeTrue())
+			Expect(mri.IsVersionAlias("default")).To(BeTrue())
+			Expect(mri.IsVersionAlias("3.3.*")).To(BeFalse())
+		})
+	})
+
+	context("ResolveAlias", func() {
+		var catalog mri.DependencyCatalog
+
+		it.Before(func() {
+			var err error
+			catalog, err = mri.NewDependencyCatalog(path, "ruby", "some-stack")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it("resolves latest to the newest version", func() {
+			version, err := catalog.ResolveAlias("latest", now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal("4.0.1"))
+		})
+
+		it("resolves stable to the newest version on a branch that is not deprecated", func() {
+			version, err := catalog.ResolveAlias("stable", now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal("3.4.8"))
+		})
+
+		it("resolves oldest-supported to the oldest version that is not deprecated", func() {
+			version, err := catalog.ResolveAlias("oldest-supported", now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal("3.3.10"))
+		})
+
+		it("resolves default to the newest version matching the default constraint", func() {
+			version, err := catalog.ResolveAlias("default", now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal("3.3.11"))
+		})
+
+		context("failure cases", func() {
+			context("when the alias is unknown", func() {
+				it("returns an error", func() {
+					_, err := catalog.ResolveAlias("newest", now)
+					Expect(err).To(MatchError(`unknown version alias "newest"`))
+				})
+			})
+
+			context("when no version matches the alias", func() {
+				it.Before(func() {
+					var err error
+					catalog, err = mri.NewDependencyCatalog(path, "ruby", "missing-stack")
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				it("returns an error", func() {
+					_, err := catalog.ResolveAlias("latest", now)
+					Expect(err).To(MatchError(`no MRI version matches the "latest" alias on stack "missing-stack" (linux/amd64)`))
+				})
+			})
+		})
+	})
+
+	context("ResolveSource", func() {
+		var catalog mri.DependencyCatalog
+
+		it.Before(func() {
+			Expect(os.WriteFile(path, []byte(`[metadata]
+  [metadata.default-versions]
+    ruby = "3.3.*"
+
+  [[metadata.dependencies]]
+    id = "ruby"
+    version = "3.3.11"
+    source = "https://cache.ruby-lang.org/pub/ruby/3.3/ruby-3.3.11.tar.gz"
+    source-checksum = "sha256:some-3.3-checksum"
+    stacks = ["io.buildpacks.stacks.jammy"]
+
+  [[metadata.dependencies]]
+    id = "ruby"
+    version = "3.4.8"
+    source = "https://cache.ruby-lang.org/pub/ruby/3.4/ruby-3.4.8.tar.gz"
+    source-checksum = "sha256:some-3.4-checksum"
+    stacks = ["io.buildpacks.stacks.noble"]
+    os = "linux"
+    arch = "arm64"
+
+  [[metadata.dependencies]]
+    id = "ruby"
+    version = "3.4.9"
+    stacks = ["io.buildpacks.stacks.noble"]
+`), 0600)).To(Succeed())
+
+			var err error
+			catalog, err = mri.NewDependencyCatalog(path, "ruby", "some-custom-stack")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it("returns the newest matching dependency with a source on any stack", func() {
+			dependency, err := catalog.ResolveSource("3.*")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dependency.Version).To(Equal("3.4.8"))
+			Expect(dependency.Source).To(Equal("https://cache.ruby-lang.org/pub/ruby/3.4/ruby-3.4.8.tar.gz"))
+			Expect(dependency.SourceChecksum).To(Equal("sha256:some-3.4-checksum"))
+		})
+
+		it("resolves the default version", func() {
+			dependency, err := catalog.ResolveSource("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dependency.Version).To(Equal("3.3.11"))
+		})
+
+		context("failure cases", func() {
+			context("when no source matches", func() {
+				it("returns an error", func() {
+					_, err := catalog.ResolveSource("4.*")
+					Expect(err).To(MatchError(`no MRI source matches the version constraint "4.*"`))
+				})
+			})
+
+			context("when the constraint is invalid", func() {
+				it("returns an error", func() {
+					_, err := catalog.ResolveSource("not a constraint")
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		context("WithSources", func() {
+			it("resolves aliases against the dependencies with a source", func() {
+				_, err := catalog.ResolveAlias(mri.LatestAlias, time.Now())
+				Expect(err).To(MatchError(ContainSubstring(`no MRI version matches the "latest" alias`)))
+
+				version, err := catalog.WithSources().ResolveAlias(mri.LatestAlias, time.Now())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(Equal("3.4.8"))
+
+				version, err = catalog.WithSources().ResolveAlias(mri.DefaultAlias, time.Now())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(Equal("3.3.11"))
+			})
+
+			it("intersects requirements against the dependencies with a source", func() {
+				version, err := catalog.WithSources().Intersect([]mri.VersionRequirement{
+					{Source: "BP_MRI_VERSION", Constraint: "3.*"},
+					{Source: "Gemfile.lock", Constraint: "< 3.4"},
+				}, time.Now())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(Equal("3.3.11"))
+			})
+		})
+	})
+}
@@ -17,11 +17,19 @@ type BuildPlanMetadata struct {
 	Version       string `toml:"version"`
 }
 
-func Detect(buildpackYMLParser VersionParser) packit.DetectFunc {
+func Detect(
+	buildpackYMLParser,
+	rubyVersionParser,
+	gemfileParser,
+	gemfileLockParser,
+	toolVersionsParser,
+	miseTOMLParser,
+	gemspecParser VersionParser,
+) packit.DetectFunc {
 	return func(context packit.DetectContext) (packit.DetectResult, error) {
 		var requirements []packit.BuildPlanRequirement
 
-		// If versions are provided via BP_MRI_VERSION and/or buildpack.yml:
+		// If versions are provided via BP_MRI_VERSION, buildpack.yml and/or any of the app's version files:
 		// Detection will pass all versions as build plan requirements.
 		// The build phase is responsible for using a priority mapping to select correct version.
 		// This will allow for greater clarity in log output if the user has set version through multiple configurations.
@@ -39,20 +47,54 @@ func Detect(buildpackYMLParser VersionParser) packit.DetectFunc {
 			})
 		}
 
-		// check buildpack.yml
-		version, err := buildpackYMLParser.ParseVersion(filepath.Join(context.WorkingDir, BuildpackYMLSource))
-		if err != nil {
-			return packit.DetectResult{}, err
+		// check buildpack.yml and the version files written by Bundler and
+		// Ruby version managers
+		versionFiles := []struct {
+			source string
+			parser VersionParser
+		}{
+			{BuildpackYMLSource, buildpackYMLParser},
+			{RubyVersionSource, rubyVersionParser},
+			{GemfileSource, gemfileParser},
+			{GemfileLockSource, gemfileLockParser},
+			{ToolVersionsSource, toolVersionsParser},
+			{MiseTOMLSource, miseTOMLParser},
 		}
 
-		if version != "" {
-			requirements = append(requirements, packit.BuildPlanRequirement{
-				Name: MRI,
-				Metadata: BuildPlanMetadata{
-					VersionSource: BuildpackYMLSource,
-					Version:       version,
-				},
-			})
+		for _, file := range versionFiles {
+			version, err := file.parser.ParseVersion(filepath.Join(context.WorkingDir, file.source))
+			if err != nil {
+				return packit.DetectResult{}, err
+			}
+
+			if version != "" {
+				requirements = append(requirements, packit.BuildPlanRequirement{
+					Name: MRI,
+					Metadata: BuildPlanMetadata{
+						VersionSource: file.source,
+						Version:       version,
+					},
+				})
+			}
+		}
+
+		// check the gemspec only when nothing else has pinned a version, so gem
+		// repositories without a lockfile still get a compatible interpreter
+		if len(requirements) == 0 {
+			version, err := gemspecParser.ParseVersion(context.WorkingDir)
+			if err != nil {
+				return packit.DetectResult{}, err
+			}
+
+			if version != "" {
+				requirements = append(requirements, packit.BuildPlanRequirement{
+					Name: MRI,
+					Metadata: BuildPlanMetadata{
+						VersionSource: GemspecSource,
+						Version:       version,
+					},
+				})
+			}
 		}
 
 		return packit.DetectResult{
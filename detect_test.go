@@ -18,13 +18,33 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 		Expect = NewWithT(t).Expect
 
 		buildpackYMLParser *fakes.VersionParser
+		rubyVersionParser  *fakes.VersionParser
+		gemfileParser      *fakes.VersionParser
+		gemfileLockParser  *fakes.VersionParser
+		toolVersionsParser *fakes.VersionParser
+		miseTOMLParser     *fakes.VersionParser
+		gemspecParser      *fakes.VersionParser
 		detect             packit.DetectFunc
 	)
 
 	it.Before(func() {
 		buildpackYMLParser = &fakes.VersionParser{}
+		rubyVersionParser = &fakes.VersionParser{}
+		gemfileParser = &fakes.VersionParser{}
+		gemfileLockParser = &fakes.VersionParser{}
+		toolVersionsParser = &fakes.VersionParser{}
+		miseTOMLParser = &fakes.VersionParser{}
+		gemspecParser = &fakes.VersionParser{}
 
-		detect = mri.Detect(buildpackYMLParser)
+		detect = mri.Detect(
+			buildpackYMLParser,
+			rubyVersionParser,
+			gemfileParser,
+			gemfileLockParser,
+			toolVersionsParser,
+			miseTOMLParser,
+			gemspecParser,
+		)
 	})
 
 	it("returns a plan that provides mri", func() {
@@ -107,6 +127,164 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 		})
 	})
 
+	context("when the source code contains a .ruby-version file", func() {
+		it.Before(func() {
+			rubyVersionParser.ParseVersionCall.Returns.Version = "3.3.*"
+		})
+
+		it("returns a plan that provides and requires that version of mri", func() {
+			result, err := detect(packit.DetectContext{
+				WorkingDir: "/working-dir",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plan).To(Equal(packit.BuildPlan{
+				Provides: []packit.BuildPlanProvision{
+					{Name: mri.MRI},
+				},
+				Requires: []packit.BuildPlanRequirement{
+					{
+						Name: mri.MRI,
+						Metadata: mri.BuildPlanMetadata{
+							VersionSource: ".ruby-version",
+							Version:       "3.3.*",
+						},
+					},
+				},
+			}))
+
+			Expect(rubyVersionParser.ParseVersionCall.Receives.Path).To(Equal("/working-dir/.ruby-version"))
+		})
+	})
+
+	context("when the source code contains a Gemfile and a Gemfile.lock", func() {
+		it.Before(func() {
+			gemfileParser.ParseVersionCall.Returns.Version = ">= 3.3, < 4.0"
+			gemfileLockParser.ParseVersionCall.Returns.Version = ">= 3.3.4, < 3.4.0"
+		})
+
+		it("returns a plan that requires both versions of mri", func() {
+			result, err := detect(packit.DetectContext{
+				WorkingDir: "/working-dir",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plan).To(Equal(packit.BuildPlan{
+				Provides: []packit.BuildPlanProvision{
+					{Name: mri.MRI},
+				},
+				Requires: []packit.BuildPlanRequirement{
+					{
+						Name: mri.MRI,
+						Metadata: mri.BuildPlanMetadata{
+							VersionSource: "Gemfile",
+							Version:       ">= 3.3, < 4.0",
+						},
+					},
+					{
+						Name: mri.MRI,
+						Metadata: mri.BuildPlanMetadata{
+							VersionSource: "Gemfile.lock",
+							Version:       ">= 3.3.4, < 3.4.0",
+						},
+					},
+				},
+			}))
+
+			Expect(gemfileParser.ParseVersionCall.Receives.Path).To(Equal("/working-dir/Gemfile"))
+			Expect(gemfileLockParser.ParseVersionCall.Receives.Path).To(Equal("/working-dir/Gemfile.lock"))
+		})
+	})
+
+	context("when the source code contains a .tool-versions and a mise.toml file", func() {
+		it.Before(func() {
+			toolVersionsParser.ParseVersionCall.Returns.Version = "3.4.1"
+			miseTOMLParser.ParseVersionCall.Returns.Version = "3.4.*"
+		})
+
+		it("returns a plan that requires both versions of mri", func() {
+			result, err := detect(packit.DetectContext{
+				WorkingDir: "/working-dir",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plan).To(Equal(packit.BuildPlan{
+				Provides: []packit.BuildPlanProvision{
+					{Name: mri.MRI},
+				},
+				Requires: []packit.BuildPlanRequirement{
+					{
+						Name: mri.MRI,
+						Metadata: mri.BuildPlanMetadata{
+							VersionSource: ".tool-versions",
+							Version:       "3.4.1",
+						},
+					},
+					{
+						Name: mri.MRI,
+						Metadata: mri.BuildPlanMetadata{
+							VersionSource: "mise.toml",
+							Version:       "3.4.*",
+						},
+					},
+				},
+			}))
+
+			Expect(toolVersionsParser.ParseVersionCall.Receives.Path).To(Equal("/working-dir/.tool-versions"))
+			Expect(miseTOMLParser.ParseVersionCall.Receives.Path).To(Equal("/working-dir/mise.toml"))
+		})
+	})
+
+	context("when the source code only contains a gemspec", func() {
+		it.Before(func() {
+			gemspecParser.ParseVersionCall.Returns.Version = ">= 3.2, < 4.0"
+		})
+
+		it("returns a plan that requires the gemspec version of mri", func() {
+			result, err := detect(packit.DetectContext{
+				WorkingDir: "/working-dir",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plan).To(Equal(packit.BuildPlan{
+				Provides: []packit.BuildPlanProvision{
+					{Name: mri.MRI},
+				},
+				Requires: []packit.BuildPlanRequirement{
+					{
+						Name: mri.MRI,
+						Metadata: mri.BuildPlanMetadata{
+							VersionSource: "gemspec",
+							Version:       ">= 3.2, < 4.0",
+						},
+					},
+				},
+			}))
+
+			Expect(gemspecParser.ParseVersionCall.Receives.Path).To(Equal("/working-dir"))
+		})
+
+		context("when a stronger version source is present", func() {
+			it.Before(func() {
+				rubyVersionParser.ParseVersionCall.Returns.Version = "3.3.*"
+			})
+
+			it("does not consult the gemspec", func() {
+				result, err := detect(packit.DetectContext{
+					WorkingDir: "/working-dir",
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Plan.Requires).To(Equal([]packit.BuildPlanRequirement{
+					{
+						Name: mri.MRI,
+						Metadata: mri.BuildPlanMetadata{
+							VersionSource: ".ruby-version",
+							Version:       "3.3.*",
+						},
+					},
+				}))
+
+				Expect(gemspecParser.ParseVersionCall.CallCount).To(Equal(0))
+			})
+		})
+	})
+
 	context("failure cases", func() {
 		context("when the buildpack.yml parser fails", func() {
 			it.Before(func() {
@@ -120,5 +298,83 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 				Expect(err).To(MatchError("failed to parse buildpack.yml"))
 			})
 		})
+
+		context("when the .ruby-version parser fails", func() {
+			it.Before(func() {
+				rubyVersionParser.ParseVersionCall.Returns.Err = errors.New("failed to parse .ruby-version")
+			})
+
+			it("returns an error", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: "/working-dir",
+				})
+				Expect(err).To(MatchError("failed to parse .ruby-version"))
+			})
+		})
+
+		context("when the Gemfile parser fails", func() {
+			it.Before(func() {
+				gemfileParser.ParseVersionCall.Returns.Err = errors.New("failed to parse Gemfile")
+			})
+
+			it("returns an error", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: "/working-dir",
+				})
+				Expect(err).To(MatchError("failed to parse Gemfile"))
+			})
+		})
+
+		context("when the Gemfile.lock parser fails", func() {
+			it.Before(func() {
+				gemfileLockParser.ParseVersionCall.Returns.Err = errors.New("failed to parse Gemfile.lock")
+			})
+
+			it("returns an error", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: "/working-dir",
+				})
+				Expect(err).To(MatchError("failed to parse Gemfile.lock"))
+			})
+		})
+
+		context("when the .tool-versions parser fails", func() {
+			it.Before(func() {
+				toolVersionsParser.ParseVersionCall.Returns.Err = errors.New("failed to parse .tool-versions")
+			})
+
+			it("returns an error", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: "/working-dir",
+				})
+				Expect(err).To(MatchError("failed to parse .tool-versions"))
+			})
+		})
+
+		context("when the mise.toml parser fails", func() {
+			it.Before(func() {
+				miseTOMLParser.ParseVersionCall.Returns.Err = errors.New("failed to parse mise.toml")
+			})
+
+			it("returns an error", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: "/working-dir",
+				})
+				Expect(err).To(MatchError("failed to parse mise.toml"))
+			})
+		})
+
+		context("when the gemspec parser fails", func() {
+			it.Before(func() {
+				gemspecParser.ParseVersionCall.Returns.Err = errors.New("failed to parse gemspec")
+			})
+
+			it("returns an error", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: "/working-dir",
+				})
+				Expect(err).To(MatchError("failed to parse gemspec"))
+			})
+		})
 	})
 }
@@ -0,0 +1,75 @@
+package mri
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/paketo-buildpacks/packit/v2/postal"
+)
+
+// DefaultEOLWarningDays keeps the 30-day window of the deprecation warning that
+// packit's scribe.Emitter.SelectedDependency prints, which the build replaces.
+const DefaultEOLWarningDays = 30
+
+// EOLPolicy describes how the build treats MRI versions that are approaching
+// or have passed their end-of-life (deprecation) date.
+type EOLPolicy struct {
+	WarningDays int
+	FailOnEOL   bool
+}
+
+// LoadEOLPolicy reads the policy from $BP_MRI_EOL_WARNING_DAYS and
+// $BP_MRI_FAIL_ON_EOL.
+func LoadEOLPolicy() (EOLPolicy, error) {
+	policy := EOLPolicy{WarningDays: DefaultEOLWarningDays}
+
+	if value, ok := os.LookupEnv("BP_MRI_EOL_WARNING_DAYS"); ok {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 {
+			return EOLPolicy{}, fmt.Errorf("invalid value for $BP_MRI_EOL_WARNING_DAYS: %q is not a non-negative number of days", value)
+		}
+		policy.WarningDays = days
+	}
+
+	if value, ok := os.LookupEnv("BP_MRI_FAIL_ON_EOL"); ok {
+		fail, err := strconv.ParseBool(value)
+		if err != nil {
+			return EOLPolicy{}, fmt.Errorf("invalid value for $BP_MRI_FAIL_ON_EOL: %w", err)
+		}
+		policy.FailOnEOL = fail
+	}
+
+	return policy, nil
+}
+
+// IsEOL reports whether the dependency has reached its deprecation date.
+func (p EOLPolicy) IsEOL(dependency postal.Dependency, now time.Time) bool {
+	if dependency.DeprecationDate.IsZero() {
+		return false
+	}
+
+	return !dependency.DeprecationDate.After(now)
+}
+
+// IsNearEOL reports whether the dependency will reach its deprecation date
+// within the configured warning window.
+func (p EOLPolicy) IsNearEOL(dependency postal.Dependency, now time.Time) bool {
+	if dependency.DeprecationDate.IsZero() || p.IsEOL(dependency, now) {
+		return false
+	}
+
+	return dependency.DeprecationDate.Before(now.AddDate(0, 0, p.WarningDays))
+}
+
+// Check returns an error when the dependency has reached its deprecation
+// date and the policy does not allow end-of-life versions.
+func (p EOLPolicy) Check(dependency postal.Dependency, now time.Time) error {
+	if p.FailOnEOL && p.IsEOL(dependency, now) {
+		return fmt.Errorf("%s %s reached end-of-life on %s and $BP_MRI_FAIL_ON_EOL is set: select a supported version of %s",
+			dependency.Name, dependency.Version, dependency.DeprecationDate.Format("2006-01-02"), dependency.Name)
+	}
+
+	return nil
+}
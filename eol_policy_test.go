@@ -0,0 +1,118 @@
+package mri_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/paketo-buildpacks/mri"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testEOLPolicy(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		now        time.Time
+		dependency postal.Dependency
+	)
+
+	it.Before(func() {
+		now = time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC)
+
+		dependency = postal.Dependency{
+			Name:            "MRI",
+			Version:         "3.2.9",
+			DeprecationDate: time.Date(2027, time.March, 31, 0, 0, 0, 0, time.UTC),
+		}
+	})
+
+	context("LoadEOLPolicy", func() {
+		it("defaults to warning 30 days ahead without failing", func() {
+			policy, err := mri.LoadEOLPolicy()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy).To(Equal(mri.EOLPolicy{WarningDays: 30}))
+		})
+
+		context("when the environment configures the policy", func() {
+			it.Before(func() {
+				t.Setenv("BP_MRI_EOL_WARNING_DAYS", "180")
+				t.Setenv("BP_MRI_FAIL_ON_EOL", "true")
+			})
+
+			it("reads the configuration", func() {
+				policy, err := mri.LoadEOLPolicy()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(policy).To(Equal(mri.EOLPolicy{WarningDays: 180, FailOnEOL: true}))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when BP_MRI_EOL_WARNING_DAYS is negative", func() {
+				it.Before(func() {
+					t.Setenv("BP_MRI_EOL_WARNING_DAYS", "-1")
+				})
+
+				it("returns an error", func() {
+					_, err := mri.LoadEOLPolicy()
+					Expect(err).To(MatchError(`invalid value for $BP_MRI_EOL_WARNING_DAYS: "-1" is not a non-negative number of days`))
+				})
+			})
+
+			context("when BP_MRI_FAIL_ON_EOL is not a boolean", func() {
+				it.Before(func() {
+					t.Setenv("BP_MRI_FAIL_ON_EOL", "sometimes")
+				})
+
+				it("returns an error", func() {
+					_, err := mri.LoadEOLPolicy()
+					Expect(err).To(MatchError(ContainSubstring("invalid value for $BP_MRI_FAIL_ON_EOL")))
+				})
+			})
+		})
+	})
+
+	context("IsNearEOL", func() {
+		it("reports versions that reach end-of-life within the window", func() {
+			Expect(mri.EOLPolicy{WarningDays: 180}.IsNearEOL(dependency, now)).To(BeTrue())
+			Expect(mri.EOLPolicy{WarningDays: 30}.IsNearEOL(dependency, now)).To(BeFalse())
+		})
+
+		it("does not report versions that have already reached end-of-life", func() {
+			Expect(mri.EOLPolicy{WarningDays: 180}.IsNearEOL(dependency, dependency.DeprecationDate)).To(BeFalse())
+		})
+
+		it("does not report versions without a deprecation date", func() {
+			Expect(mri.EOLPolicy{WarningDays: 180}.IsNearEOL(postal.Dependency{}, now)).To(BeFalse())
+		})
+	})
+
+	context("IsEOL", func() {
+		it("reports versions on or after their deprecation date", func() {
+			policy := mri.EOLPolicy{}
+			Expect(policy.IsEOL(dependency, now)).To(BeFalse())
+			Expect(policy.IsEOL(dependency, dependency.DeprecationDate)).To(BeTrue())
+			Expect(policy.IsEOL(dependency, dependency.DeprecationDate.AddDate(0, 0, 1))).To(BeTrue())
+			Expect(policy.IsEOL(postal.Dependency{}, now)).To(BeFalse())
+		})
+	})
+
+	context("Check", func() {
+		it("allows end-of-life versions by default", func() {
+			Expect(mri.EOLPolicy{}.Check(dependency, dependency.DeprecationDate)).To(Succeed())
+		})
+
+		context("when the policy fails on end-of-life versions", func() {
+			it("allows supported versions", func() {
+				Expect(mri.EOLPolicy{FailOnEOL: true}.Check(dependency, now)).To(Succeed())
+			})
+
+			it("returns an error for end-of-life versions", func() {
+				err := mri.EOLPolicy{FailOnEOL: true}.Check(dependency, dependency.DeprecationDate)
+				Expect(err).To(MatchError("MRI 3.2.9 reached end-of-life on 2027-03-31 and $BP_MRI_FAIL_ON_EOL is set: select a supported version of MRI"))
+			})
+		})
+	})
+}
@@ -0,0 +1,9 @@
+package mri
+
+// These expose unexported functions to the tests of the mri_test package.
+var (
+	CopySlim              = copySlim
+	FormatSize            = formatSize
+	ResolveCustomArtifact = resolveCustomArtifact
+	SlimLaunchLayer       = slimLaunchLayer
+)
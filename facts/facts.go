@@ -0,0 +1,123 @@
+// Package facts reads the facts that the MRI buildpack publishes about the
+// Ruby installation it provides, so that later buildpacks do not need to
+// rediscover them.
+package facts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// The build-time environment variables that hold the facts.
+const (
+	VersionEnv    = "MRI_VERSION"
+	ABIVersionEnv = "MRI_ABI_VERSION"
+	HomeEnv       = "MRI_HOME"
+	PlatformEnv   = "MRI_PLATFORM"
+)
+
+// The keys that hold the facts in the metadata of the MRI layer.
+const (
+	VersionKey    = "version"
+	ABIVersionKey = "abi-version"
+	HomeKey       = "home"
+	PlatformKey   = "platform"
+)
+
+// Facts describe an MRI installation.
+type Facts struct {
+	// Version is the version of MRI, e.g. 3.4.8.
+	Version string
+
+	// ABIVersion is the ABI version of MRI, which names its gem and library
+	// directories, e.g. 3.4.0.
+	ABIVersion string
+
+	// Home is the installation prefix of MRI.
+	Home string
+
+	// Platform is the platform MRI was built for, e.g. x86_64-linux.
+	Platform string
+}
+
+// Metadata returns the facts as layer metadata, omitting those that are not
+// known.
+func (f Facts) Metadata() map[string]interface{} {
+	metadata := map[string]interface{}{}
+	for key, value := range map[string]string{
+		VersionKey:    f.Version,
+		ABIVersionKey: f.ABIVersion,
+		HomeKey:       f.Home,
+		PlatformKey:   f.Platform,
+	} {
+		if value != "" {
+			metadata[key] = value
+		}
+	}
+
+	return metadata
+}
+
+// Environment returns the facts as environment variables, omitting those
+// that are not known.
+func (f Facts) Environment() map[string]string {
+	environment := map[string]string{}
+	for key, value := range map[string]string{
+		VersionEnv:    f.Version,
+		ABIVersionEnv: f.ABIVersion,
+		HomeEnv:       f.Home,
+		PlatformEnv:   f.Platform,
+	} {
+		if value != "" {
+			environment[key] = value
+		}
+	}
+
+	return environment
+}
+
+// Read returns the facts recorded in the metadata of the MRI layer at the
+// given path.
+func Read(layerPath string) (Facts, error) {
+	var content struct {
+		Metadata map[string]interface{} `toml:"metadata"`
+	}
+
+	_, err := toml.DecodeFile(strings.TrimSuffix(layerPath, string(os.PathSeparator))+".toml", &content)
+	if err != nil {
+		return Facts{}, fmt.Errorf("failed to read MRI facts: %w", err)
+	}
+
+	value := func(key string) string {
+		s, _ := content.Metadata[key].(string)
+		return s
+	}
+
+	facts := Facts{
+		Version:    value(VersionKey),
+		ABIVersion: value(ABIVersionKey),
+		Home:       value(HomeKey),
+		Platform:   value(PlatformKey),
+	}
+
+	if facts.Version == "" || facts.Home == "" {
+		return Facts{}, fmt.Errorf("failed to read MRI facts: the metadata of %s does not record them", layerPath)
+	}
+
+	return facts, nil
+}
+
+// FromEnvironment returns the facts of the MRI installation that $MRI_HOME
+// points to.
+func FromEnvironment() (Facts, error) {
+	home, ok := os.LookupEnv(HomeEnv)
+	if !ok || home == "" {
+		return Facts{}, errors.New("failed to read MRI facts: $MRI_HOME is not set")
+	}
+
+	return Read(home)
+}
@@ -0,0 +1,112 @@
+package facts_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/mri/facts"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testFacts(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		layersDir string
+		layerPath string
+	)
+
+	it.Before(func() {
+		var err error
+		layersDir, err = os.MkdirTemp("", "layers")
+		Expect(err).NotTo(HaveOccurred())
+
+		layerPath = filepath.Join(layersDir, "mri")
+		Expect(os.WriteFile(layerPath+".toml", []byte(`launch = true
+build = true
+
+[metadata]
+  abi-version = "3.4.0"
+  dependency-sha = "sha256:some-sha"
+  home = "/layers/paketo-buildpacks_mri/mri"
+  platform = "x86_64-linux"
+  version = "3.4.8"
+`), 0600)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(layersDir)).To(Succeed())
+	})
+
+	context("Read", func() {
+		it("reads the facts from the layer metadata", func() {
+			f, err := facts.Read(layerPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f).To(Equal(facts.Facts{
+				Version:    "3.4.8",
+				ABIVersion: "3.4.0",
+				Home:       "/layers/paketo-buildpacks_mri/mri",
+				Platform:   "x86_64-linux",
+			}))
+		})
+
+		context("failure cases", func() {
+			context("when the layer metadata does not exist", func() {
+				it("returns an error", func() {
+					_, err := facts.Read(filepath.Join(layersDir, "missing"))
+					Expect(err).To(MatchError(ContainSubstring("failed to read MRI facts")))
+					Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
+				})
+			})
+
+			context("when the layer metadata does not record the facts", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(layerPath+".toml", []byte("[metadata]\ndependency-sha = \"sha256:some-sha\"\n"), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := facts.Read(layerPath)
+					Expect(err).To(MatchError(ContainSubstring("does not record them")))
+				})
+			})
+		})
+	})
+
+	context("FromEnvironment", func() {
+		it("reads the facts of the layer at $MRI_HOME", func() {
+			t.Setenv("MRI_HOME", layerPath)
+
+			f, err := facts.FromEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.Version).To(Equal("3.4.8"))
+		})
+
+		context("when $MRI_HOME is not set", func() {
+			it.Before(func() {
+				t.Setenv("MRI_HOME", "")
+			})
+
+			it("returns an error", func() {
+				_, err := facts.FromEnvironment()
+				Expect(err).To(MatchError("failed to read MRI facts: $MRI_HOME is not set"))
+			})
+		})
+	})
+
+	context("Metadata and Environment", func() {
+		it("omit the facts that are not known", func() {
+			f := facts.Facts{Version: "3.4.8", Home: "/some/home"}
+			Expect(f.Metadata()).To(Equal(map[string]interface{}{
+				"version": "3.4.8",
+				"home":    "/some/home",
+			}))
+			Expect(f.Environment()).To(Equal(map[string]string{
+				"MRI_VERSION": "3.4.8",
+				"MRI_HOME":    "/some/home",
+			}))
+		})
+	})
+}
@@ -0,0 +1,14 @@
+package facts_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestUnitFacts(t *testing.T) {
+	suite := spec.New("facts", spec.Report(report.Terminal{}))
+	suite("Facts", testFacts)
+	suite.Run(t)
+}
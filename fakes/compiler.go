@@ -0,0 +1,36 @@
+package fakes
+
+import (
+	"sync"
+
+	"github.com/paketo-buildpacks/packit/v2/postal"
+)
+
+type Compiler struct {
+	CompileCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Dependency postal.Dependency
+			LayerPath  string
+			Flags      []string
+		}
+		Returns struct {
+			Error error
+		}
+		Stub func(postal.Dependency, string, []string) error
+	}
+}
+
+func (f *Compiler) Compile(param1 postal.Dependency, param2 string, param3 []string) error {
+	f.CompileCall.mutex.Lock()
+	defer f.CompileCall.mutex.Unlock()
+	f.CompileCall.CallCount++
+	f.CompileCall.Receives.Dependency = param1
+	f.CompileCall.Receives.LayerPath = param2
+	f.CompileCall.Receives.Flags = param3
+	if f.CompileCall.Stub != nil {
+		return f.CompileCall.Stub(param1, param2, param3)
+	}
+	return f.CompileCall.Returns.Error
+}
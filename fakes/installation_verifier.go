@@ -0,0 +1,36 @@
+package fakes
+
+import (
+	"sync"
+
+	"github.com/paketo-buildpacks/mri"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+)
+
+type InstallationVerifier struct {
+	VerifyCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			LayerPath  string
+			Dependency postal.Dependency
+		}
+		Returns struct {
+			VerificationReport mri.VerificationReport
+			Error              error
+		}
+		Stub func(string, postal.Dependency) (mri.VerificationReport, error)
+	}
+}
+
+func (f *InstallationVerifier) Verify(param1 string, param2 postal.Dependency) (mri.VerificationReport, error) {
+	f.VerifyCall.mutex.Lock()
+	defer f.VerifyCall.mutex.Unlock()
+	f.VerifyCall.CallCount++
+	f.VerifyCall.Receives.LayerPath = param1
+	f.VerifyCall.Receives.Dependency = param2
+	if f.VerifyCall.Stub != nil {
+		return f.VerifyCall.Stub(param1, param2)
+	}
+	return f.VerifyCall.Returns.VerificationReport, f.VerifyCall.Returns.Error
+}
@@ -3,8 +3,8 @@ package fakes
 import (
 	"sync"
 
+	"github.com/paketo-buildpacks/mri"
 	"github.com/paketo-buildpacks/packit/v2/postal"
-	"github.com/paketo-buildpacks/packit/v2/sbom"
 )
 
 type SBOMGenerator struct {
@@ -16,14 +16,14 @@ type SBOMGenerator struct {
 			Dir        string
 		}
 		Returns struct {
-			SBOM  sbom.SBOM
-			Error error
+			InstallationSBOM mri.InstallationSBOM
+			Error            error
 		}
-		Stub func(postal.Dependency, string) (sbom.SBOM, error)
+		Stub func(postal.Dependency, string) (mri.InstallationSBOM, error)
 	}
 }
 
-func (f *SBOMGenerator) GenerateFromDependency(param1 postal.Dependency, param2 string) (sbom.SBOM, error) {
+func (f *SBOMGenerator) GenerateFromDependency(param1 postal.Dependency, param2 string) (mri.InstallationSBOM, error) {
 	f.GenerateFromDependencyCall.mutex.Lock()
 	defer f.GenerateFromDependencyCall.mutex.Unlock()
 	f.GenerateFromDependencyCall.CallCount++
@@ -32,5 +32,5 @@ func (f *SBOMGenerator) GenerateFromDependency(param1 postal.Dependency, param2
 	if f.GenerateFromDependencyCall.Stub != nil {
 		return f.GenerateFromDependencyCall.Stub(param1, param2)
 	}
-	return f.GenerateFromDependencyCall.Returns.SBOM, f.GenerateFromDependencyCall.Returns.Error
+	return f.GenerateFromDependencyCall.Returns.InstallationSBOM, f.GenerateFromDependencyCall.Returns.Error
 }
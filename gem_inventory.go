@@ -0,0 +1,159 @@
+package mri
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	gemspecName     = regexp.MustCompile(`^\s*\w+\.name\s*=\s*(.*)$`)
+	gemspecVersion  = regexp.MustCompile(`^\s*\w+\.version\s*=\s*(.*)$`)
+	gemspecLicenses = regexp.MustCompile(`^\s*\w+\.licenses?\s*=\s*(.*)$`)
+	gemDirectory    = regexp.MustCompile(`^(.+?)-(\d[^-]*)(?:-.+)?$`)
+)
+
+// Gem is a gem shipped with the MRI installation.
+type Gem struct {
+	Name     string
+	Version  string
+	Licenses []string
+
+	// Default is true for default gems, which are part of the standard
+	// library and cannot be uninstalled, and false for bundled gems.
+	Default bool
+}
+
+// PURL returns the package URL of the gem.
+func (g Gem) PURL() string {
+	return fmt.Sprintf("pkg:gem/%s@%s", g.Name, g.Version)
+}
+
+// GemInventory statically lists the default and bundled gems of the MRI
+// installation at the given layer path, sorted by name and version. Gems are
+// read from the gemspecs in the specifications directory of the installation
+// and from the gems directory for gems that have no gemspec. The gemspecs are
+// never evaluated. It returns no gems when the layout of the installation is
+// not recognized.
+func GemInventory(layerPath string) ([]Gem, error) {
+	abi, err := rubyABIVersion(layerPath)
+	if err != nil || abi == "" {
+		return nil, err
+	}
+
+	gemDir := filepath.Join(layerPath, "lib", "ruby", "gems", abi)
+
+	var gems []Gem
+	seen := map[string]bool{}
+	for _, dir := range []string{filepath.Join(gemDir, "specifications", "default"), filepath.Join(gemDir, "specifications")} {
+		gemspecs, err := filepath.Glob(filepath.Join(dir, "*.gemspec"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(gemspecs)
+
+		for _, gemspec := range gemspecs {
+			gem, err := parseInstalledGemspec(gemspec)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", gemspec, err)
+			}
+
+			if gem.Name == "" || gem.Version == "" {
+				continue
+			}
+
+			gem.Default = filepath.Base(dir) == "default"
+			if !seen[gem.Name+"-"+gem.Version] {
+				seen[gem.Name+"-"+gem.Version] = true
+				gems = append(gems, gem)
+			}
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(gemDir, "gems"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		matches := gemDirectory.FindStringSubmatch(entry.Name())
+		if matches == nil || seen[matches[1]+"-"+matches[2]] {
+			continue
+		}
+
+		seen[matches[1]+"-"+matches[2]] = true
+		gems = append(gems, Gem{Name: matches[1], Version: matches[2]})
+	}
+
+	sort.SliceStable(gems, func(i, j int) bool {
+		if gems[i].Name != gems[j].Name {
+			return gems[i].Name < gems[j].Name
+		}
+		return gems[i].Version < gems[j].Version
+	})
+
+	return gems, nil
+}
+
+// parseInstalledGemspec reads the name, version and licenses from a gemspec
+// written by RubyGems on installation, e.g.
+//
+//	s.name = "rexml".freeze
+//	s.version = "3.2.6".freeze
+//	s.licenses = ["BSD-2-Clause".freeze]
+func parseInstalledGemspec(path string) (Gem, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Gem{}, err
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to close file: %v\n", err)
+		}
+	}()
+
+	var gem Gem
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := stripRubyComment(scanner.Text())
+
+		if matches := gemspecName.FindStringSubmatch(line); matches != nil && gem.Name == "" {
+			gem.Name = firstQuotedString(matches[1])
+		}
+
+		if matches := gemspecVersion.FindStringSubmatch(line); matches != nil && gem.Version == "" {
+			gem.Version = firstQuotedString(matches[1])
+		}
+
+		if matches := gemspecLicenses.FindStringSubmatch(line); matches != nil && gem.Licenses == nil {
+			for _, license := range quotedString.FindAllStringSubmatch(matches[1], -1) {
+				if license[1] != "" {
+					gem.Licenses = append(gem.Licenses, license[1])
+				}
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return Gem{}, err
+	}
+
+	return gem, nil
+}
+
+func firstQuotedString(value string) string {
+	matches := quotedString.FindStringSubmatch(value)
+	if matches == nil {
+		return ""
+	}
+
+	return strings.TrimSpace(matches[1])
+}
@@ -0,0 +1,111 @@
+package mri_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/mri"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testGemInventory(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		layerPath string
+		gemDir    string
+	)
+
+	it.Before(func() {
+		var err error
+		layerPath, err = os.MkdirTemp("", "layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		gemDir = filepath.Join(layerPath, "lib", "ruby", "gems", "3.4.0")
+		Expect(os.MkdirAll(filepath.Join(gemDir, "specifications", "default"), os.ModePerm)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(gemDir, "gems", "rexml-3.4.0"), os.ModePerm)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(gemDir, "gems", "debug-1.10.0"), os.ModePerm)).To(Succeed())
+
+		Expect(os.WriteFile(filepath.Join(gemDir, "specifications", "default", "openssl-3.3.0.gemspec"), []byte(`# -*- encoding: utf-8 -*-
+# stub: openssl 3.3.0 ruby lib
+# stub: ext/openssl/extconf.rb
+
+Gem::Specification.new do |s|
+  s.name = "openssl".freeze
+  s.version = "3.3.0".freeze
+
+  s.required_rubygems_version = Gem::Requirement.new(">= 0".freeze) if s.respond_to? :required_rubygems_version=
+  s.licenses = ["Ruby".freeze, "BSD-2-Clause".freeze]
+  s.required_ruby_version = Gem::Requirement.new(">= 2.7.0".freeze)
+  s.specification_version = 4
+end
+`), 0600)).To(Succeed())
+
+		Expect(os.WriteFile(filepath.Join(gemDir, "specifications", "rexml-3.4.0.gemspec"), []byte(`# -*- encoding: utf-8 -*-
+# stub: rexml 3.4.0 ruby lib
+
+Gem::Specification.new do |s|
+  s.name = "rexml".freeze
+  s.version = "3.4.0".freeze
+  s.license = "BSD-2-Clause".freeze
+end
+`), 0600)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(layerPath)).To(Succeed())
+	})
+
+	it("lists the default and bundled gems of the installation", func() {
+		gems, err := mri.GemInventory(layerPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gems).To(Equal([]mri.Gem{
+			{Name: "debug", Version: "1.10.0"},
+			{Name: "openssl", Version: "3.3.0", Licenses: []string{"Ruby", "BSD-2-Clause"}, Default: true},
+			{Name: "rexml", Version: "3.4.0", Licenses: []string{"BSD-2-Clause"}},
+		}))
+
+		Expect(gems[1].PURL()).To(Equal("pkg:gem/openssl@3.3.0"))
+	})
+
+	context("when a gem directory names a platform", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(gemDir, "gems", "racc-1.8.1-x86_64-linux"), os.ModePerm)).To(Succeed())
+		})
+
+		it("leaves the platform out of the version", func() {
+			gems, err := mri.GemInventory(layerPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gems).To(ContainElement(mri.Gem{Name: "racc", Version: "1.8.1"}))
+		})
+	})
+
+	context("when the layout is not recognized", func() {
+		it.Before(func() {
+			Expect(os.RemoveAll(filepath.Join(layerPath, "lib"))).To(Succeed())
+		})
+
+		it("returns no gems", func() {
+			gems, err := mri.GemInventory(layerPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gems).To(BeEmpty())
+		})
+	})
+
+	context("failure cases", func() {
+		context("when a gemspec cannot be read", func() {
+			it.Before(func() {
+				Expect(os.Chmod(filepath.Join(gemDir, "specifications", "rexml-3.4.0.gemspec"), 0000)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := mri.GemInventory(layerPath)
+				Expect(err).To(MatchError(ContainSubstring("permission denied")))
+				Expect(err).To(MatchError(ContainSubstring("failed to parse")))
+			})
+		})
+	})
+}
@@ -0,0 +1,123 @@
+package mri
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/Masterminds/semver"
+)
+
+var (
+	rbConfigRubyVersion = regexp.MustCompile(`CONFIG\["ruby_version"\]\s*=\s*"([^"]+)"`)
+	rubyGemsVersion     = regexp.MustCompile(`(?m)^\s*VERSION\s*=\s*"([^"]+)"`)
+)
+
+// StaticGemPath derives the default gem path of the MRI installation at the
+// given layer path without running Ruby, mirroring Gem.default_path: the user
+// gem directory under the given home directory, followed by the gem directory
+// of the installation. It returns an empty path when the layout of the
+// installation is not recognized.
+func StaticGemPath(layerPath, home string) (string, error) {
+	abi, err := rubyABIVersion(layerPath)
+	if err != nil || abi == "" {
+		return "", err
+	}
+
+	defaultDir := filepath.Join(layerPath, "lib", "ruby", "gems", abi)
+	if _, err := os.Stat(defaultDir); err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	if home == "" {
+		return defaultDir, nil
+	}
+
+	if _, err := os.Stat(home); err != nil {
+		if os.IsNotExist(err) {
+			return defaultDir, nil
+		}
+		return "", err
+	}
+
+	userDir, err := gemUserDir(layerPath, abi, home)
+	if err != nil {
+		return "", err
+	}
+
+	return userDir + string(os.PathListSeparator) + defaultDir, nil
+}
+
+// rubyABIVersion returns the ABI version (e.g. 3.4.0) of the installation,
+// which names its gem and library directories. It is read from the single
+// directory in lib/ruby/gems, falling back to the ruby_version recorded in
+// rbconfig.rb.
+func rubyABIVersion(layerPath string) (string, error) {
+	entries, err := os.ReadDir(filepath.Join(layerPath, "lib", "ruby", "gems"))
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
+	var abis []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			abis = append(abis, entry.Name())
+		}
+	}
+
+	if len(abis) == 1 {
+		return abis[0], nil
+	}
+
+	rbconfigs, err := filepath.Glob(filepath.Join(layerPath, "lib", "ruby", "*", "*", "rbconfig.rb"))
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(rbconfigs)
+
+	for _, rbconfig := range rbconfigs {
+		content, err := os.ReadFile(rbconfig)
+		if err != nil {
+			return "", err
+		}
+
+		if matches := rbConfigRubyVersion.FindSubmatch(content); matches != nil {
+			return string(matches[1]), nil
+		}
+	}
+
+	return "", nil
+}
+
+// gemUserDir returns the user gem directory for the given ABI version.
+// RubyGems uses ~/.gem when it exists and, since RubyGems 3.5, the XDG data
+// directory otherwise.
+func gemUserDir(layerPath, abi, home string) (string, error) {
+	legacyDir := filepath.Join(home, ".gem")
+	if _, err := os.Stat(legacyDir); err == nil {
+		return filepath.Join(legacyDir, "ruby", abi), nil
+	}
+
+	content, err := os.ReadFile(filepath.Join(layerPath, "lib", "ruby", abi, "rubygems.rb"))
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
+	if matches := rubyGemsVersion.FindSubmatch(content); matches != nil {
+		version, err := semver.NewVersion(string(matches[1]))
+		if err == nil && version.LessThan(semver.MustParse("3.5.0")) {
+			return filepath.Join(legacyDir, "ruby", abi), nil
+		}
+	}
+
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+
+	return filepath.Join(dataHome, "gem", "ruby", abi), nil
+}
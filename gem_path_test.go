@@ -0,0 +1,141 @@
+package mri_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/mri"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testStaticGemPath(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		layerPath string
+		home      string
+	)
+
+	it.Before(func() {
+		var err error
+		layerPath, err = os.MkdirTemp("", "layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		home, err = os.MkdirTemp("", "home")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(layerPath, "lib", "ruby", "gems", "3.4.0"), os.ModePerm)).To(Succeed())
+
+		t.Setenv("XDG_DATA_HOME", "")
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(layerPath)).To(Succeed())
+		Expect(os.RemoveAll(home)).To(Succeed())
+	})
+
+	it("returns the user and installation gem directories", func() {
+		gemPath, err := mri.StaticGemPath(layerPath, home)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gemPath).To(Equal(filepath.Join(home, ".local", "share", "gem", "ruby", "3.4.0") + ":" + filepath.Join(layerPath, "lib", "ruby", "gems", "3.4.0")))
+	})
+
+	context("when XDG_DATA_HOME is set", func() {
+		it.Before(func() {
+			t.Setenv("XDG_DATA_HOME", "/some/data/home")
+		})
+
+		it("uses it for the user gem directory", func() {
+			gemPath, err := mri.StaticGemPath(layerPath, home)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gemPath).To(HavePrefix("/some/data/home/gem/ruby/3.4.0:"))
+		})
+	})
+
+	context("when ~/.gem exists", func() {
+		it.Before(func() {
+			Expect(os.Mkdir(filepath.Join(home, ".gem"), os.ModePerm)).To(Succeed())
+		})
+
+		it("uses it for the user gem directory", func() {
+			gemPath, err := mri.StaticGemPath(layerPath, home)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gemPath).To(HavePrefix(filepath.Join(home, ".gem", "ruby", "3.4.0") + ":"))
+		})
+	})
+
+	context("when the installation ships a RubyGems version older than 3.5", func() {
+		it.Before(func() {
+			Expect(os.RemoveAll(filepath.Join(layerPath, "lib", "ruby", "gems", "3.4.0"))).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(layerPath, "lib", "ruby", "gems", "3.2.0"), os.ModePerm)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(layerPath, "lib", "ruby", "3.2.0"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(layerPath, "lib", "ruby", "3.2.0", "rubygems.rb"), []byte(`module Gem
+  VERSION = "3.4.19"
+end
+`), 0600)).To(Succeed())
+		})
+
+		it("uses ~/.gem for the user gem directory", func() {
+			gemPath, err := mri.StaticGemPath(layerPath, home)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gemPath).To(Equal(filepath.Join(home, ".gem", "ruby", "3.2.0") + ":" + filepath.Join(layerPath, "lib", "ruby", "gems", "3.2.0")))
+		})
+	})
+
+	context("when there are several gem directories", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(layerPath, "lib", "ruby", "gems", "3.3.0"), os.ModePerm)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(layerPath, "lib", "ruby", "3.4.0", "x86_64-linux"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(layerPath, "lib", "ruby", "3.4.0", "x86_64-linux", "rbconfig.rb"), []byte(`module RbConfig
+  CONFIG = {}
+  CONFIG["MAJOR"] = "3"
+  CONFIG["ruby_version"] = "3.4.0"
+end
+`), 0600)).To(Succeed())
+		})
+
+		it("reads the ABI version from rbconfig.rb", func() {
+			gemPath, err := mri.StaticGemPath(layerPath, home)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gemPath).To(HaveSuffix(":" + filepath.Join(layerPath, "lib", "ruby", "gems", "3.4.0")))
+		})
+	})
+
+	context("when the home directory does not exist", func() {
+		it("only returns the installation gem directory", func() {
+			gemPath, err := mri.StaticGemPath(layerPath, filepath.Join(home, "missing"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gemPath).To(Equal(filepath.Join(layerPath, "lib", "ruby", "gems", "3.4.0")))
+		})
+	})
+
+	context("when the layout is not recognized", func() {
+		it.Before(func() {
+			Expect(os.RemoveAll(filepath.Join(layerPath, "lib"))).To(Succeed())
+		})
+
+		it("returns an empty path", func() {
+			gemPath, err := mri.StaticGemPath(layerPath, home)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gemPath).To(BeEmpty())
+		})
+	})
+
+	context("failure cases", func() {
+		context("when rbconfig.rb cannot be read", func() {
+			it.Before(func() {
+				Expect(os.RemoveAll(filepath.Join(layerPath, "lib", "ruby", "gems"))).To(Succeed())
+				Expect(os.MkdirAll(filepath.Join(layerPath, "lib", "ruby", "3.4.0", "x86_64-linux"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(layerPath, "lib", "ruby", "3.4.0", "x86_64-linux", "rbconfig.rb"), nil, 0000)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := mri.StaticGemPath(layerPath, home)
+				Expect(err).To(MatchError(ContainSubstring("permission denied")))
+			})
+		})
+	})
+}
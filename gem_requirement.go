@@ -0,0 +1,90 @@
+package mri
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	gemRequirementPattern = regexp.MustCompile(`^(=|!=|>=|<=|>|<|~>)?\s*(\S+)$`)
+	gemPrereleasePattern  = regexp.MustCompile(`^(\d+(?:\.\d+)*)\.([A-Za-z][0-9A-Za-z.]*)$`)
+)
+
+// convertGemRequirements translates a list of RubyGems requirements, as
+// found in a Gemfile ruby directive or a gemspec required_ruby_version, into
+// a single semver constraint where every requirement must hold.
+func convertGemRequirements(requirements []string) (string, error) {
+	var constraints []string
+	for _, requirement := range requirements {
+		constraint, err := convertGemRequirement(requirement)
+		if err != nil {
+			return "", err
+		}
+
+		if constraint != "" {
+			constraints = append(constraints, constraint)
+		}
+	}
+
+	return strings.Join(constraints, ", "), nil
+}
+
+func convertGemRequirement(requirement string) (string, error) {
+	matches := gemRequirementPattern.FindStringSubmatch(strings.TrimSpace(requirement))
+	if matches == nil {
+		return "", fmt.Errorf("%q is not a valid requirement", requirement)
+	}
+
+	operator, version := matches[1], normalizeGemVersion(patchLevelPattern.ReplaceAllString(matches[2], ""))
+
+	switch operator {
+	case "", "=":
+		return normalizeRubyVersion(version)
+
+	case "~>":
+		// RubyGems' pessimistic operator allows the last given segment to
+		// increase: "~> 3.3" is ">= 3.3, < 4.0" and "~> 3.3.4" is
+		// ">= 3.3.4, < 3.4.0". A prerelease only affects the lower bound.
+		release, _, _ := strings.Cut(version, "-")
+		segments := strings.Split(release, ".")
+		for _, segment := range segments {
+			if _, err := strconv.Atoi(segment); err != nil {
+				return "", fmt.Errorf("%q is not a valid requirement", requirement)
+			}
+		}
+
+		upper := segments
+		if len(upper) > 1 {
+			upper = upper[:len(upper)-1]
+		}
+
+		last, _ := strconv.Atoi(upper[len(upper)-1])
+		upper = append(append([]string{}, upper[:len(upper)-1]...), strconv.Itoa(last+1))
+		for len(upper) < len(segments) {
+			upper = append(upper, "0")
+		}
+
+		return fmt.Sprintf(">= %s, < %s", version, strings.Join(upper, ".")), nil
+
+	default:
+		if !rubyVersionPattern.MatchString(version) {
+			return "", fmt.Errorf("%q is not a valid requirement", requirement)
+		}
+
+		return fmt.Sprintf("%s %s", operator, version), nil
+	}
+}
+
+// normalizeGemVersion converts a RubyGems prerelease version, where the
+// prerelease is the first segment containing a letter (e.g. "3.4.0.preview1"
+// or "3.1.0.a"), into its semver form ("3.4.0-preview1", "3.1.0-a").
+func normalizeGemVersion(version string) string {
+	matches := gemPrereleasePattern.FindStringSubmatch(version)
+	if matches == nil {
+		return version
+	}
+
+	return fmt.Sprintf("%s-%s", matches[1], matches[2])
+}
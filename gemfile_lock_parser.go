@@ -0,0 +1,81 @@
+package mri
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+type GemfileLockParser struct {
+	logger scribe.Emitter
+}
+
+func NewGemfileLockParser(logger scribe.Emitter) GemfileLockParser {
+	return GemfileLockParser{
+		logger: logger,
+	}
+}
+
+// ParseVersion reads the RUBY VERSION section of a Gemfile.lock and returns
+// a semver constraint that allows newer patch releases of the recorded
+// version (e.g. "ruby 3.3.4p94" becomes ">= 3.3.4, < 3.4.0"). Bundler does
+// not require the exact recorded patch release, and only the latest patches
+// of each line are shipped in the buildpack. An entry that cannot be read is
+// logged and ignored so that it does not fail detection.
+func (p GemfileLockParser) ParseVersion(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to close file: %v\n", err)
+		}
+	}()
+
+	var inSection bool
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if !inSection {
+			inSection = strings.TrimSpace(line) == "RUBY VERSION"
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			break
+		}
+
+		if len(fields) != 2 || fields[0] != "ruby" {
+			return p.skip(path, fmt.Errorf("malformed RUBY VERSION entry %q", strings.TrimSpace(line)))
+		}
+
+		version, err := convertGemRequirement("~> " + fields[1])
+		if err != nil {
+			return p.skip(path, err)
+		}
+
+		return version, nil
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return "", nil
+}
+
+func (p GemfileLockParser) skip(path string, err error) (string, error) {
+	p.logger.Debug.Process("Ignoring the RUBY VERSION section in %s: %s", path, err)
+	p.logger.Debug.Break()
+
+	return "", nil
+}
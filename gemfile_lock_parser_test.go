@@ -0,0 +1,138 @@
+package mri_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/mri"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testGemfileLockParser(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		workingDir string
+		path       string
+		buffer     *bytes.Buffer
+		parser     mri.GemfileLockParser
+	)
+
+	it.Before(func() {
+		var err error
+		workingDir, err = os.MkdirTemp("", "working-dir")
+		Expect(err).NotTo(HaveOccurred())
+
+		path = filepath.Join(workingDir, "Gemfile.lock")
+		Expect(os.WriteFile(path, []byte(`GEM
+  remote: https://rubygems.org/
+  specs:
+    rack (3.0.8)
+
+PLATFORMS
+  x86_64-linux
+
+DEPENDENCIES
+  rack
+
+RUBY VERSION
+   ruby 3.3.4p94
+
+BUNDLED WITH
+   2.5.11
+`), 0600)).To(Succeed())
+
+		buffer = bytes.NewBuffer(nil)
+		parser = mri.NewGemfileLockParser(scribe.NewEmitter(buffer).WithLevel("DEBUG"))
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(workingDir)).To(Succeed())
+	})
+
+	context("ParseVersion", func() {
+		it("parses the mri version from the RUBY VERSION section", func() {
+			version, err := parser.ParseVersion(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal(">= 3.3.4, < 3.4.0"))
+		})
+
+		context("when the Gemfile.lock has no RUBY VERSION section", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte(`GEM
+  remote: https://rubygems.org/
+  specs:
+    rack (3.0.8)
+
+BUNDLED WITH
+   2.5.11
+`), 0600)).To(Succeed())
+			})
+
+			it("returns an empty version", func() {
+				version, err := parser.ParseVersion(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(BeEmpty())
+			})
+		})
+
+		context("when the RUBY VERSION section records a prerelease", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte("RUBY VERSION\n   ruby 3.4.0.preview1\n"), 0600)).To(Succeed())
+			})
+
+			it("normalizes it into a semver prerelease", func() {
+				version, err := parser.ParseVersion(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(Equal(">= 3.4.0-preview1, < 3.5.0"))
+			})
+		})
+
+		context("when the RUBY VERSION section is malformed", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte("RUBY VERSION\n   jruby\n"), 0600)).To(Succeed())
+			})
+
+			it("ignores it", func() {
+				version, err := parser.ParseVersion(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(BeEmpty())
+				Expect(buffer.String()).To(ContainSubstring(`malformed RUBY VERSION entry "jruby"`))
+			})
+		})
+
+		context("when the Gemfile.lock does not exist", func() {
+			it.Before(func() {
+				Expect(os.Remove(path)).To(Succeed())
+			})
+
+			it("returns an empty version", func() {
+				version, err := parser.ParseVersion(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(BeEmpty())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the Gemfile.lock cannot be read", func() {
+				it.Before(func() {
+					Expect(os.Chmod(path, 0000)).To(Succeed())
+				})
+
+				it.After(func() {
+					Expect(os.Chmod(path, 0644)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := parser.ParseVersion(path)
+					Expect(err).To(MatchError(ContainSubstring("permission denied")))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,122 @@
+package mri
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+var (
+	gemfileRubyDirective = regexp.MustCompile(`(?m)^\s*ruby(?:\s+|\s*\()(.*)$`)
+	gemfileRubyOption    = regexp.MustCompile(`(?::(\w+)\s*=>|\b(\w+):)\s*["']([^"']*)["']`)
+	gemfileFileRead      = regexp.MustCompile(`File\.read\(\s*["']([^"']+)["']\s*\)`)
+	quotedString         = regexp.MustCompile(`["']([^"']*)["']`)
+)
+
+type GemfileParser struct {
+	logger scribe.Emitter
+}
+
+func NewGemfileParser(logger scribe.Emitter) GemfileParser {
+	return GemfileParser{
+		logger: logger,
+	}
+}
+
+// ParseVersion reads the ruby directive from a Gemfile and returns the
+// requested MRI version as a semver constraint. Requirements using the
+// pessimistic operator are expanded into their equivalent ranges, and
+// directives that defer to a version file (`ruby file: ".ruby-version"`) are
+// resolved relative to the Gemfile. A directive that cannot be read, such as
+// one computing the version at runtime, is logged and ignored so that it does
+// not fail detection.
+func (p GemfileParser) ParseVersion(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	matches := gemfileRubyDirective.FindSubmatch(content)
+	if matches == nil {
+		return "", nil
+	}
+
+	directive := strings.TrimSpace(stripRubyComment(string(matches[1])))
+	arguments := strings.TrimSuffix(directive, ")")
+
+	var file string
+	for _, option := range gemfileRubyOption.FindAllStringSubmatch(arguments, -1) {
+		name := option[1] + option[2]
+		switch name {
+		case "engine":
+			if option[3] != "ruby" {
+				return "", nil
+			}
+		case "file":
+			file = option[3]
+		}
+	}
+	arguments = gemfileRubyOption.ReplaceAllString(arguments, "")
+
+	if match := gemfileFileRead.FindStringSubmatch(arguments); match != nil {
+		file = match[1]
+		arguments = gemfileFileRead.ReplaceAllString(arguments, "")
+	}
+
+	if file != "" {
+		version, err := NewRubyVersionParser().ParseVersion(filepath.Join(filepath.Dir(path), file))
+		if err != nil {
+			return p.skip(path, err)
+		}
+
+		return version, nil
+	}
+
+	if expression := strings.Trim(quotedString.ReplaceAllString(arguments, ""), ", \t"); expression != "" {
+		return p.skip(path, fmt.Errorf("%q is not a list of version strings", directive))
+	}
+
+	var requirements []string
+	for _, requirement := range quotedString.FindAllStringSubmatch(arguments, -1) {
+		requirements = append(requirements, requirement[1])
+	}
+
+	version, err := convertGemRequirements(requirements)
+	if err != nil {
+		return p.skip(path, err)
+	}
+
+	return version, nil
+}
+
+func (p GemfileParser) skip(path string, err error) (string, error) {
+	p.logger.Debug.Process("Ignoring the ruby directive in %s: %s", path, err)
+	p.logger.Debug.Break()
+
+	return "", nil
+}
+
+// stripRubyComment removes a trailing comment from a line of Ruby, ignoring
+// any "#" characters that appear inside string literals.
+func stripRubyComment(line string) string {
+	var quote rune
+	for i, c := range line {
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == 0 && c == '#':
+			return line[:i]
+		}
+	}
+
+	return line
+}
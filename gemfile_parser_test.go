@@ -0,0 +1,191 @@
+package mri_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/mri"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testGemfileParser(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		workingDir string
+		path       string
+		buffer     *bytes.Buffer
+		parser     mri.GemfileParser
+	)
+
+	it.Before(func() {
+		var err error
+		workingDir, err = os.MkdirTemp("", "working-dir")
+		Expect(err).NotTo(HaveOccurred())
+
+		path = filepath.Join(workingDir, "Gemfile")
+		Expect(os.WriteFile(path, []byte(`source "https://rubygems.org"
+
+ruby "3.3.4"
+
+gem "rails", "~> 7.1"
+`), 0600)).To(Succeed())
+
+		buffer = bytes.NewBuffer(nil)
+		parser = mri.NewGemfileParser(scribe.NewEmitter(buffer).WithLevel("DEBUG"))
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(workingDir)).To(Succeed())
+	})
+
+	context("ParseVersion", func() {
+		it("parses the mri version from the ruby directive", func() {
+			version, err := parser.ParseVersion(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal("3.3.4"))
+		})
+
+		context("when the ruby directive uses requirement operators", func() {
+			for directive, expected := range map[string]string{
+				`ruby "~> 3.3"`:                     ">= 3.3, < 4.0",
+				`ruby "~> 3.3.4"`:                   ">= 3.3.4, < 3.4.0",
+				`ruby "~> 3"`:                       ">= 3, < 4",
+				`ruby ">= 3.2", "< 4.0"`:            ">= 3.2, < 4.0",
+				`ruby("= 3.3")`:                     "3.3.*",
+				`ruby '3.3.4', patchlevel: '94'`:    "3.3.4",
+				`ruby "3.3.4" # keep in sync w/ CI`: "3.3.4",
+				`  ruby "!= 3.3.1", ">= 3.3"`:       "!= 3.3.1, >= 3.3",
+				`ruby "3.4.0.preview1"`:             "3.4.0-preview1",
+				`ruby "~> 3.4.0.rc1"`:               ">= 3.4.0-rc1, < 3.5.0",
+				`ruby ">= 3.1.0.a"`:                 ">= 3.1.0-a",
+			} {
+				it("converts "+directive+" into a semver constraint", func() {
+					Expect(os.WriteFile(path, []byte(directive+"\n"), 0600)).To(Succeed())
+
+					version, err := parser.ParseVersion(path)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(version).To(Equal(expected))
+				})
+			}
+		})
+
+		context("when the ruby directive refers to a version file", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, ".ruby-version"), []byte("ruby-3.4.1\n"), 0600)).To(Succeed())
+			})
+
+			it("reads the version from the file option", func() {
+				Expect(os.WriteFile(path, []byte(`ruby file: ".ruby-version"`), 0600)).To(Succeed())
+
+				version, err := parser.ParseVersion(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(Equal("3.4.1"))
+			})
+
+			it("reads the version from the legacy hash rocket file option", func() {
+				Expect(os.WriteFile(path, []byte(`ruby :file => ".ruby-version"`), 0600)).To(Succeed())
+
+				version, err := parser.ParseVersion(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(Equal("3.4.1"))
+			})
+
+			it("reads the version from a File.read call", func() {
+				Expect(os.WriteFile(path, []byte(`ruby File.read(".ruby-version").strip`), 0600)).To(Succeed())
+
+				version, err := parser.ParseVersion(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(Equal("3.4.1"))
+			})
+		})
+
+		context("when the ruby directive requests an engine other than MRI", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte(`ruby "3.1.4", engine: "jruby", engine_version: "9.4.5.0"`), 0600)).To(Succeed())
+			})
+
+			it("returns an empty version", func() {
+				version, err := parser.ParseVersion(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(BeEmpty())
+			})
+		})
+
+		context("when the Gemfile has no ruby directive", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte(`source "https://rubygems.org"
+gem "ruby-progressbar"
+`), 0600)).To(Succeed())
+			})
+
+			it("returns an empty version", func() {
+				version, err := parser.ParseVersion(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(BeEmpty())
+			})
+		})
+
+		context("when the Gemfile does not exist", func() {
+			it.Before(func() {
+				Expect(os.Remove(path)).To(Succeed())
+			})
+
+			it("returns an empty version", func() {
+				version, err := parser.ParseVersion(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(BeEmpty())
+			})
+		})
+
+		context("when the ruby directive cannot be read", func() {
+			for directive, reason := range map[string]string{
+				`ruby ENV.fetch("RUBY_VERSION", "3.3.4")`: `"ENV.fetch(\"RUBY_VERSION\", \"3.3.4\")" is not a list of version strings`,
+				`ruby RUBY_VERSION`:                       `"RUBY_VERSION" is not a list of version strings`,
+				`ruby "~> three"`:                         `"~> three" is not a valid requirement`,
+			} {
+				it("ignores "+directive, func() {
+					Expect(os.WriteFile(path, []byte(directive+"\n"), 0600)).To(Succeed())
+
+					version, err := parser.ParseVersion(path)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(version).To(BeEmpty())
+					Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("Ignoring the ruby directive in %s: %s", path, reason)))
+				})
+			}
+
+			it("ignores a referenced version file that cannot be read", func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, ".ruby-version"), []byte("latest"), 0600)).To(Succeed())
+				Expect(os.WriteFile(path, []byte(`ruby file: ".ruby-version"`), 0600)).To(Succeed())
+
+				version, err := parser.ParseVersion(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(BeEmpty())
+				Expect(buffer.String()).To(ContainSubstring(`"latest" is not a valid MRI version`))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the Gemfile cannot be read", func() {
+				it.Before(func() {
+					Expect(os.Chmod(path, 0000)).To(Succeed())
+				})
+
+				it.After(func() {
+					Expect(os.Chmod(path, 0644)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := parser.ParseVersion(path)
+					Expect(err).To(MatchError(ContainSubstring("permission denied")))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,121 @@
+package mri
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+var gemspecRequiredRubyVersion = regexp.MustCompile(`^\s*\w+\.required_ruby_version\s*=\s*(.*)$`)
+
+type GemspecParser struct {
+	logger scribe.Emitter
+}
+
+func NewGemspecParser(logger scribe.Emitter) GemspecParser {
+	return GemspecParser{
+		logger: logger,
+	}
+}
+
+// ParseVersion statically reads the required_ruby_version declaration from
+// the *.gemspec files in the given directory and returns it as a semver
+// constraint. The gemspecs are never evaluated, so declarations that are
+// computed at runtime (e.g. interpolated strings) are ignored. When several
+// gemspecs declare a requirement, all of them must be satisfied. The gemspec is
+// only a fallback hint, so one that cannot be read or parsed is logged and
+// ignored rather than failing detection.
+func (p GemspecParser) ParseVersion(path string) (string, error) {
+	gemspecs, err := filepath.Glob(filepath.Join(path, "*.gemspec"))
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(gemspecs)
+
+	var constraints []string
+	for _, gemspec := range gemspecs {
+		requirements, err := parseRequiredRubyVersion(gemspec)
+		if err != nil {
+			p.skip(gemspec, err)
+			continue
+		}
+
+		constraint, err := convertGemRequirements(requirements)
+		if err != nil {
+			p.skip(gemspec, err)
+			continue
+		}
+
+		if constraint != "" && !slices.Contains(constraints, constraint) {
+			constraints = append(constraints, constraint)
+		}
+	}
+
+	return strings.Join(constraints, ", "), nil
+}
+
+func (p GemspecParser) skip(path string, err error) {
+	p.logger.Debug.Process("Ignoring the required_ruby_version in %s: %s", path, err)
+	p.logger.Debug.Break()
+}
+
+func parseRequiredRubyVersion(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to close file: %v\n", err)
+		}
+	}()
+
+	var declaration string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := stripRubyComment(scanner.Text())
+
+		if declaration == "" {
+			matches := gemspecRequiredRubyVersion.FindStringSubmatch(line)
+			if matches == nil {
+				continue
+			}
+			declaration = matches[1]
+		} else {
+			declaration += line
+		}
+
+		// Keep reading while an array literal or argument list is still open,
+		// e.g. `required_ruby_version = [` followed by one requirement per line.
+		trimmed := strings.TrimSpace(declaration)
+		if strings.Count(trimmed, "[") > strings.Count(trimmed, "]") ||
+			strings.Count(trimmed, "(") > strings.Count(trimmed, ")") ||
+			strings.HasSuffix(trimmed, ",") {
+			continue
+		}
+
+		break
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if strings.Contains(declaration, "#{") {
+		return nil, nil
+	}
+
+	var requirements []string
+	for _, requirement := range quotedString.FindAllStringSubmatch(declaration, -1) {
+		requirements = append(requirements, requirement[1])
+	}
+
+	return requirements, nil
+}
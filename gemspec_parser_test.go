@@ -0,0 +1,198 @@
+package mri_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/mri"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testGemspecParser(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		workingDir string
+		buffer     *bytes.Buffer
+		parser     mri.GemspecParser
+	)
+
+	it.Before(func() {
+		var err error
+		workingDir, err = os.MkdirTemp("", "working-dir")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.WriteFile(filepath.Join(workingDir, "some-gem.gemspec"), []byte(`# frozen_string_literal: true
+
+Gem::Specification.new do |spec|
+  spec.name = "some-gem"
+  spec.version = SomeGem::VERSION
+  spec.required_ruby_version = ">= 3.2", "< 4.0" # keep in sync with CI
+  spec.add_dependency "rack", "~> 3.0"
+end
+`), 0600)).To(Succeed())
+
+		buffer = bytes.NewBuffer(nil)
+		parser = mri.NewGemspecParser(scribe.NewEmitter(buffer).WithLevel("DEBUG"))
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(workingDir)).To(Succeed())
+	})
+
+	context("ParseVersion", func() {
+		it("parses the required_ruby_version from the gemspec", func() {
+			version, err := parser.ParseVersion(workingDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal(">= 3.2, < 4.0"))
+		})
+
+		context("when the requirement uses the pessimistic operator", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "some-gem.gemspec"), []byte(`Gem::Specification.new do |s|
+  s.required_ruby_version = Gem::Requirement.new("~> 3.3")
+end
+`), 0600)).To(Succeed())
+			})
+
+			it("expands it into a range", func() {
+				version, err := parser.ParseVersion(workingDir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(Equal(">= 3.3, < 4.0"))
+			})
+		})
+
+		context("when the requirement spans multiple lines", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "some-gem.gemspec"), []byte(`Gem::Specification.new do |spec|
+  spec.required_ruby_version = [
+    ">= 3.1",
+    "!= 3.2.0",
+  ]
+  spec.summary = "[not a requirement]"
+end
+`), 0600)).To(Succeed())
+			})
+
+			it("collects every requirement", func() {
+				version, err := parser.ParseVersion(workingDir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(Equal(">= 3.1, != 3.2.0"))
+			})
+		})
+
+		context("when several gemspecs declare a requirement", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "other-gem.gemspec"), []byte(`Gem::Specification.new do |spec|
+  spec.required_ruby_version = ">= 3.3"
+end
+`), 0600)).To(Succeed())
+			})
+
+			it("requires all of them", func() {
+				version, err := parser.ParseVersion(workingDir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(Equal(">= 3.3, >= 3.2, < 4.0"))
+			})
+		})
+
+		context("when the requirement is computed at runtime", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "some-gem.gemspec"), []byte(`Gem::Specification.new do |spec|
+  spec.required_ruby_version = ">= #{File.read(".minimum-ruby").strip}"
+end
+`), 0600)).To(Succeed())
+			})
+
+			it("returns an empty version", func() {
+				version, err := parser.ParseVersion(workingDir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(BeEmpty())
+			})
+		})
+
+		context("when the gemspec does not declare a requirement", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "some-gem.gemspec"), []byte(`Gem::Specification.new do |spec|
+  spec.name = "some-gem"
+end
+`), 0600)).To(Succeed())
+			})
+
+			it("returns an empty version", func() {
+				version, err := parser.ParseVersion(workingDir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(BeEmpty())
+			})
+		})
+
+		context("when there is no gemspec", func() {
+			it.Before(func() {
+				Expect(os.Remove(filepath.Join(workingDir, "some-gem.gemspec"))).To(Succeed())
+			})
+
+			it("returns an empty version", func() {
+				version, err := parser.ParseVersion(workingDir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(BeEmpty())
+			})
+		})
+
+		context("when the requirement allows prereleases", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "some-gem.gemspec"), []byte(`Gem::Specification.new do |spec|
+  spec.required_ruby_version = ">= 3.1.0.a"
+end
+`), 0600)).To(Succeed())
+			})
+
+			it("converts the prerelease into its semver form", func() {
+				version, err := parser.ParseVersion(workingDir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(Equal(">= 3.1.0-a"))
+			})
+		})
+
+		context("when the gemspec cannot be read", func() {
+			it.Before(func() {
+				Expect(os.Chmod(filepath.Join(workingDir, "some-gem.gemspec"), 0000)).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Chmod(filepath.Join(workingDir, "some-gem.gemspec"), 0644)).To(Succeed())
+			})
+
+			it("ignores it", func() {
+				version, err := parser.ParseVersion(workingDir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(BeEmpty())
+				Expect(buffer.String()).To(ContainSubstring("permission denied"))
+			})
+		})
+
+		context("when the requirement is malformed", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "some-gem.gemspec"), []byte(`Gem::Specification.new do |spec|
+  spec.required_ruby_version = ">= three"
+end
+`), 0600)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workingDir, "other-gem.gemspec"), []byte(`Gem::Specification.new do |spec|
+  spec.required_ruby_version = ">= 3.2"
+end
+`), 0600)).To(Succeed())
+			})
+
+			it("ignores that gemspec", func() {
+				version, err := parser.ParseVersion(workingDir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(Equal(">= 3.2"))
+				Expect(buffer.String()).To(ContainSubstring(`Ignoring the required_ruby_version in %s: ">= three" is not a valid requirement`, filepath.Join(workingDir, "some-gem.gemspec")))
+			})
+		})
+	})
+}
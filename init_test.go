@@ -11,6 +11,31 @@ func TestUnitMRI(t *testing.T) {
 	suite := spec.New("mri", spec.Report(report.Terminal{}))
 	suite("BuildpackYMLParser", testBuildpackYMLParser)
 	suite("Detect", testDetect)
+	suite("DependencyCatalog", testDependencyCatalog)
+	suite("RubyVersionParser", testRubyVersionParser)
+	suite("GemfileParser", testGemfileParser)
+	suite("GemfileLockParser", testGemfileLockParser)
+	suite("ToolVersionsParser", testToolVersionsParser)
+	suite("MiseTOMLParser", testMiseTOMLParser)
+	suite("GemspecParser", testGemspecParser)
+	suite("UnsatisfiedVersionError", testUnsatisfiedVersionError)
+	suite("EOLPolicy", testEOLPolicy)
+	suite("YJITConfig", testYJITConfig)
+	suite("SlimLaunch", testSlimLaunch)
+	suite("StaticGemPath", testStaticGemPath)
+	suite("RubyVerifier", testRubyVerifier)
+	suite("CustomArtifact", testCustomArtifact)
+	suite("SourceCompiler", testSourceCompiler)
+	suite("InstallationFacts", testInstallationFacts)
+	suite("VersionResolution", testVersionResolution)
+	suite("BuildReport", testBuildReport)
+	suite("VersionChange", testVersionChange)
+	suite("CacheInputs", testCacheInputs)
+	suite("GemInventory", testGemInventory)
+	suite("InstallationSBOMGenerator", testInstallationSBOMGenerator)
+	suite("SBOMFormats", testSBOMFormats)
+	suite("Provenance", testProvenance)
+	suite("VEX", testVEX)
 	suite("Build", testBuild)
 	suite.Run(t)
 }
@@ -0,0 +1,89 @@
+package mri
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/paketo-buildpacks/mri/facts"
+	"github.com/paketo-buildpacks/packit/v2"
+)
+
+var rbConfigArch = regexp.MustCompile(`CONFIG\["arch"\]\s*=\s*"([^"]+)"`)
+
+// InstallationFacts derives the facts published to later buildpacks from the
+// MRI installation at the given layer path without running Ruby. Facts that
+// cannot be read from the installation are left empty.
+func InstallationFacts(layerPath, version string) (facts.Facts, error) {
+	abi, err := rubyABIVersion(layerPath)
+	if err != nil {
+		return facts.Facts{}, err
+	}
+
+	platform, err := rubyPlatform(layerPath, abi)
+	if err != nil {
+		return facts.Facts{}, err
+	}
+
+	return facts.Facts{
+		Version:    version,
+		ABIVersion: abi,
+		Home:       layerPath,
+		Platform:   platform,
+	}, nil
+}
+
+// rubyPlatform returns the platform (e.g. x86_64-linux) the installation was
+// built for. It is read from the arch recorded in rbconfig.rb, falling back to
+// the name of the directory that holds it.
+func rubyPlatform(layerPath, abi string) (string, error) {
+	if abi == "" {
+		return "", nil
+	}
+
+	rbconfigs, err := filepath.Glob(filepath.Join(layerPath, "lib", "ruby", abi, "*", "rbconfig.rb"))
+	if err != nil {
+		return "", err
+	}
+
+	if len(rbconfigs) == 0 {
+		return "", nil
+	}
+	sort.Strings(rbconfigs)
+
+	content, err := os.ReadFile(rbconfigs[0])
+	if err != nil {
+		return "", err
+	}
+
+	if matches := rbConfigArch.FindSubmatch(content); matches != nil {
+		return string(matches[1]), nil
+	}
+
+	return filepath.Base(filepath.Dir(rbconfigs[0])), nil
+}
+
+// publishFacts records the facts about the installation in the metadata of the
+// MRI layer and exposes them to later buildpacks through its build
+// environment.
+func publishFacts(layer *packit.Layer, version string) error {
+	installation, err := InstallationFacts(layer.Path, version)
+	if err != nil {
+		return err
+	}
+
+	if layer.Metadata == nil {
+		layer.Metadata = map[string]interface{}{}
+	}
+
+	for key, value := range installation.Metadata() {
+		layer.Metadata[key] = value
+	}
+
+	for name, value := range installation.Environment() {
+		layer.BuildEnv.Override(name, value)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package mri_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/mri"
+	"github.com/paketo-buildpacks/mri/facts"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testInstallationFacts(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		layerPath string
+	)
+
+	it.Before(func() {
+		var err error
+		layerPath, err = os.MkdirTemp("", "layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(layerPath, "lib", "ruby", "gems", "3.4.0"), os.ModePerm)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(layerPath, "lib", "ruby", "3.4.0", "aarch64-linux"), os.ModePerm)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(layerPath, "lib", "ruby", "3.4.0", "aarch64-linux", "rbconfig.rb"), []byte(`module RbConfig
+  CONFIG = {}
+  CONFIG["ruby_version"] = "3.4.0"
+  CONFIG["arch"] = "aarch64-linux-gnu"
+end
+`), 0600)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(layerPath)).To(Succeed())
+	})
+
+	it("reads the facts from the installation", func() {
+		installation, err := mri.InstallationFacts(layerPath, "3.4.8")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(installation).To(Equal(facts.Facts{
+			Version:    "3.4.8",
+			ABIVersion: "3.4.0",
+			Home:       layerPath,
+			Platform:   "aarch64-linux-gnu",
+		}))
+	})
+
+	context("when rbconfig.rb does not record the arch", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(layerPath, "lib", "ruby", "3.4.0", "aarch64-linux", "rbconfig.rb"), []byte("module RbConfig\nend\n"), 0600)).To(Succeed())
+		})
+
+		it("uses the name of its directory", func() {
+			installation, err := mri.InstallationFacts(layerPath, "3.4.8")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(installation.Platform).To(Equal("aarch64-linux"))
+		})
+	})
+
+	context("when the layout is not recognized", func() {
+		it.Before(func() {
+			Expect(os.RemoveAll(filepath.Join(layerPath, "lib"))).To(Succeed())
+		})
+
+		it("only returns the version and home", func() {
+			installation, err := mri.InstallationFacts(layerPath, "3.4.8")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(installation).To(Equal(facts.Facts{Version: "3.4.8", Home: layerPath}))
+		})
+	})
+
+	context("failure cases", func() {
+		context("when rbconfig.rb cannot be read", func() {
+			it.Before(func() {
+				Expect(os.Chmod(filepath.Join(layerPath, "lib", "ruby", "3.4.0", "aarch64-linux", "rbconfig.rb"), 0000)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := mri.InstallationFacts(layerPath, "3.4.8")
+				Expect(err).To(MatchError(ContainSubstring("permission denied")))
+			})
+		})
+	})
+}
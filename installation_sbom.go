@@ -0,0 +1,100 @@
+package mri
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+)
+
+// The media types of the SBOM formats that can be listed in the sbom-formats
+// of the buildpack.toml.
+const (
+	CycloneDXMediaType = "application/vnd.cyclonedx+json"
+	SPDXMediaType      = "application/spdx+json"
+	SyftMediaType      = "application/vnd.syft+json"
+)
+
+// InstallationSBOMGenerator describes an installed dependency together with
+// the default and bundled gems it ships, so that vulnerability scanners can
+// match the gems against their own advisories.
+type InstallationSBOMGenerator struct{}
+
+func NewInstallationSBOMGenerator() InstallationSBOMGenerator {
+	return InstallationSBOMGenerator{}
+}
+
+// GenerateFromDependency returns an SBOM listing the given dependency and a
+// component for each gem found in the installation at the given path.
+func (g InstallationSBOMGenerator) GenerateFromDependency(dependency postal.Dependency, path string) (InstallationSBOM, error) {
+	gems, err := GemInventory(path)
+	if err != nil {
+		return InstallationSBOM{}, err
+	}
+
+	return InstallationSBOM{
+		Dependency: dependency,
+		Path:       path,
+		Gems:       gems,
+	}, nil
+}
+
+// InstallationSBOM describes a dependency installed at a path and the gems it
+// ships. It is encoded without any external tooling so that the same
+// installation always results in byte-identical SBOMs.
+type InstallationSBOM struct {
+	Dependency postal.Dependency
+	Path       string
+	Gems       []Gem
+
+	// Vulnerabilities are the VEX statements about the dependency, which are
+	// only encoded in CycloneDX.
+	Vulnerabilities []VEXStatement
+}
+
+// InFormats encodes the SBOM in each of the given media types. CycloneDX is
+// encoded as version 1.5 and SPDX as version 2.3.
+func (s InstallationSBOM) InFormats(mediaTypes ...string) (packit.SBOMFormats, error) {
+	var formats packit.SBOMFormats
+	for _, mediaType := range mediaTypes {
+		baseType, params, err := mime.ParseMediaType(mediaType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse SBOM media type: %w", err)
+		}
+
+		var (
+			extension string
+			supported string
+			encode    func() ([]byte, error)
+		)
+
+		switch baseType {
+		case CycloneDXMediaType:
+			extension, supported, encode = "cdx.json", cycloneDXSpecVersion, s.cycloneDX
+		case SPDXMediaType:
+			extension, supported, encode = "spdx.json", spdxSpecVersion, s.spdx
+		case SyftMediaType:
+			extension, supported, encode = "syft.json", "", s.syft
+		default:
+			return nil, fmt.Errorf("unsupported SBOM format: '%s'", mediaType)
+		}
+
+		if version, ok := params["version"]; ok && supported != "" && version != supported {
+			return nil, fmt.Errorf("version '%s' is not supported for SBOM format '%s'", version, baseType)
+		}
+
+		content, err := encode()
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s SBOM: %w", baseType, err)
+		}
+
+		formats = append(formats, packit.SBOMFormat{
+			Extension: extension,
+			Content:   bytes.NewReader(content),
+		})
+	}
+
+	return formats, nil
+}
@@ -0,0 +1,146 @@
+package mri_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/mri"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testInstallationSBOMGenerator(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		layerPath  string
+		dependency postal.Dependency
+		generator  mri.InstallationSBOMGenerator
+	)
+
+	it.Before(func() {
+		var err error
+		layerPath, err = os.MkdirTemp("", "layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		specifications := filepath.Join(layerPath, "lib", "ruby", "gems", "3.4.0", "specifications", "default")
+		Expect(os.MkdirAll(specifications, os.ModePerm)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(specifications, "net-imap-0.5.8.gemspec"), []byte(`Gem::Specification.new do |s|
+  s.name = "net-imap".freeze
+  s.version = "0.5.8".freeze
+  s.licenses = ["Ruby".freeze, "BSD-2-Clause".freeze]
+end
+`), 0600)).To(Succeed())
+
+		dependency = postal.Dependency{
+			ID:      "ruby",
+			Name:    "Ruby",
+			Version: "3.4.8",
+		}
+
+		generator = mri.NewInstallationSBOMGenerator()
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(layerPath)).To(Succeed())
+	})
+
+	it("describes the dependency and the gems of the installation", func() {
+		bom, err := generator.GenerateFromDependency(dependency, layerPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bom).To(Equal(mri.InstallationSBOM{
+			Dependency: dependency,
+			Path:       layerPath,
+			Gems: []mri.Gem{
+				{Name: "net-imap", Version: "0.5.8", Licenses: []string{"Ruby", "BSD-2-Clause"}, Default: true},
+			},
+		}))
+	})
+
+	context("InFormats", func() {
+		it("encodes the SBOM in each of the given formats", func() {
+			bom, err := generator.GenerateFromDependency(dependency, layerPath)
+			Expect(err).NotTo(HaveOccurred())
+
+			formats, err := bom.InFormats(mri.CycloneDXMediaType, mri.SPDXMediaType, mri.SyftMediaType)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(formats.Formats()).To(HaveLen(3))
+
+			var extensions []string
+			for _, format := range formats.Formats() {
+				extensions = append(extensions, format.Extension)
+
+				content, err := io.ReadAll(format.Content)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("pkg:gem/net-imap@0.5.8"), format.Extension)
+			}
+			Expect(extensions).To(Equal([]string{"cdx.json", "spdx.json", "syft.json"}))
+		})
+
+		it("encodes the same SBOM to the same bytes", func() {
+			encode := func() []string {
+				bom, err := generator.GenerateFromDependency(dependency, layerPath)
+				Expect(err).NotTo(HaveOccurred())
+
+				formats, err := bom.InFormats(mri.CycloneDXMediaType, mri.SPDXMediaType, mri.SyftMediaType)
+				Expect(err).NotTo(HaveOccurred())
+
+				var contents []string
+				for _, format := range formats.Formats() {
+					content, err := io.ReadAll(format.Content)
+					Expect(err).NotTo(HaveOccurred())
+					contents = append(contents, string(content))
+				}
+
+				return contents
+			}
+
+			Expect(encode()).To(Equal(encode()))
+		})
+
+		it("accepts the versions it encodes", func() {
+			_, err := mri.InstallationSBOM{}.InFormats(mri.CycloneDXMediaType+";version=1.5", mri.SPDXMediaType+";version=2.3")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		context("failure cases", func() {
+			context("when the format is not supported", func() {
+				it("returns an error", func() {
+					_, err := mri.InstallationSBOM{}.InFormats("random-format")
+					Expect(err).To(MatchError("unsupported SBOM format: 'random-format'"))
+				})
+			})
+
+			context("when the version of the format is not supported", func() {
+				it("returns an error", func() {
+					_, err := mri.InstallationSBOM{}.InFormats(mri.CycloneDXMediaType + ";version=1.3")
+					Expect(err).To(MatchError("version '1.3' is not supported for SBOM format 'application/vnd.cyclonedx+json'"))
+				})
+			})
+
+			context("when the media type cannot be parsed", func() {
+				it("returns an error", func() {
+					_, err := mri.InstallationSBOM{}.InFormats("application/spdx+json;;")
+					Expect(err).To(MatchError(ContainSubstring("failed to parse SBOM media type")))
+				})
+			})
+		})
+	})
+
+	context("failure cases", func() {
+		context("when the gems cannot be listed", func() {
+			it.Before(func() {
+				Expect(os.Chmod(filepath.Join(layerPath, "lib", "ruby", "gems", "3.4.0", "specifications", "default", "net-imap-0.5.8.gemspec"), 0000)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := generator.GenerateFromDependency(dependency, layerPath)
+				Expect(err).To(MatchError(ContainSubstring("permission denied")))
+			})
+		})
+	})
+}
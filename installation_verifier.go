@@ -0,0 +1,232 @@
+package mri
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+)
+
+// ExpectedExtensions lists the standard library extensions that every MRI
+// installation is expected to be able to load.
+var ExpectedExtensions = []string{
+	"date",
+	"digest",
+	"io/console",
+	"json",
+	"openssl",
+	"psych",
+	"ripper",
+	"socket",
+	"zlib",
+}
+
+// rubyHostCPUs maps the architectures of the buildpack to the host_cpu that
+// RbConfig reports for them.
+var rubyHostCPUs = map[string]string{
+	"amd64": "x86_64",
+	"arm64": "aarch64",
+}
+
+const verificationScript = `
+require "json"
+require "rbconfig"
+
+extensions = ARGV.to_h do |extension|
+  begin
+    require extension
+    [extension, nil]
+  rescue LoadError => e
+    [extension, e.message]
+  end
+end
+
+puts JSON.generate(
+  "version" => RUBY_VERSION,
+  "host_cpu" => RbConfig::CONFIG["host_cpu"],
+  "prefix" => RbConfig::CONFIG["prefix"],
+  "extensions" => extensions,
+)
+`
+
+// LoadVerifyConfig reports whether $BP_MRI_VERIFY requests that the installed
+// interpreter be verified.
+func LoadVerifyConfig() (bool, error) {
+	value, ok := os.LookupEnv("BP_MRI_VERIFY")
+	if !ok {
+		return false, nil
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for $BP_MRI_VERIFY: %w", err)
+	}
+
+	return enabled, nil
+}
+
+// VerificationCheck is the outcome of a single verification of the installed
+// interpreter.
+type VerificationCheck struct {
+	Name     string
+	Expected string
+	Actual   string
+	Passed   bool
+}
+
+// VerificationReport collects the checks run against the installed
+// interpreter.
+type VerificationReport struct {
+	Checks []VerificationCheck
+}
+
+// Failed reports whether any of the checks failed.
+func (r VerificationReport) Failed() bool {
+	for _, check := range r.Checks {
+		if !check.Passed {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r VerificationReport) String() string {
+	var lines []string
+	for _, check := range r.Checks {
+		status := "PASS"
+		if !check.Passed {
+			status = "FAIL"
+		}
+
+		lines = append(lines, fmt.Sprintf("[%s] %s: expected %s, got %s", status, check.Name, check.Expected, check.Actual))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// VerificationError is returned when the installed interpreter fails one or
+// more checks.
+type VerificationError struct {
+	Version string
+	Report  VerificationReport
+}
+
+func (e VerificationError) Error() string {
+	lines := []string{fmt.Sprintf("MRI %s failed verification:", e.Version)}
+	for _, line := range strings.Split(e.Report.String(), "\n") {
+		lines = append(lines, "  "+line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// RubyVerifier verifies an MRI installation by running its ruby executable.
+type RubyVerifier struct {
+	ruby Executable
+}
+
+func NewRubyVerifier(ruby Executable) RubyVerifier {
+	return RubyVerifier{
+		ruby: ruby,
+	}
+}
+
+// Verify runs the ruby installed at the given layer path and checks that it
+// reports the version of the dependency, loads the expected standard library
+// extensions, was built for the target architecture and resolves its
+// installation prefix to the layer path, which shows that it is relocatable.
+func (v RubyVerifier) Verify(layerPath string, dependency postal.Dependency) (VerificationReport, error) {
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+	err := v.ruby.Execute(pexec.Execution{
+		Args:   append([]string{"-e", verificationScript}, ExpectedExtensions...),
+		Env:    append(os.Environ(), fmt.Sprintf("PATH=%s%c%s", filepath.Join(layerPath, "bin"), os.PathListSeparator, os.Getenv("PATH"))),
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+	if err != nil {
+		return VerificationReport{}, fmt.Errorf("failed to run ruby: %w\n%s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var output struct {
+		Version    string             `json:"version"`
+		HostCPU    string             `json:"host_cpu"`
+		Prefix     string             `json:"prefix"`
+		Extensions map[string]*string `json:"extensions"`
+	}
+	err = json.Unmarshal(stdout.Bytes(), &output)
+	if err != nil {
+		return VerificationReport{}, fmt.Errorf("failed to parse verification output: %w", err)
+	}
+
+	var report VerificationReport
+	report.Checks = append(report.Checks, VerificationCheck{
+		Name:     "version",
+		Expected: dependency.Version,
+		Actual:   output.Version,
+		Passed:   output.Version == dependency.Version,
+	})
+
+	for _, extension := range ExpectedExtensions {
+		check := VerificationCheck{
+			Name:     fmt.Sprintf("extension %s", extension),
+			Expected: "loaded",
+			Actual:   "loaded",
+			Passed:   true,
+		}
+
+		loadErr, ok := output.Extensions[extension]
+		if !ok {
+			check.Actual, check.Passed = "not checked", false
+		} else if loadErr != nil {
+			check.Actual, check.Passed = *loadErr, false
+		}
+
+		report.Checks = append(report.Checks, check)
+	}
+
+	arch := targetArch(dependency.Arch)
+	expectedCPU, ok := rubyHostCPUs[arch]
+	if !ok {
+		expectedCPU = arch
+	}
+
+	report.Checks = append(report.Checks, VerificationCheck{
+		Name:     "architecture",
+		Expected: expectedCPU,
+		Actual:   output.HostCPU,
+		Passed:   output.HostCPU == expectedCPU,
+	})
+
+	report.Checks = append(report.Checks, VerificationCheck{
+		Name:     "installation prefix",
+		Expected: layerPath,
+		Actual:   output.Prefix,
+		Passed:   samePath(output.Prefix, layerPath),
+	})
+
+	return report, nil
+}
+
+func samePath(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+
+	if resolved, err := filepath.EvalSymlinks(a); err == nil {
+		a = resolved
+	}
+
+	if resolved, err := filepath.EvalSymlinks(b); err == nil {
+		b = resolved
+	}
+
+	return filepath.Clean(a) == filepath.Clean(b)
+}
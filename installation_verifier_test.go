@@ -0,0 +1,131 @@
+package mri_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/mri"
+	"github.com/paketo-buildpacks/mri/fakes"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testRubyVerifier(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		layerPath  string
+		output     string
+		ruby       *fakes.Executable
+		dependency postal.Dependency
+		verifier   mri.RubyVerifier
+	)
+
+	it.Before(func() {
+		var err error
+		layerPath, err = os.MkdirTemp("", "layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		output = fmt.Sprintf(`{
+			"version": "3.4.8",
+			"host_cpu": "aarch64",
+			"prefix": %q,
+			"extensions": {"date": null, "digest": null, "io/console": null, "json": null, "openssl": null, "psych": null, "ripper": null, "socket": null, "zlib": null}
+		}`, layerPath)
+
+		ruby = &fakes.Executable{}
+		ruby.ExecuteCall.Stub = func(execution pexec.Execution) error {
+			_, err := fmt.Fprintln(execution.Stdout, output)
+			return err
+		}
+
+		dependency = postal.Dependency{Version: "3.4.8", Arch: "arm64"}
+
+		verifier = mri.NewRubyVerifier(ruby)
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(layerPath)).To(Succeed())
+	})
+
+	context("Verify", func() {
+		it("runs the installed ruby and checks the installation", func() {
+			report, err := verifier.Verify(layerPath, dependency)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Failed()).To(BeFalse())
+
+			Expect(report.Checks).To(ContainElements(
+				mri.VerificationCheck{Name: "version", Expected: "3.4.8", Actual: "3.4.8", Passed: true},
+				mri.VerificationCheck{Name: "extension openssl", Expected: "loaded", Actual: "loaded", Passed: true},
+				mri.VerificationCheck{Name: "architecture", Expected: "aarch64", Actual: "aarch64", Passed: true},
+				mri.VerificationCheck{Name: "installation prefix", Expected: layerPath, Actual: layerPath, Passed: true},
+			))
+			Expect(report.Checks).To(HaveLen(12))
+
+			execution := ruby.ExecuteCall.Receives.Execution
+			Expect(execution.Args[0]).To(Equal("-e"))
+			Expect(execution.Args[2:]).To(Equal(mri.ExpectedExtensions))
+			Expect(execution.Env).To(ContainElement(HavePrefix(fmt.Sprintf("PATH=%s:", filepath.Join(layerPath, "bin")))))
+		})
+
+		context("when the installation does not match the dependency", func() {
+			it.Before(func() {
+				output = `{
+					"version": "3.4.7",
+					"host_cpu": "x86_64",
+					"prefix": "/tmp/ruby-build",
+					"extensions": {"date": null, "digest": null, "io/console": null, "json": null, "openssl": "cannot load such file -- openssl", "psych": null, "ripper": null, "socket": null}
+				}`
+			})
+
+			it("reports the failed checks", func() {
+				report, err := verifier.Verify(layerPath, dependency)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(report.Failed()).To(BeTrue())
+
+				Expect(report.Checks).To(ContainElements(
+					mri.VerificationCheck{Name: "version", Expected: "3.4.8", Actual: "3.4.7", Passed: false},
+					mri.VerificationCheck{Name: "extension openssl", Expected: "loaded", Actual: "cannot load such file -- openssl", Passed: false},
+					mri.VerificationCheck{Name: "extension zlib", Expected: "loaded", Actual: "not checked", Passed: false},
+					mri.VerificationCheck{Name: "architecture", Expected: "aarch64", Actual: "x86_64", Passed: false},
+					mri.VerificationCheck{Name: "installation prefix", Expected: layerPath, Actual: "/tmp/ruby-build", Passed: false},
+				))
+				Expect(report.String()).To(ContainSubstring("[FAIL] version: expected 3.4.8, got 3.4.7"))
+				Expect(report.String()).To(ContainSubstring("[PASS] extension json: expected loaded, got loaded"))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when ruby cannot be run", func() {
+				it.Before(func() {
+					ruby.ExecuteCall.Stub = func(execution pexec.Execution) error {
+						_, _ = fmt.Fprintln(execution.Stderr, "exec format error")
+						return errors.New("exit status 1")
+					}
+				})
+
+				it("returns an error", func() {
+					_, err := verifier.Verify(layerPath, dependency)
+					Expect(err).To(MatchError("failed to run ruby: exit status 1\nexec format error"))
+				})
+			})
+
+			context("when the output cannot be parsed", func() {
+				it.Before(func() {
+					output = "%%%"
+				})
+
+				it("returns an error", func() {
+					_, err := verifier.Verify(layerPath, dependency)
+					Expect(err).To(MatchError(ContainSubstring("failed to parse verification output")))
+				})
+			})
+		})
+	})
+}
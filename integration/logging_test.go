@@ -86,12 +86,15 @@ func testLogging(t *testing.T, context spec.G, it spec.S) {
 				"  Configuring build environment",
 				MatchRegexp(fmt.Sprintf(`    GEM_PATH         -> "/home/cnb/.local/share/gem/ruby/3\.4\.\d+:/layers/%s/mri/lib/ruby/gems/3\.4\.\d+"`, strings.ReplaceAll(settings.Buildpack.ID, "/", "_"))),
 				`    MALLOC_ARENA_MAX -> "2"`,
+				`    MRI_ABI_VERSION  -> "3.4.0"`,
+				fmt.Sprintf(`    MRI_HOME         -> "/layers/%s/mri"`, strings.ReplaceAll(settings.Buildpack.ID, "/", "_")),
+				MatchRegexp(`    MRI_PLATFORM     -> "(x86_64|aarch64)-linux"`),
+				MatchRegexp(`    MRI_VERSION      -> "3\.4\.\d+"`),
 			))
 
 			Expect(logs).To(ContainLines(
 				"  Configuring launch environment",
-				MatchRegexp(fmt.Sprintf(`    GEM_PATH         -> "/home/cnb/.local/share/gem/ruby/3\.4\.\d+:/layers/%s/mri/lib/ruby/gems/3\.4\.\d+"`, strings.ReplaceAll(settings.Buildpack.ID, "/", "_"))),
-				`    MALLOC_ARENA_MAX -> "2"`,
+				MatchRegexp(fmt.Sprintf(`    GEM_PATH -> "/home/cnb/.local/share/gem/ruby/3\.4\.\d+:/layers/%s/mri/lib/ruby/gems/3\.4\.\d+"`, strings.ReplaceAll(settings.Buildpack.ID, "/", "_"))),
 			))
 		})
 
@@ -140,20 +143,19 @@ func testLogging(t *testing.T, context spec.G, it spec.S) {
 					MatchRegexp(`      Completed in ([0-9]*(\.[0-9]*)?[a-z]+)+`),
 				))
 
-				Expect(logs).To(ContainLines(
-					fmt.Sprintf("  Adding /layers/%s/mri/bin to the $PATH", strings.ReplaceAll(settings.Buildpack.ID, "/", "_")),
-				))
-
 				Expect(logs).To(ContainLines(
 					"  Configuring build environment",
 					MatchRegexp(fmt.Sprintf(`    GEM_PATH         -> "/home/cnb/.local/share/gem/ruby/3\.4\.\d+:/layers/%s/mri/lib/ruby/gems/3\.4\.\d+"`, strings.ReplaceAll(settings.Buildpack.ID, "/", "_"))),
 					`    MALLOC_ARENA_MAX -> "2"`,
+					`    MRI_ABI_VERSION  -> "3.4.0"`,
+					fmt.Sprintf(`    MRI_HOME         -> "/layers/%s/mri"`, strings.ReplaceAll(settings.Buildpack.ID, "/", "_")),
+					MatchRegexp(`    MRI_PLATFORM     -> "(x86_64|aarch64)-linux"`),
+					MatchRegexp(`    MRI_VERSION      -> "3\.4\.\d+"`),
 				))
 
 				Expect(logs).To(ContainLines(
 					"  Configuring launch environment",
-					MatchRegexp(fmt.Sprintf(`    GEM_PATH         -> "/home/cnb/.local/share/gem/ruby/3\.4\.\d+:/layers/%s/mri/lib/ruby/gems/3\.4\.\d+"`, strings.ReplaceAll(settings.Buildpack.ID, "/", "_"))),
-					`    MALLOC_ARENA_MAX -> "2"`,
+					MatchRegexp(fmt.Sprintf(`    GEM_PATH -> "/home/cnb/.local/share/gem/ruby/3\.4\.\d+:/layers/%s/mri/lib/ruby/gems/3\.4\.\d+"`, strings.ReplaceAll(settings.Buildpack.ID, "/", "_"))),
 				))
 			})
 		})
@@ -204,12 +206,15 @@ func testLogging(t *testing.T, context spec.G, it spec.S) {
 					"  Configuring build environment",
 					MatchRegexp(fmt.Sprintf(`    GEM_PATH         -> "/home/cnb/.local/share/gem/ruby/3\.4\.\d+:/layers/%s/mri/lib/ruby/gems/3\.4\.\d+"`, strings.ReplaceAll(settings.Buildpack.ID, "/", "_"))),
 					`    MALLOC_ARENA_MAX -> "2"`,
+					`    MRI_ABI_VERSION  -> "3.4.0"`,
+					fmt.Sprintf(`    MRI_HOME         -> "/layers/%s/mri"`, strings.ReplaceAll(settings.Buildpack.ID, "/", "_")),
+					MatchRegexp(`    MRI_PLATFORM     -> "(x86_64|aarch64)-linux"`),
+					MatchRegexp(`    MRI_VERSION      -> "3\.4\.\d+"`),
 				))
 
 				Expect(logs).To(ContainLines(
 					"  Configuring launch environment",
-					MatchRegexp(fmt.Sprintf(`    GEM_PATH         -> "/home/cnb/.local/share/gem/ruby/3\.4\.\d+:/layers/%s/mri/lib/ruby/gems/3\.4\.\d+"`, strings.ReplaceAll(settings.Buildpack.ID, "/", "_"))),
-					`    MALLOC_ARENA_MAX -> "2"`,
+					MatchRegexp(fmt.Sprintf(`    GEM_PATH -> "/home/cnb/.local/share/gem/ruby/3\.4\.\d+:/layers/%s/mri/lib/ruby/gems/3\.4\.\d+"`, strings.ReplaceAll(settings.Buildpack.ID, "/", "_"))),
 				))
 			})
 		})
@@ -107,12 +107,15 @@ func testReusingLayerRebuild(t *testing.T, context spec.G, it spec.S) {
 				"  Configuring build environment",
 				MatchRegexp(fmt.Sprintf(`    GEM_PATH         -> "/home/cnb/.local/share/gem/ruby/3\.4\.\d+:/layers/%s/mri/lib/ruby/gems/3\.4\.\d+"`, strings.ReplaceAll(settings.Buildpack.ID, "/", "_"))),
 				`    MALLOC_ARENA_MAX -> "2"`,
+				`    MRI_ABI_VERSION  -> "3.4.0"`,
+				fmt.Sprintf(`    MRI_HOME         -> "/layers/%s/mri"`, strings.ReplaceAll(settings.Buildpack.ID, "/", "_")),
+				MatchRegexp(`    MRI_PLATFORM     -> "(x86_64|aarch64)-linux"`),
+				MatchRegexp(`    MRI_VERSION      -> "3\.4\.\d+"`),
 			))
 
 			Expect(logs).To(ContainLines(
 				"  Configuring launch environment",
-				MatchRegexp(fmt.Sprintf(`    GEM_PATH         -> "/home/cnb/.local/share/gem/ruby/3\.4\.\d+:/layers/%s/mri/lib/ruby/gems/3\.4\.\d+"`, strings.ReplaceAll(settings.Buildpack.ID, "/", "_"))),
-				`    MALLOC_ARENA_MAX -> "2"`,
+				MatchRegexp(fmt.Sprintf(`    GEM_PATH -> "/home/cnb/.local/share/gem/ruby/3\.4\.\d+:/layers/%s/mri/lib/ruby/gems/3\.4\.\d+"`, strings.ReplaceAll(settings.Buildpack.ID, "/", "_"))),
 			))
 
 			firstContainer, err = docker.Container.Run.
@@ -225,12 +228,15 @@ func testReusingLayerRebuild(t *testing.T, context spec.G, it spec.S) {
 				"  Configuring build environment",
 				MatchRegexp(fmt.Sprintf(`    GEM_PATH         -> "/home/cnb/.local/share/gem/ruby/3\.4\.\d+:/layers/%s/mri/lib/ruby/gems/3\.4\.\d+"`, strings.ReplaceAll(settings.Buildpack.ID, "/", "_"))),
 				`    MALLOC_ARENA_MAX -> "2"`,
+				`    MRI_ABI_VERSION  -> "3.4.0"`,
+				fmt.Sprintf(`    MRI_HOME         -> "/layers/%s/mri"`, strings.ReplaceAll(settings.Buildpack.ID, "/", "_")),
+				MatchRegexp(`    MRI_PLATFORM     -> "(x86_64|aarch64)-linux"`),
+				MatchRegexp(`    MRI_VERSION      -> "3\.4\.\d+"`),
 			))
 
 			Expect(logs).To(ContainLines(
 				"  Configuring launch environment",
-				MatchRegexp(fmt.Sprintf(`    GEM_PATH         -> "/home/cnb/.local/share/gem/ruby/3\.4\.\d+:/layers/%s/mri/lib/ruby/gems/3\.4\.\d+"`, strings.ReplaceAll(settings.Buildpack.ID, "/", "_"))),
-				`    MALLOC_ARENA_MAX -> "2"`,
+				MatchRegexp(fmt.Sprintf(`    GEM_PATH -> "/home/cnb/.local/share/gem/ruby/3\.4\.\d+:/layers/%s/mri/lib/ruby/gems/3\.4\.\d+"`, strings.ReplaceAll(settings.Buildpack.ID, "/", "_"))),
 			))
 
 			firstContainer, err = docker.Container.Run.
@@ -278,12 +284,15 @@ func testReusingLayerRebuild(t *testing.T, context spec.G, it spec.S) {
 				"  Configuring build environment",
 				MatchRegexp(fmt.Sprintf(`    GEM_PATH         -> "/home/cnb/.local/share/gem/ruby/3\.3\.\d+:/layers/%s/mri/lib/ruby/gems/3\.3\.\d+"`, strings.ReplaceAll(settings.Buildpack.ID, "/", "_"))),
 				`    MALLOC_ARENA_MAX -> "2"`,
+				`    MRI_ABI_VERSION  -> "3.3.0"`,
+				fmt.Sprintf(`    MRI_HOME         -> "/layers/%s/mri"`, strings.ReplaceAll(settings.Buildpack.ID, "/", "_")),
+				MatchRegexp(`    MRI_PLATFORM     -> "(x86_64|aarch64)-linux"`),
+				MatchRegexp(`    MRI_VERSION      -> "3\.3\.\d+"`),
 			))
 
 			Expect(logs).To(ContainLines(
 				"  Configuring launch environment",
-				MatchRegexp(fmt.Sprintf(`    GEM_PATH         -> "/home/cnb/.local/share/gem/ruby/3\.3\.\d+:/layers/%s/mri/lib/ruby/gems/3\.3\.\d+"`, strings.ReplaceAll(settings.Buildpack.ID, "/", "_"))),
-				`    MALLOC_ARENA_MAX -> "2"`,
+				MatchRegexp(fmt.Sprintf(`    GEM_PATH -> "/home/cnb/.local/share/gem/ruby/3\.3\.\d+:/layers/%s/mri/lib/ruby/gems/3\.3\.\d+"`, strings.ReplaceAll(settings.Buildpack.ID, "/", "_"))),
 			))
 
 			secondContainer, err = docker.Container.Run.
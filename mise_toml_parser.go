@@ -0,0 +1,63 @@
+package mri
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/BurntSushi/toml"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+type MiseTOMLParser struct {
+	logger scribe.Emitter
+}
+
+func NewMiseTOMLParser(logger scribe.Emitter) MiseTOMLParser {
+	return MiseTOMLParser{
+		logger: logger,
+	}
+}
+
+// ParseVersion reads the ruby entry of the [tools] table in a mise.toml file
+// and returns it as a semver constraint. mise accepts a plain string, a list
+// of fallback versions or a table with a version key, with the same version
+// forms as asdf. Versions that do not name an MRI version are logged and
+// ignored.
+func (p MiseTOMLParser) ParseVersion(path string) (string, error) {
+	var config struct {
+		Tools map[string]interface{} `toml:"tools"`
+	}
+
+	_, err := toml.DecodeFile(path, &config)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	var version string
+	switch tool := config.Tools["ruby"].(type) {
+	case string:
+		version = tool
+	case []interface{}:
+		if len(tool) > 0 {
+			version, _ = tool[0].(string)
+		}
+	case map[string]interface{}:
+		version, _ = tool["version"].(string)
+	}
+
+	if version == "" {
+		return "", nil
+	}
+
+	version, err = normalizeToolVersion(version)
+	if err != nil {
+		p.logger.Debug.Process("Ignoring the ruby tool in %s: %s", path, err)
+		p.logger.Debug.Break()
+		return "", nil
+	}
+
+	return version, nil
+}
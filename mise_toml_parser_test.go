@@ -0,0 +1,172 @@
+package mri_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/mri"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testMiseTOMLParser(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		workingDir string
+		path       string
+		buffer     *bytes.Buffer
+		parser     mri.MiseTOMLParser
+	)
+
+	it.Before(func() {
+		var err error
+		workingDir, err = os.MkdirTemp("", "working-dir")
+		Expect(err).NotTo(HaveOccurred())
+
+		path = filepath.Join(workingDir, "mise.toml")
+		Expect(os.WriteFile(path, []byte(`[env]
+RAILS_ENV = "production"
+
+[tools]
+node = "20"
+ruby = "3.4"
+`), 0600)).To(Succeed())
+
+		buffer = bytes.NewBuffer(nil)
+		parser = mri.NewMiseTOMLParser(scribe.NewEmitter(buffer).WithLevel("DEBUG"))
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(workingDir)).To(Succeed())
+	})
+
+	context("ParseVersion", func() {
+		it("parses the ruby version from the tools table", func() {
+			version, err := parser.ParseVersion(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal("3.4.*"))
+		})
+
+		context("when the ruby tool lists fallback versions", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte("[tools]\nruby = [\"3.4.1\", \"3.3\"]\n"), 0600)).To(Succeed())
+			})
+
+			it("returns the first version", func() {
+				version, err := parser.ParseVersion(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(Equal("3.4.1"))
+			})
+		})
+
+		context("when the ruby tool is a table", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte("[tools]\nruby = { version = \"ruby-3.3.6\" }\n"), 0600)).To(Succeed())
+			})
+
+			it("returns the version key", func() {
+				version, err := parser.ParseVersion(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(Equal("3.3.6"))
+			})
+		})
+
+		context("when the ruby tool requests the latest version", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte("[tools]\nruby = \"latest\"\n"), 0600)).To(Succeed())
+			})
+
+			it("returns a wildcard constraint", func() {
+				version, err := parser.ParseVersion(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(Equal("*"))
+			})
+		})
+
+		context("when the ruby tool requests a version prefix", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte("[tools]\nruby = \"prefix:3.3\"\n"), 0600)).To(Succeed())
+			})
+
+			it("returns a wildcard constraint", func() {
+				version, err := parser.ParseVersion(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(Equal("3.3.*"))
+			})
+		})
+
+		context("when the ruby tool does not name an MRI version", func() {
+			for tool, reason := range map[string]string{
+				`ruby = "ref:master"`: `"ref:master" does not name an MRI version`,
+				`ruby = "three"`:      `"three" is not a valid MRI version`,
+			} {
+				it("ignores "+tool, func() {
+					Expect(os.WriteFile(path, []byte("[tools]\n"+tool+"\n"), 0600)).To(Succeed())
+
+					version, err := parser.ParseVersion(path)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(version).To(BeEmpty())
+					Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("Ignoring the ruby tool in %s: %s", path, reason)))
+				})
+			}
+		})
+
+		context("when the file has no ruby tool", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte("[tools]\nnode = \"20\"\n"), 0600)).To(Succeed())
+			})
+
+			it("returns an empty version", func() {
+				version, err := parser.ParseVersion(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(BeEmpty())
+			})
+		})
+
+		context("when the mise.toml file does not exist", func() {
+			it.Before(func() {
+				Expect(os.Remove(path)).To(Succeed())
+			})
+
+			it("returns an empty version", func() {
+				version, err := parser.ParseVersion(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(BeEmpty())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the mise.toml file cannot be read", func() {
+				it.Before(func() {
+					Expect(os.Chmod(path, 0000)).To(Succeed())
+				})
+
+				it.After(func() {
+					Expect(os.Chmod(path, 0644)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := parser.ParseVersion(path)
+					Expect(err).To(MatchError(ContainSubstring("permission denied")))
+				})
+			})
+
+			context("when the contents of the mise.toml file are malformed", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(path, []byte("%%%"), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := parser.ParseVersion(path)
+					Expect(err).To(MatchError(ContainSubstring("failed to parse")))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,250 @@
+package mri
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/cargo"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+)
+
+// ProvenanceFile is the name of the provenance statement in the
+// mri-provenance layer.
+const ProvenanceFile = "provenance.json"
+
+// The build types of the provenance statement, which tell whether the
+// buildpack installed a prebuilt artifact or compiled MRI itself.
+const (
+	InstallBuildType = "https://paketo.io/buildpacks/mri/install/v1"
+	CompileBuildType = "https://paketo.io/buildpacks/mri/compile/v1"
+)
+
+// LoadProvenanceConfig reports whether $BP_MRI_PROVENANCE requests that a
+// provenance statement for the installed MRI be written.
+func LoadProvenanceConfig() (bool, error) {
+	value, ok := os.LookupEnv("BP_MRI_PROVENANCE")
+	if !ok {
+		return false, nil
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for $BP_MRI_PROVENANCE: %w", err)
+	}
+
+	return enabled, nil
+}
+
+// Provenance is an in-toto statement carrying a SLSA v1 provenance predicate
+// that links the installed MRI to the artifact and source it came from. It
+// records no timestamps, so the same installation always results in the
+// same statement.
+type Provenance struct {
+	Type          string              `json:"_type"`
+	Subject       []ProvenanceSubject `json:"subject"`
+	PredicateType string              `json:"predicateType"`
+	Predicate     ProvenancePredicate `json:"predicate"`
+}
+
+// ProvenanceSubject is an artifact the provenance statement is about.
+type ProvenanceSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// ProvenancePredicate describes how the subjects were produced.
+type ProvenancePredicate struct {
+	BuildDefinition ProvenanceBuildDefinition `json:"buildDefinition"`
+	RunDetails      ProvenanceRunDetails      `json:"runDetails"`
+}
+
+// ProvenanceBuildDefinition describes the inputs of the build.
+type ProvenanceBuildDefinition struct {
+	BuildType            string                         `json:"buildType"`
+	ExternalParameters   ProvenanceParameters           `json:"externalParameters"`
+	ResolvedDependencies []ProvenanceResourceDescriptor `json:"resolvedDependencies"`
+}
+
+// ProvenanceParameters are the MRI version and the target it was built for.
+// ConfigureFlags are only set when MRI was compiled from source.
+type ProvenanceParameters struct {
+	Version        string           `json:"version"`
+	Origin         string           `json:"origin"`
+	Target         ProvenanceTarget `json:"target"`
+	ConfigureFlags []string         `json:"configure-flags,omitempty"`
+}
+
+// ProvenanceTarget is the platform MRI was built for.
+type ProvenanceTarget struct {
+	Stack string `json:"stack"`
+	OS    string `json:"os"`
+	Arch  string `json:"arch"`
+}
+
+// ProvenanceResourceDescriptor is an artifact that the build consumed.
+type ProvenanceResourceDescriptor struct {
+	Name   string            `json:"name"`
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+// ProvenanceRunDetails identifies the buildpack that installed MRI.
+type ProvenanceRunDetails struct {
+	Builder ProvenanceBuilder `json:"builder"`
+}
+
+// ProvenanceBuilder is the buildpack and its version.
+type ProvenanceBuilder struct {
+	ID      string            `json:"id"`
+	Version map[string]string `json:"version"`
+}
+
+// NewProvenance describes how the given dependency, installed at the given
+// layer path, was obtained. The subjects are the artifact that was installed,
+// when its checksum is known, and the ruby executable of the installation.
+func NewProvenance(context packit.BuildContext, dependency postal.Dependency, origin string, configureFlags []string, layerPath string) (Provenance, error) {
+	provenance := Provenance{
+		Type:          "https://in-toto.io/Statement/v1",
+		Subject:       []ProvenanceSubject{},
+		PredicateType: "https://slsa.dev/provenance/v1",
+		Predicate: ProvenancePredicate{
+			BuildDefinition: ProvenanceBuildDefinition{
+				BuildType: InstallBuildType,
+				ExternalParameters: ProvenanceParameters{
+					Version: dependency.Version,
+					Origin:  origin,
+					Target: ProvenanceTarget{
+						Stack: context.Stack,
+						OS:    dependency.OS,
+						Arch:  targetArch(dependency.Arch),
+					},
+				},
+				ResolvedDependencies: []ProvenanceResourceDescriptor{},
+			},
+			RunDetails: ProvenanceRunDetails{
+				Builder: ProvenanceBuilder{
+					ID:      context.BuildpackInfo.Homepage,
+					Version: map[string]string{context.BuildpackInfo.ID: context.BuildpackInfo.Version},
+				},
+			},
+		},
+	}
+
+	if provenance.Predicate.RunDetails.Builder.ID == "" {
+		provenance.Predicate.RunDetails.Builder.ID = context.BuildpackInfo.ID
+	}
+
+	if provenance.Predicate.BuildDefinition.ExternalParameters.Target.OS == "" {
+		provenance.Predicate.BuildDefinition.ExternalParameters.Target.OS = "linux"
+	}
+
+	if origin == CompiledSourceOrigin {
+		provenance.Predicate.BuildDefinition.BuildType = CompileBuildType
+		provenance.Predicate.BuildDefinition.ExternalParameters.ConfigureFlags = configureFlags
+	} else {
+		checksum := dependency.Checksum
+		//nolint Ignore SA1019, informed usage of deprecated field
+		if checksum == "" && dependency.SHA256 != "" {
+			//nolint Ignore SA1019, informed usage of deprecated field
+			checksum = "sha256:" + dependency.SHA256
+		}
+
+		if dependency.URI != "" && checksum != "" {
+			provenance.Subject = append(provenance.Subject, ProvenanceSubject{
+				Name:   dependency.URI,
+				Digest: provenanceDigest(cargo.Checksum(checksum)),
+			})
+		}
+	}
+
+	ruby, err := fileDigest(filepath.Join(layerPath, "bin", "ruby"))
+	if err != nil && !os.IsNotExist(err) {
+		return Provenance{}, fmt.Errorf("failed to digest the ruby executable: %w", err)
+	}
+
+	if ruby != "" {
+		provenance.Subject = append(provenance.Subject, ProvenanceSubject{
+			Name:   "bin/ruby",
+			Digest: map[string]string{"sha256": ruby},
+		})
+	}
+
+	if dependency.Source != "" {
+		sourceChecksum := dependency.SourceChecksum
+		//nolint Ignore SA1019, informed usage of deprecated field
+		if sourceChecksum == "" && dependency.SourceSHA256 != "" {
+			//nolint Ignore SA1019, informed usage of deprecated field
+			sourceChecksum = "sha256:" + dependency.SourceSHA256
+		}
+
+		source := ProvenanceResourceDescriptor{Name: "source", URI: dependency.Source}
+		if sourceChecksum != "" {
+			source.Digest = provenanceDigest(cargo.Checksum(sourceChecksum))
+		}
+
+		provenance.Predicate.BuildDefinition.ResolvedDependencies = append(provenance.Predicate.BuildDefinition.ResolvedDependencies, source)
+	}
+
+	return provenance, nil
+}
+
+func provenanceDigest(checksum cargo.Checksum) map[string]string {
+	return map[string]string{checksum.Algorithm(): checksum.Hash()}
+}
+
+// fileDigest returns the hex-encoded SHA-256 of the file at the given path.
+func fileDigest(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to close file: %v\n", err)
+		}
+	}()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+// writeProvenance writes the provenance statement into its own launch layer,
+// where policy engines inspecting the image look for it. The statement names
+// the buildpack version that ran, which changes on buildpack upgrades even
+// when the cached MRI layer is reused as is.
+func writeProvenance(context packit.BuildContext, provenance Provenance) (packit.Layer, error) {
+	layer, err := context.Layers.Get(MRIProvenance)
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	layer, err = layer.Reset()
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	layer.Launch = true
+
+	content, err := json.MarshalIndent(provenance, "", "  ")
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	err = os.WriteFile(filepath.Join(layer.Path, ProvenanceFile), append(content, '\n'), 0644)
+	if err != nil {
+		return packit.Layer{}, fmt.Errorf("failed to write provenance: %w", err)
+	}
+
+	return layer, nil
+}
@@ -0,0 +1,182 @@
+package mri_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/mri"
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testProvenance(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		layerPath    string
+		buildContext packit.BuildContext
+		dependency   postal.Dependency
+	)
+
+	it.Before(func() {
+		var err error
+		layerPath, err = os.MkdirTemp("", "layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(layerPath, "bin"), os.ModePerm)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(layerPath, "bin", "ruby"), []byte("some-ruby"), 0755)).To(Succeed())
+
+		t.Setenv("CNB_TARGET_ARCH", "amd64")
+
+		buildContext = packit.BuildContext{
+			BuildpackInfo: packit.BuildpackInfo{
+				ID:       "paketo-buildpacks/mri",
+				Version:  "1.2.3",
+				Homepage: "https://github.com/paketo-buildpacks/mri",
+			},
+			Stack: "io.buildpacks.stacks.jammy",
+		}
+
+		dependency = postal.Dependency{
+			ID:             "ruby",
+			Name:           "Ruby",
+			Version:        "3.4.8",
+			URI:            "https://example.com/ruby_3.4.8_linux_x64_jammy.tgz",
+			Checksum:       "sha256:some-sha",
+			Source:         "https://cache.ruby-lang.org/pub/ruby/3.4/ruby-3.4.8.tar.gz",
+			SourceChecksum: "sha256:some-source-sha",
+		}
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(layerPath)).To(Succeed())
+	})
+
+	context("LoadProvenanceConfig", func() {
+		it("is disabled by default", func() {
+			enabled, err := mri.LoadProvenanceConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(enabled).To(BeFalse())
+		})
+
+		context("when BP_MRI_PROVENANCE is set", func() {
+			it.Before(func() {
+				t.Setenv("BP_MRI_PROVENANCE", "true")
+			})
+
+			it("is enabled", func() {
+				enabled, err := mri.LoadProvenanceConfig()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(enabled).To(BeTrue())
+			})
+		})
+
+		context("when BP_MRI_PROVENANCE is invalid", func() {
+			it.Before(func() {
+				t.Setenv("BP_MRI_PROVENANCE", "some-bad-value")
+			})
+
+			it("returns an error", func() {
+				_, err := mri.LoadProvenanceConfig()
+				Expect(err).To(MatchError(ContainSubstring("invalid value for $BP_MRI_PROVENANCE")))
+			})
+		})
+	})
+
+	context("NewProvenance", func() {
+		it("links the installed artifact to its source", func() {
+			provenance, err := mri.NewProvenance(buildContext, dependency, mri.BuildpackTOMLOrigin, nil, layerPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(provenance).To(Equal(mri.Provenance{
+				Type: "https://in-toto.io/Statement/v1",
+				Subject: []mri.ProvenanceSubject{
+					{Name: "https://example.com/ruby_3.4.8_linux_x64_jammy.tgz", Digest: map[string]string{"sha256": "some-sha"}},
+					{Name: "bin/ruby", Digest: map[string]string{"sha256": "71a579e3c8430ea4624fe6b177853387467a643f195847145989faeb77d27f97"}},
+				},
+				PredicateType: "https://slsa.dev/provenance/v1",
+				Predicate: mri.ProvenancePredicate{
+					BuildDefinition: mri.ProvenanceBuildDefinition{
+						BuildType: mri.InstallBuildType,
+						ExternalParameters: mri.ProvenanceParameters{
+							Version: "3.4.8",
+							Origin:  "buildpack.toml",
+							Target: mri.ProvenanceTarget{
+								Stack: "io.buildpacks.stacks.jammy",
+								OS:    "linux",
+								Arch:  "amd64",
+							},
+						},
+						ResolvedDependencies: []mri.ProvenanceResourceDescriptor{
+							{Name: "source", URI: "https://cache.ruby-lang.org/pub/ruby/3.4/ruby-3.4.8.tar.gz", Digest: map[string]string{"sha256": "some-source-sha"}},
+						},
+					},
+					RunDetails: mri.ProvenanceRunDetails{
+						Builder: mri.ProvenanceBuilder{
+							ID:      "https://github.com/paketo-buildpacks/mri",
+							Version: map[string]string{"paketo-buildpacks/mri": "1.2.3"},
+						},
+					},
+				},
+			}))
+		})
+
+		context("when MRI was compiled from source", func() {
+			it("records the compile build type and configure flags", func() {
+				provenance, err := mri.NewProvenance(buildContext, dependency, mri.CompiledSourceOrigin, []string{"--enable-yjit"}, layerPath)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(provenance.Predicate.BuildDefinition.BuildType).To(Equal(mri.CompileBuildType))
+				Expect(provenance.Predicate.BuildDefinition.ExternalParameters.ConfigureFlags).To(Equal([]string{"--enable-yjit"}))
+				Expect(provenance.Subject).To(HaveLen(1))
+				Expect(provenance.Subject[0].Name).To(Equal("bin/ruby"))
+				Expect(provenance.Predicate.BuildDefinition.ResolvedDependencies).To(HaveLen(1))
+			})
+		})
+
+		context("when the artifact has no checksum and no source", func() {
+			it.Before(func() {
+				dependency.Checksum = ""
+				dependency.Source = ""
+				dependency.SourceChecksum = ""
+			})
+
+			it("only records the ruby executable", func() {
+				provenance, err := mri.NewProvenance(buildContext, dependency, mri.CustomArtifactOrigin, nil, layerPath)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(provenance.Subject).To(HaveLen(1))
+				Expect(provenance.Subject[0].Name).To(Equal("bin/ruby"))
+				Expect(provenance.Predicate.BuildDefinition.ResolvedDependencies).To(BeEmpty())
+			})
+		})
+
+		context("when the buildpack has no homepage", func() {
+			it.Before(func() {
+				buildContext.BuildpackInfo.Homepage = ""
+			})
+
+			it("identifies the builder by the buildpack ID", func() {
+				provenance, err := mri.NewProvenance(buildContext, dependency, mri.BuildpackTOMLOrigin, nil, layerPath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(provenance.Predicate.RunDetails.Builder.ID).To(Equal("paketo-buildpacks/mri"))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the ruby executable cannot be read", func() {
+				it.Before(func() {
+					Expect(os.Chmod(filepath.Join(layerPath, "bin", "ruby"), 0000)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := mri.NewProvenance(buildContext, dependency, mri.BuildpackTOMLOrigin, nil, layerPath)
+					Expect(err).To(MatchError(ContainSubstring("failed to digest the ruby executable")))
+				})
+			})
+		})
+	})
+}
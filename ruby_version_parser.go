@@ -0,0 +1,94 @@
+package mri
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var (
+	rubyVersionPattern = regexp.MustCompile(`^(\d+)(?:\.(\d+))?(?:\.(\d+))?(-[0-9A-Za-z.]+)?$`)
+	patchLevelPattern  = regexp.MustCompile(`-?p\d+$`)
+)
+
+// nonMRIEngines are the interpreter prefixes understood by rbenv, chruby and
+// friends that do not refer to MRI. Version files pinning one of these are
+// not meant for this buildpack.
+var nonMRIEngines = []string{"jruby", "truffleruby", "mruby", "rbx", "maglev", "ree", "system"}
+
+type RubyVersionParser struct{}
+
+func NewRubyVersionParser() RubyVersionParser {
+	return RubyVersionParser{}
+}
+
+// ParseVersion reads a .ruby-version file as written by rbenv, chruby or
+// rvm and returns the pinned MRI version as a semver constraint.
+func (p RubyVersionParser) ParseVersion(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to close file: %v\n", err)
+		}
+	}()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		version, err := normalizeRubyVersion(line)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		return version, nil
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return "", nil
+}
+
+// normalizeRubyVersion converts the version formats accepted by Ruby version
+// managers (e.g. "ruby-3.3.4", "3.3.4p94", "3.3") into a semver constraint.
+// Partial versions become wildcards so that the newest matching patch is
+// selected. An empty string is returned for interpreters other than MRI.
+func normalizeRubyVersion(raw string) (string, error) {
+	version := strings.TrimSpace(raw)
+	version = strings.TrimPrefix(version, "ruby-")
+
+	for _, engine := range nonMRIEngines {
+		if version == engine || strings.HasPrefix(version, engine+"-") {
+			return "", nil
+		}
+	}
+
+	version = patchLevelPattern.ReplaceAllString(version, "")
+
+	matches := rubyVersionPattern.FindStringSubmatch(version)
+	if matches == nil {
+		return "", fmt.Errorf("%q is not a valid MRI version", raw)
+	}
+
+	switch {
+	case matches[2] == "":
+		return fmt.Sprintf("%s.*", matches[1]), nil
+	case matches[3] == "":
+		return fmt.Sprintf("%s.%s.*", matches[1], matches[2]), nil
+	default:
+		return version, nil
+	}
+}
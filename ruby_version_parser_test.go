@@ -0,0 +1,118 @@
+package mri_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/mri"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testRubyVersionParser(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		workingDir string
+		path       string
+		parser     mri.RubyVersionParser
+	)
+
+	it.Before(func() {
+		var err error
+		workingDir, err = os.MkdirTemp("", "working-dir")
+		Expect(err).NotTo(HaveOccurred())
+
+		path = filepath.Join(workingDir, ".ruby-version")
+		Expect(os.WriteFile(path, []byte("3.3.4\n"), 0600)).To(Succeed())
+
+		parser = mri.NewRubyVersionParser()
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(workingDir)).To(Succeed())
+	})
+
+	context("ParseVersion", func() {
+		it("parses the mri version from a .ruby-version file", func() {
+			version, err := parser.ParseVersion(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal("3.3.4"))
+		})
+
+		context("when the file uses a version manager format", func() {
+			for raw, expected := range map[string]string{
+				"ruby-3.3.4":               "3.3.4",
+				"3.3.4p94":                 "3.3.4",
+				"ruby-3.3.4-p94":           "3.3.4",
+				"3.3":                      "3.3.*",
+				"ruby-3":                   "3.*",
+				"3.4.0-preview1":           "3.4.0-preview1",
+				"  3.2.2  ":                "3.2.2",
+				"# pinned\n\nruby-3.4.1\n": "3.4.1",
+			} {
+				it("normalizes "+raw+" into a semver constraint", func() {
+					Expect(os.WriteFile(path, []byte(raw), 0600)).To(Succeed())
+
+					version, err := parser.ParseVersion(path)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(version).To(Equal(expected))
+				})
+			}
+		})
+
+		context("when the file pins an interpreter other than MRI", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte("jruby-9.4.5.0"), 0600)).To(Succeed())
+			})
+
+			it("returns an empty version", func() {
+				version, err := parser.ParseVersion(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(BeEmpty())
+			})
+		})
+
+		context("when the .ruby-version file does not exist", func() {
+			it.Before(func() {
+				Expect(os.Remove(path)).To(Succeed())
+			})
+
+			it("returns an empty version", func() {
+				version, err := parser.ParseVersion(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(BeEmpty())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the .ruby-version file cannot be read", func() {
+				it.Before(func() {
+					Expect(os.Chmod(path, 0000)).To(Succeed())
+				})
+
+				it.After(func() {
+					Expect(os.Chmod(path, 0644)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := parser.ParseVersion(path)
+					Expect(err).To(MatchError(ContainSubstring("permission denied")))
+				})
+			})
+
+			context("when the version is malformed", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(path, []byte("not-a-version"), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := parser.ParseVersion(path)
+					Expect(err).To(MatchError(ContainSubstring(`"not-a-version" is not a valid MRI version`)))
+				})
+			})
+		})
+	})
+}
@@ -10,26 +10,31 @@ import (
 	"github.com/paketo-buildpacks/packit/v2/draft"
 	"github.com/paketo-buildpacks/packit/v2/pexec"
 	"github.com/paketo-buildpacks/packit/v2/postal"
-	"github.com/paketo-buildpacks/packit/v2/sbom"
 	"github.com/paketo-buildpacks/packit/v2/scribe"
+	"github.com/paketo-buildpacks/packit/v2/servicebindings"
 )
 
-type Generator struct{}
-
-func (f Generator) GenerateFromDependency(dependency postal.Dependency, path string) (sbom.SBOM, error) {
-	return sbom.GenerateFromDependency(dependency, path)
-}
-
 func main() {
 	logger := scribe.NewEmitter(os.Stdout).WithLevel(os.Getenv("BP_LOG_LEVEL"))
 
 	packit.Run(
-		mri.Detect(mri.NewBuildpackYMLParser()),
+		mri.Detect(
+			mri.NewBuildpackYMLParser(),
+			mri.NewRubyVersionParser(),
+			mri.NewGemfileParser(logger),
+			mri.NewGemfileLockParser(logger),
+			mri.NewToolVersionsParser(logger),
+			mri.NewMiseTOMLParser(logger),
+			mri.NewGemspecParser(logger),
+		),
 		mri.Build(
 			draft.NewPlanner(),
 			postal.NewService(cargo.NewTransport()),
+			servicebindings.NewResolver(),
+			mri.NewSourceCompiler(cargo.NewTransport(), pexec.NewExecutable("configure"), pexec.NewExecutable("make")),
 			pexec.NewExecutable("gem"),
-			Generator{},
+			mri.NewInstallationSBOMGenerator(),
+			mri.NewRubyVerifier(pexec.NewExecutable("ruby")),
 			logger,
 			chronos.DefaultClock,
 		),
@@ -0,0 +1,668 @@
+package mri
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/cargo"
+)
+
+const (
+	cycloneDXSpecVersion = "1.5"
+	spdxSpecVersion      = "2.3"
+	syftSchemaVersion    = "16.1.10"
+
+	// sbomTool names the tool that generated the SBOM.
+	sbomTool = "paketo-buildpacks/mri"
+
+	// sbomCreated is the creation time recorded in SPDX documents, which
+	// require one. It is fixed, as the lifecycle does for the creation time of
+	// images, so that the SBOM only changes when the installation does.
+	sbomCreated = "1980-01-01T00:00:01Z"
+
+	// unknownCPE is the CPE recorded for dependencies that declare none. It
+	// uses the NA logical operator for every component so that it never
+	// matches, as in packit.
+	unknownCPE = "cpe:2.3:-:-:-:-:-:-:-:-:-:-:-"
+)
+
+var (
+	spdxLicenseIdentifier = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.+-]*$`)
+	spdxInvalidCharacters = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+)
+
+// sbomPackage is a package listed in the SBOM, independent of its format.
+type sbomPackage struct {
+	ID       string
+	Name     string
+	Version  string
+	Type     string
+	Language string
+	PURL     string
+	CPEs     []string
+	Licenses []string
+
+	// LicenseOperator joins the licenses into an SPDX license expression.
+	LicenseOperator string
+
+	Checksum       cargo.Checksum
+	URI            string
+	Source         string
+	SourceChecksum cargo.Checksum
+}
+
+// licenseExpression returns the SPDX license expression of the package, or
+// NOASSERTION when it has no licenses or one of them is not an identifier.
+func (p sbomPackage) licenseExpression() string {
+	if len(p.Licenses) == 0 {
+		return "NOASSERTION"
+	}
+
+	for _, license := range p.Licenses {
+		if !spdxLicenseIdentifier.MatchString(license) {
+			return "NOASSERTION"
+		}
+	}
+
+	expression := strings.Join(p.Licenses, fmt.Sprintf(" %s ", p.LicenseOperator))
+	if len(p.Licenses) > 1 {
+		expression = fmt.Sprintf("(%s)", expression)
+	}
+
+	return expression
+}
+
+// packages returns the dependency followed by the gems it ships.
+func (s InstallationSBOM) packages() []sbomPackage {
+	cpes := s.Dependency.CPEs
+	if len(cpes) == 0 {
+		//nolint Ignore SA1019, informed usage of deprecated field
+		cpes = []string{s.Dependency.CPE}
+	}
+
+	var dependencyCPEs []string
+	for _, cpe := range cpes {
+		if cpe == "" {
+			cpe = unknownCPE
+		}
+		dependencyCPEs = append(dependencyCPEs, cpe)
+	}
+
+	checksum := s.Dependency.Checksum
+	//nolint Ignore SA1019, informed usage of deprecated field
+	if checksum == "" && s.Dependency.SHA256 != "" {
+		//nolint Ignore SA1019, informed usage of deprecated field
+		checksum = "sha256:" + s.Dependency.SHA256
+	}
+
+	sourceChecksum := s.Dependency.SourceChecksum
+	//nolint Ignore SA1019, informed usage of deprecated field
+	if sourceChecksum == "" && s.Dependency.SourceSHA256 != "" {
+		//nolint Ignore SA1019, informed usage of deprecated field
+		sourceChecksum = "sha256:" + s.Dependency.SourceSHA256
+	}
+
+	packages := []sbomPackage{
+		{
+			Name:            s.Dependency.Name,
+			Version:         s.Dependency.Version,
+			Type:            "binary",
+			PURL:            s.Dependency.PURL,
+			CPEs:            dependencyCPEs,
+			Licenses:        s.Dependency.Licenses,
+			LicenseOperator: "AND",
+			Checksum:        cargo.Checksum(checksum),
+			URI:             s.Dependency.URI,
+			Source:          s.Dependency.Source,
+			SourceChecksum:  cargo.Checksum(sourceChecksum),
+		},
+	}
+
+	// RubyGems treats several licenses as a choice between them.
+	for _, gem := range s.Gems {
+		packages = append(packages, sbomPackage{
+			Name:            gem.Name,
+			Version:         gem.Version,
+			Type:            "gem",
+			Language:        "ruby",
+			PURL:            gem.PURL(),
+			Licenses:        gem.Licenses,
+			LicenseOperator: "OR",
+		})
+	}
+
+	for i := range packages {
+		packages[i].ID = sbomDigest(packages[i].Type, packages[i].Name, packages[i].Version)[:16]
+	}
+
+	return packages
+}
+
+// sbomDigest returns the hex-encoded SHA-256 of the given values, which
+// derives stable identifiers from the contents of the SBOM.
+func sbomDigest(values ...string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(values, "\x00"))))
+}
+
+// sbomIdentity returns the values that identify the whole SBOM.
+func (s InstallationSBOM) sbomIdentity() []string {
+	identity := []string{s.Path}
+	for _, p := range s.packages() {
+		identity = append(identity, p.ID, string(p.Checksum))
+	}
+
+	return identity
+}
+
+func encodeSBOM(document interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(document)
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+type cycloneDXBOM struct {
+	Schema          string                   `json:"$schema"`
+	BOMFormat       string                   `json:"bomFormat"`
+	SpecVersion     string                   `json:"specVersion"`
+	SerialNumber    string                   `json:"serialNumber"`
+	Version         int                      `json:"version"`
+	Metadata        cycloneDXMetadata        `json:"metadata"`
+	Components      []cycloneDXComponent     `json:"components"`
+	Vulnerabilities []cycloneDXVulnerability `json:"vulnerabilities,omitempty"`
+}
+
+type cycloneDXMetadata struct {
+	Tools     cycloneDXTools      `json:"tools"`
+	Component *cycloneDXComponent `json:"component,omitempty"`
+}
+
+type cycloneDXTools struct {
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXComponent struct {
+	BOMRef             string                       `json:"bom-ref,omitempty"`
+	Type               string                       `json:"type"`
+	Name               string                       `json:"name"`
+	Version            string                       `json:"version,omitempty"`
+	Hashes             []cycloneDXHash              `json:"hashes,omitempty"`
+	Licenses           []cycloneDXLicenseChoice     `json:"licenses,omitempty"`
+	CPE                string                       `json:"cpe,omitempty"`
+	PURL               string                       `json:"purl,omitempty"`
+	ExternalReferences []cycloneDXExternalReference `json:"externalReferences,omitempty"`
+	Properties         []cycloneDXProperty          `json:"properties,omitempty"`
+}
+
+type cycloneDXHash struct {
+	Algorithm string `json:"alg"`
+	Content   string `json:"content"`
+}
+
+type cycloneDXLicenseChoice struct {
+	License cycloneDXLicense `json:"license"`
+}
+
+type cycloneDXLicense struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+type cycloneDXExternalReference struct {
+	URL    string          `json:"url"`
+	Type   string          `json:"type"`
+	Hashes []cycloneDXHash `json:"hashes,omitempty"`
+}
+
+type cycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cycloneDXVulnerability struct {
+	ID       string                        `json:"id"`
+	Source   *cycloneDXVulnerabilitySource `json:"source,omitempty"`
+	Analysis cycloneDXAnalysis             `json:"analysis"`
+	Affects  []cycloneDXAffect             `json:"affects"`
+}
+
+type cycloneDXVulnerabilitySource struct {
+	URL string `json:"url"`
+}
+
+type cycloneDXAnalysis struct {
+	State         string `json:"state"`
+	Justification string `json:"justification,omitempty"`
+	Detail        string `json:"detail,omitempty"`
+}
+
+type cycloneDXAffect struct {
+	Ref string `json:"ref"`
+}
+
+func cycloneDXHashes(checksum cargo.Checksum) []cycloneDXHash {
+	algorithms := map[string]string{
+		"md5":    "MD5",
+		"sha1":   "SHA-1",
+		"sha256": "SHA-256",
+		"sha384": "SHA-384",
+		"sha512": "SHA-512",
+	}
+
+	algorithm, ok := algorithms[checksum.Algorithm()]
+	if checksum == "" || !ok {
+		return nil
+	}
+
+	return []cycloneDXHash{{Algorithm: algorithm, Content: checksum.Hash()}}
+}
+
+func (s InstallationSBOM) cycloneDX() ([]byte, error) {
+	bom := cycloneDXBOM{
+		Schema:       "http://cyclonedx.org/schema/bom-1.5.schema.json",
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  cycloneDXSpecVersion,
+		SerialNumber: "urn:uuid:" + sbomUUID(s.sbomIdentity()),
+		Version:      1,
+		Metadata: cycloneDXMetadata{
+			Tools: cycloneDXTools{
+				Components: []cycloneDXComponent{{Type: "application", Name: sbomTool}},
+			},
+		},
+		Components: []cycloneDXComponent{},
+	}
+
+	if s.Path != "" {
+		bom.Metadata.Component = &cycloneDXComponent{
+			BOMRef: sbomDigest(s.Path)[:16],
+			Type:   "file",
+			Name:   s.Path,
+		}
+	}
+
+	for i, p := range s.packages() {
+		component := cycloneDXComponent{
+			BOMRef:  p.ID,
+			Type:    "library",
+			Name:    p.Name,
+			Version: p.Version,
+			Hashes:  cycloneDXHashes(p.Checksum),
+			PURL:    p.PURL,
+		}
+
+		if i == 0 {
+			component.Type = "application"
+		}
+
+		for _, license := range p.Licenses {
+			if spdxLicenseIdentifier.MatchString(license) {
+				component.Licenses = append(component.Licenses, cycloneDXLicenseChoice{License: cycloneDXLicense{ID: license}})
+			} else {
+				component.Licenses = append(component.Licenses, cycloneDXLicenseChoice{License: cycloneDXLicense{Name: license}})
+			}
+		}
+
+		for j, cpe := range p.CPEs {
+			if j == 0 {
+				component.CPE = cpe
+				continue
+			}
+			component.Properties = append(component.Properties, cycloneDXProperty{Name: "syft:cpe23", Value: cpe})
+		}
+
+		if p.URI != "" {
+			component.ExternalReferences = append(component.ExternalReferences, cycloneDXExternalReference{
+				URL:    p.URI,
+				Type:   "distribution",
+				Hashes: cycloneDXHashes(p.Checksum),
+			})
+		}
+
+		if p.Source != "" {
+			component.ExternalReferences = append(component.ExternalReferences, cycloneDXExternalReference{
+				URL:    p.Source,
+				Type:   "source-distribution",
+				Hashes: cycloneDXHashes(p.SourceChecksum),
+			})
+		}
+
+		bom.Components = append(bom.Components, component)
+	}
+
+	// The VEX statements are about the dependency, which is the first
+	// component.
+	for _, statement := range s.Vulnerabilities {
+		vulnerability := cycloneDXVulnerability{
+			ID: statement.ID,
+			Analysis: cycloneDXAnalysis{
+				State:         statement.State,
+				Justification: statement.Justification,
+				Detail:        statement.Detail,
+			},
+			Affects: []cycloneDXAffect{{Ref: bom.Components[0].BOMRef}},
+		}
+
+		if statement.Source != "" {
+			vulnerability.Source = &cycloneDXVulnerabilitySource{URL: statement.Source}
+		}
+
+		bom.Vulnerabilities = append(bom.Vulnerabilities, vulnerability)
+	}
+
+	return encodeSBOM(bom)
+}
+
+// sbomUUID formats a digest of the given values as a version 4 UUID, so that
+// the serial number of the same SBOM never changes.
+func sbomUUID(values []string) string {
+	digest := sha256.Sum256([]byte(strings.Join(values, "\x00")))
+	digest[6] = (digest[6] & 0x0f) | 0x40
+	digest[8] = (digest[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", digest[0:4], digest[4:6], digest[6:8], digest[8:10], digest[10:16])
+}
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name                  string            `json:"name"`
+	SPDXID                string            `json:"SPDXID"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	Supplier              string            `json:"supplier"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	Checksums             []spdxChecksum    `json:"checksums,omitempty"`
+	SourceInfo            string            `json:"sourceInfo,omitempty"`
+	LicenseConcluded      string            `json:"licenseConcluded"`
+	LicenseDeclared       string            `json:"licenseDeclared"`
+	CopyrightText         string            `json:"copyrightText"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs,omitempty"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+func spdxChecksums(checksum cargo.Checksum) []spdxChecksum {
+	algorithms := map[string]string{
+		"md5":    "MD5",
+		"sha1":   "SHA1",
+		"sha256": "SHA256",
+		"sha384": "SHA384",
+		"sha512": "SHA512",
+	}
+
+	algorithm, ok := algorithms[checksum.Algorithm()]
+	if checksum == "" || !ok {
+		return nil
+	}
+
+	return []spdxChecksum{{Algorithm: algorithm, ChecksumValue: checksum.Hash()}}
+}
+
+func (s InstallationSBOM) spdx() ([]byte, error) {
+	name := s.Path
+	if name == "" {
+		name = "unknown"
+	}
+
+	document := spdxDocument{
+		SPDXVersion:       "SPDX-" + spdxSpecVersion,
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              name,
+		DocumentNamespace: fmt.Sprintf("https://paketo.io/spdx/mri/%s", sbomUUID(s.sbomIdentity())),
+		CreationInfo: spdxCreationInfo{
+			Created:  sbomCreated,
+			Creators: []string{"Organization: Paketo Buildpacks", "Tool: " + sbomTool},
+		},
+		Packages:      []spdxPackage{},
+		Relationships: []spdxRelationship{},
+	}
+
+	var dependencyID string
+	for i, p := range s.packages() {
+		id := spdxInvalidCharacters.ReplaceAllString(fmt.Sprintf("SPDXRef-Package-%s-%s-%s", p.Type, p.Name, p.ID), "-")
+
+		pkg := spdxPackage{
+			Name:                  p.Name,
+			SPDXID:                id,
+			VersionInfo:           p.Version,
+			Supplier:              "NOASSERTION",
+			DownloadLocation:      "NOASSERTION",
+			Checksums:             spdxChecksums(p.Checksum),
+			LicenseConcluded:      "NOASSERTION",
+			LicenseDeclared:       p.licenseExpression(),
+			CopyrightText:         "NOASSERTION",
+			PrimaryPackagePurpose: "LIBRARY",
+		}
+
+		if p.URI != "" {
+			pkg.DownloadLocation = p.URI
+		}
+
+		if p.Source != "" {
+			pkg.SourceInfo = fmt.Sprintf("built from %s", p.Source)
+			if p.SourceChecksum != "" {
+				pkg.SourceInfo = fmt.Sprintf("built from %s (%s:%s)", p.Source, p.SourceChecksum.Algorithm(), p.SourceChecksum.Hash())
+			}
+		}
+
+		for _, cpe := range p.CPEs {
+			pkg.ExternalRefs = append(pkg.ExternalRefs, spdxExternalRef{
+				ReferenceCategory: "SECURITY",
+				ReferenceType:     "cpe23Type",
+				ReferenceLocator:  cpe,
+			})
+		}
+
+		if p.PURL != "" {
+			pkg.ExternalRefs = append(pkg.ExternalRefs, spdxExternalRef{
+				ReferenceCategory: "PACKAGE-MANAGER",
+				ReferenceType:     "purl",
+				ReferenceLocator:  p.PURL,
+			})
+		}
+
+		if i == 0 {
+			dependencyID = id
+			pkg.PrimaryPackagePurpose = "APPLICATION"
+			document.Relationships = append(document.Relationships, spdxRelationship{
+				SPDXElementID:      document.SPDXID,
+				RelationshipType:   "DESCRIBES",
+				RelatedSPDXElement: id,
+			})
+		} else {
+			document.Relationships = append(document.Relationships, spdxRelationship{
+				SPDXElementID:      dependencyID,
+				RelationshipType:   "CONTAINS",
+				RelatedSPDXElement: id,
+			})
+		}
+
+		document.Packages = append(document.Packages, pkg)
+	}
+
+	return encodeSBOM(document)
+}
+
+type syftDocument struct {
+	Artifacts             []syftArtifact     `json:"artifacts"`
+	ArtifactRelationships []syftRelationship `json:"artifactRelationships"`
+	Source                syftSource         `json:"source"`
+	Distro                struct{}           `json:"distro"`
+	Descriptor            syftDescriptor     `json:"descriptor"`
+	Schema                syftSchema         `json:"schema"`
+}
+
+type syftArtifact struct {
+	ID           string        `json:"id"`
+	Name         string        `json:"name"`
+	Version      string        `json:"version"`
+	Type         string        `json:"type"`
+	FoundBy      string        `json:"foundBy"`
+	Locations    []struct{}    `json:"locations"`
+	Licenses     []syftLicense `json:"licenses"`
+	Language     string        `json:"language"`
+	CPEs         []syftCPE     `json:"cpes"`
+	PURL         string        `json:"purl"`
+	MetadataType string        `json:"metadataType,omitempty"`
+	Metadata     *syftGemspec  `json:"metadata,omitempty"`
+}
+
+type syftLicense struct {
+	Value          string     `json:"value"`
+	SPDXExpression string     `json:"spdxExpression"`
+	Type           string     `json:"type"`
+	URLs           []string   `json:"urls"`
+	Locations      []struct{} `json:"locations"`
+}
+
+type syftCPE struct {
+	CPE    string `json:"cpe"`
+	Source string `json:"source"`
+}
+
+type syftGemspec struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type syftRelationship struct {
+	Parent string `json:"parent"`
+	Child  string `json:"child"`
+	Type   string `json:"type"`
+}
+
+type syftSource struct {
+	ID       string             `json:"id"`
+	Name     string             `json:"name"`
+	Version  string             `json:"version"`
+	Type     string             `json:"type"`
+	Metadata syftSourceMetadata `json:"metadata"`
+}
+
+type syftSourceMetadata struct {
+	Path string `json:"path"`
+}
+
+type syftDescriptor struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type syftSchema struct {
+	Version string `json:"version"`
+	URL     string `json:"url"`
+}
+
+func (s InstallationSBOM) syft() ([]byte, error) {
+	document := syftDocument{
+		Artifacts:             []syftArtifact{},
+		ArtifactRelationships: []syftRelationship{},
+		Source: syftSource{
+			ID:       sbomDigest(s.Path),
+			Name:     s.Path,
+			Type:     "directory",
+			Metadata: syftSourceMetadata{Path: s.Path},
+		},
+		Descriptor: syftDescriptor{Name: sbomTool},
+		Schema: syftSchema{
+			Version: syftSchemaVersion,
+			URL:     fmt.Sprintf("https://raw.githubusercontent.com/anchore/syft/main/schema/json/schema-%s.json", syftSchemaVersion),
+		},
+	}
+
+	var dependencyID string
+	for i, p := range s.packages() {
+		artifact := syftArtifact{
+			ID:        p.ID,
+			Name:      p.Name,
+			Version:   p.Version,
+			Type:      p.Type,
+			FoundBy:   sbomTool,
+			Locations: []struct{}{},
+			Licenses:  []syftLicense{},
+			Language:  p.Language,
+			CPEs:      []syftCPE{},
+			PURL:      p.PURL,
+		}
+
+		for _, license := range p.Licenses {
+			expression := ""
+			if spdxLicenseIdentifier.MatchString(license) {
+				expression = license
+			}
+
+			artifact.Licenses = append(artifact.Licenses, syftLicense{
+				Value:          license,
+				SPDXExpression: expression,
+				Type:           "declared",
+				URLs:           []string{},
+				Locations:      []struct{}{},
+			})
+		}
+
+		for _, cpe := range p.CPEs {
+			artifact.CPEs = append(artifact.CPEs, syftCPE{CPE: cpe, Source: "declared"})
+		}
+
+		if p.Type == "gem" {
+			artifact.MetadataType = "ruby-gemspec"
+			artifact.Metadata = &syftGemspec{Name: p.Name, Version: p.Version}
+		}
+
+		if i == 0 {
+			dependencyID = p.ID
+		} else {
+			document.ArtifactRelationships = append(document.ArtifactRelationships, syftRelationship{
+				Parent: dependencyID,
+				Child:  p.ID,
+				Type:   "contains",
+			})
+		}
+
+		document.Artifacts = append(document.Artifacts, artifact)
+	}
+
+	return encodeSBOM(document)
+}
@@ -0,0 +1,443 @@
+package mri_test
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/paketo-buildpacks/mri"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testSBOMFormats(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		bom mri.InstallationSBOM
+	)
+
+	it.Before(func() {
+		bom = mri.InstallationSBOM{
+			Dependency: postal.Dependency{
+				ID:             "ruby",
+				Name:           "Ruby",
+				Version:        "3.4.8",
+				CPEs:           []string{"cpe:2.3:a:ruby-lang:ruby:3.4.8:*:*:*:*:*:*:*"},
+				PURL:           "pkg:generic/ruby@3.4.8?checksum=some-source-sha&download_url=https://cache.ruby-lang.org/pub/ruby/3.4/ruby-3.4.8.tar.gz",
+				Licenses:       []string{"BSD-2-Clause", "Ruby"},
+				Checksum:       "sha256:some-sha",
+				URI:            "https://artifacts.example.com/ruby_3.4.8_linux_x64_jammy.tgz",
+				Source:         "https://cache.ruby-lang.org/pub/ruby/3.4/ruby-3.4.8.tar.gz",
+				SourceChecksum: "sha256:some-source-sha",
+			},
+			Path: "/layers/paketo-buildpacks_mri/mri",
+			Gems: []mri.Gem{
+				{Name: "net-imap", Version: "0.5.8", Licenses: []string{"Ruby", "BSD-2-Clause"}, Default: true},
+				{Name: "rexml", Version: "3.4.0"},
+			},
+		}
+	})
+
+	encode := func(mediaType string) string {
+		formats, err := bom.InFormats(mediaType)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(formats).To(HaveLen(1))
+
+		content, err := io.ReadAll(formats[0].Content)
+		Expect(err).NotTo(HaveOccurred())
+
+		return string(content)
+	}
+
+	it("encodes CycloneDX 1.5", func() {
+		Expect(encode(mri.CycloneDXMediaType)).To(MatchJSON(`{
+			"$schema": "http://cyclonedx.org/schema/bom-1.5.schema.json",
+			"bomFormat": "CycloneDX",
+			"specVersion": "1.5",
+			"serialNumber": "urn:uuid:2399b124-18cc-4184-a5c9-2bea1708fa90",
+			"version": 1,
+			"metadata": {
+				"tools": {
+					"components": [
+						{
+							"type": "application",
+							"name": "paketo-buildpacks/mri"
+						}
+					]
+				},
+				"component": {
+					"bom-ref": "6f4e51fce9ebd5f6",
+					"type": "file",
+					"name": "/layers/paketo-buildpacks_mri/mri"
+				}
+			},
+			"components": [
+				{
+					"bom-ref": "5a792641004816b8",
+					"type": "application",
+					"name": "Ruby",
+					"version": "3.4.8",
+					"hashes": [
+						{
+							"alg": "SHA-256",
+							"content": "some-sha"
+						}
+					],
+					"licenses": [
+						{
+							"license": {
+								"id": "BSD-2-Clause"
+							}
+						},
+						{
+							"license": {
+								"id": "Ruby"
+							}
+						}
+					],
+					"cpe": "cpe:2.3:a:ruby-lang:ruby:3.4.8:*:*:*:*:*:*:*",
+					"purl": "pkg:generic/ruby@3.4.8?checksum=some-source-sha&download_url=https://cache.ruby-lang.org/pub/ruby/3.4/ruby-3.4.8.tar.gz",
+					"externalReferences": [
+						{
+							"url": "https://artifacts.example.com/ruby_3.4.8_linux_x64_jammy.tgz",
+							"type": "distribution",
+							"hashes": [
+								{
+									"alg": "SHA-256",
+									"content": "some-sha"
+								}
+							]
+						},
+						{
+							"url": "https://cache.ruby-lang.org/pub/ruby/3.4/ruby-3.4.8.tar.gz",
+							"type": "source-distribution",
+							"hashes": [
+								{
+									"alg": "SHA-256",
+									"content": "some-source-sha"
+								}
+							]
+						}
+					]
+				},
+				{
+					"bom-ref": "7688c43904e9898a",
+					"type": "library",
+					"name": "net-imap",
+					"version": "0.5.8",
+					"licenses": [
+						{
+							"license": {
+								"id": "Ruby"
+							}
+						},
+						{
+							"license": {
+								"id": "BSD-2-Clause"
+							}
+						}
+					],
+					"purl": "pkg:gem/net-imap@0.5.8"
+				},
+				{
+					"bom-ref": "64aadaef2504b82e",
+					"type": "library",
+					"name": "rexml",
+					"version": "3.4.0",
+					"purl": "pkg:gem/rexml@3.4.0"
+				}
+			]
+		}`))
+	})
+
+	it("encodes SPDX 2.3", func() {
+		Expect(encode(mri.SPDXMediaType)).To(MatchJSON(`{
+			"spdxVersion": "SPDX-2.3",
+			"dataLicense": "CC0-1.0",
+			"SPDXID": "SPDXRef-DOCUMENT",
+			"name": "/layers/paketo-buildpacks_mri/mri",
+			"documentNamespace": "https://paketo.io/spdx/mri/2399b124-18cc-4184-a5c9-2bea1708fa90",
+			"creationInfo": {
+				"created": "1980-01-01T00:00:01Z",
+				"creators": [
+					"Organization: Paketo Buildpacks",
+					"Tool: paketo-buildpacks/mri"
+				]
+			},
+			"packages": [
+				{
+					"name": "Ruby",
+					"SPDXID": "SPDXRef-Package-binary-Ruby-5a792641004816b8",
+					"versionInfo": "3.4.8",
+					"supplier": "NOASSERTION",
+					"downloadLocation": "https://artifacts.example.com/ruby_3.4.8_linux_x64_jammy.tgz",
+					"filesAnalyzed": false,
+					"checksums": [
+						{
+							"algorithm": "SHA256",
+							"checksumValue": "some-sha"
+						}
+					],
+					"sourceInfo": "built from https://cache.ruby-lang.org/pub/ruby/3.4/ruby-3.4.8.tar.gz (sha256:some-source-sha)",
+					"licenseConcluded": "NOASSERTION",
+					"licenseDeclared": "(BSD-2-Clause AND Ruby)",
+					"copyrightText": "NOASSERTION",
+					"externalRefs": [
+						{
+							"referenceCategory": "SECURITY",
+							"referenceType": "cpe23Type",
+							"referenceLocator": "cpe:2.3:a:ruby-lang:ruby:3.4.8:*:*:*:*:*:*:*"
+						},
+						{
+							"referenceCategory": "PACKAGE-MANAGER",
+							"referenceType": "purl",
+							"referenceLocator": "pkg:generic/ruby@3.4.8?checksum=some-source-sha&download_url=https://cache.ruby-lang.org/pub/ruby/3.4/ruby-3.4.8.tar.gz"
+						}
+					],
+					"primaryPackagePurpose": "APPLICATION"
+				},
+				{
+					"name": "net-imap",
+					"SPDXID": "SPDXRef-Package-gem-net-imap-7688c43904e9898a",
+					"versionInfo": "0.5.8",
+					"supplier": "NOASSERTION",
+					"downloadLocation": "NOASSERTION",
+					"filesAnalyzed": false,
+					"licenseConcluded": "NOASSERTION",
+					"licenseDeclared": "(Ruby OR BSD-2-Clause)",
+					"copyrightText": "NOASSERTION",
+					"externalRefs": [
+						{
+							"referenceCategory": "PACKAGE-MANAGER",
+							"referenceType": "purl",
+							"referenceLocator": "pkg:gem/net-imap@0.5.8"
+						}
+					],
+					"primaryPackagePurpose": "LIBRARY"
+				},
+				{
+					"name": "rexml",
+					"SPDXID": "SPDXRef-Package-gem-rexml-64aadaef2504b82e",
+					"versionInfo": "3.4.0",
+					"supplier": "NOASSERTION",
+					"downloadLocation": "NOASSERTION",
+					"filesAnalyzed": false,
+					"licenseConcluded": "NOASSERTION",
+					"licenseDeclared": "NOASSERTION",
+					"copyrightText": "NOASSERTION",
+					"externalRefs": [
+						{
+							"referenceCategory": "PACKAGE-MANAGER",
+							"referenceType": "purl",
+							"referenceLocator": "pkg:gem/rexml@3.4.0"
+						}
+					],
+					"primaryPackagePurpose": "LIBRARY"
+				}
+			],
+			"relationships": [
+				{
+					"spdxElementId": "SPDXRef-DOCUMENT",
+					"relationshipType": "DESCRIBES",
+					"relatedSpdxElement": "SPDXRef-Package-binary-Ruby-5a792641004816b8"
+				},
+				{
+					"spdxElementId": "SPDXRef-Package-binary-Ruby-5a792641004816b8",
+					"relationshipType": "CONTAINS",
+					"relatedSpdxElement": "SPDXRef-Package-gem-net-imap-7688c43904e9898a"
+				},
+				{
+					"spdxElementId": "SPDXRef-Package-binary-Ruby-5a792641004816b8",
+					"relationshipType": "CONTAINS",
+					"relatedSpdxElement": "SPDXRef-Package-gem-rexml-64aadaef2504b82e"
+				}
+			]
+		}`))
+	})
+
+	it("encodes Syft JSON", func() {
+		Expect(encode(mri.SyftMediaType)).To(MatchJSON(`{
+			"artifacts": [
+				{
+					"id": "5a792641004816b8",
+					"name": "Ruby",
+					"version": "3.4.8",
+					"type": "binary",
+					"foundBy": "paketo-buildpacks/mri",
+					"locations": [],
+					"licenses": [
+						{
+							"value": "BSD-2-Clause",
+							"spdxExpression": "BSD-2-Clause",
+							"type": "declared",
+							"urls": [],
+							"locations": []
+						},
+						{
+							"value": "Ruby",
+							"spdxExpression": "Ruby",
+							"type": "declared",
+							"urls": [],
+							"locations": []
+						}
+					],
+					"language": "",
+					"cpes": [
+						{
+							"cpe": "cpe:2.3:a:ruby-lang:ruby:3.4.8:*:*:*:*:*:*:*",
+							"source": "declared"
+						}
+					],
+					"purl": "pkg:generic/ruby@3.4.8?checksum=some-source-sha&download_url=https://cache.ruby-lang.org/pub/ruby/3.4/ruby-3.4.8.tar.gz"
+				},
+				{
+					"id": "7688c43904e9898a",
+					"name": "net-imap",
+					"version": "0.5.8",
+					"type": "gem",
+					"foundBy": "paketo-buildpacks/mri",
+					"locations": [],
+					"licenses": [
+						{
+							"value": "Ruby",
+							"spdxExpression": "Ruby",
+							"type": "declared",
+							"urls": [],
+							"locations": []
+						},
+						{
+							"value": "BSD-2-Clause",
+							"spdxExpression": "BSD-2-Clause",
+							"type": "declared",
+							"urls": [],
+							"locations": []
+						}
+					],
+					"language": "ruby",
+					"cpes": [],
+					"purl": "pkg:gem/net-imap@0.5.8",
+					"metadataType": "ruby-gemspec",
+					"metadata": {
+						"name": "net-imap",
+						"version": "0.5.8"
+					}
+				},
+				{
+					"id": "64aadaef2504b82e",
+					"name": "rexml",
+					"version": "3.4.0",
+					"type": "gem",
+					"foundBy": "paketo-buildpacks/mri",
+					"locations": [],
+					"licenses": [],
+					"language": "ruby",
+					"cpes": [],
+					"purl": "pkg:gem/rexml@3.4.0",
+					"metadataType": "ruby-gemspec",
+					"metadata": {
+						"name": "rexml",
+						"version": "3.4.0"
+					}
+				}
+			],
+			"artifactRelationships": [
+				{
+					"parent": "5a792641004816b8",
+					"child": "7688c43904e9898a",
+					"type": "contains"
+				},
+				{
+					"parent": "5a792641004816b8",
+					"child": "64aadaef2504b82e",
+					"type": "contains"
+				}
+			],
+			"source": {
+				"id": "6f4e51fce9ebd5f6a365bde070dbf0b44d8674b923a372fce5c0dd810a02e056",
+				"name": "/layers/paketo-buildpacks_mri/mri",
+				"version": "",
+				"type": "directory",
+				"metadata": {
+					"path": "/layers/paketo-buildpacks_mri/mri"
+				}
+			},
+			"distro": {},
+			"descriptor": {
+				"name": "paketo-buildpacks/mri",
+				"version": ""
+			},
+			"schema": {
+				"version": "16.1.10",
+				"url": "https://raw.githubusercontent.com/anchore/syft/main/schema/json/schema-16.1.10.json"
+			}
+		}`))
+	})
+
+	context("when the dependency declares no CPE", func() {
+		it.Before(func() {
+			bom.Dependency.CPEs = nil
+		})
+
+		it("records a CPE that matches nothing", func() {
+			Expect(encode(mri.CycloneDXMediaType)).To(ContainSubstring(`"cpe": "cpe:2.3:-:-:-:-:-:-:-:-:-:-:-"`))
+		})
+	})
+
+	context("when there are VEX statements", func() {
+		it.Before(func() {
+			bom.Vulnerabilities = []mri.VEXStatement{
+				{
+					ID:            "CVE-2025-0001",
+					State:         "not_affected",
+					Justification: "requires_environment",
+					Detail:        "Only affects Ruby on Windows.",
+					Source:        "https://www.ruby-lang.org/en/news/some-advisory/",
+				},
+				{ID: "CVE-2025-0002", State: "in_triage"},
+			}
+		})
+
+		it("records them against the dependency in CycloneDX", func() {
+			var document struct {
+				Vulnerabilities []map[string]interface{} `json:"vulnerabilities"`
+			}
+			Expect(json.Unmarshal([]byte(encode(mri.CycloneDXMediaType)), &document)).To(Succeed())
+			Expect(document.Vulnerabilities).To(Equal([]map[string]interface{}{
+				{
+					"id":     "CVE-2025-0001",
+					"source": map[string]interface{}{"url": "https://www.ruby-lang.org/en/news/some-advisory/"},
+					"analysis": map[string]interface{}{
+						"state":         "not_affected",
+						"justification": "requires_environment",
+						"detail":        "Only affects Ruby on Windows.",
+					},
+					"affects": []interface{}{map[string]interface{}{"ref": "5a792641004816b8"}},
+				},
+				{
+					"id":       "CVE-2025-0002",
+					"analysis": map[string]interface{}{"state": "in_triage"},
+					"affects":  []interface{}{map[string]interface{}{"ref": "5a792641004816b8"}},
+				},
+			}))
+		})
+
+		it("leaves them out of the other formats", func() {
+			Expect(encode(mri.SPDXMediaType)).NotTo(ContainSubstring("CVE-2025-0001"))
+			Expect(encode(mri.SyftMediaType)).NotTo(ContainSubstring("CVE-2025-0001"))
+		})
+	})
+
+	context("when a license is not an SPDX identifier", func() {
+		it.Before(func() {
+			bom.Gems[0].Licenses = []string{"Ruby License"}
+		})
+
+		it("records it by name and makes no assertion about the license expression", func() {
+			Expect(encode(mri.CycloneDXMediaType)).To(ContainSubstring(`"name": "Ruby License"`))
+			Expect(encode(mri.SPDXMediaType)).NotTo(ContainSubstring("Ruby License"))
+		})
+	})
+}
@@ -0,0 +1,279 @@
+package mri
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/chronos"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+// slimExcludedPaths lists the content of the MRI layer, relative to its root,
+// that is only needed to build native extensions or read documentation and is
+// therefore left out of the slim launch layer.
+var slimExcludedPaths = []string{
+	"include",
+	"lib/pkgconfig",
+	"share/doc",
+	"share/man",
+	"share/ri",
+}
+
+// LoadSlimLaunchConfig reports whether $BP_MRI_SLIM_LAUNCH requests a separate,
+// slimmed down MRI layer for launch.
+func LoadSlimLaunchConfig() (bool, error) {
+	value, ok := os.LookupEnv("BP_MRI_SLIM_LAUNCH")
+	if !ok {
+		return false, nil
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for $BP_MRI_SLIM_LAUNCH: %w", err)
+	}
+
+	return enabled, nil
+}
+
+// slimLaunchLayer creates a launch layer from the given MRI layer without the
+// headers, static libraries, mkmf tooling and documentation that are only
+// needed at build time. The launch environment and exec.d executables of the
+// MRI layer are carried over to the new layer, and its SBOM carries the same
+// VEX statements. The layer is reused when it was created from an MRI layer
+// with the same metadata.
+func slimLaunchLayer(
+	context packit.BuildContext,
+	mriLayer packit.Layer,
+	dependency postal.Dependency,
+	vulnerabilities []VEXStatement,
+	sbomGenerator SBOMGenerator,
+	logger scribe.Emitter,
+	clock chronos.Clock,
+) (packit.Layer, error) {
+	logger.Debug.Process("Getting the layer associated with the MRI launch runtime:")
+	layer, err := context.Layers.Get(MRILaunch)
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	logger.Debug.Subprocess(layer.Path)
+	logger.Debug.Break()
+
+	if layer.Metadata != nil && reflect.DeepEqual(layer.Metadata, mriLayer.Metadata) {
+		logger.Process("Reusing cached layer %s", layer.Path)
+		logger.Break()
+
+		layer.Launch = true
+		layer.ExecD = mriLayer.ExecD
+
+		return layer, nil
+	}
+
+	layer, err = layer.Reset()
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	layer.Launch = true
+
+	logger.Process("Creating slim launch layer")
+	duration, err := clock.Measure(func() error {
+		return copySlim(mriLayer.Path, layer.Path)
+	})
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	logger.Action("Completed in %s", duration.Round(time.Millisecond))
+
+	fullSize, err := directorySize(mriLayer.Path)
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	slimSize, err := directorySize(layer.Path)
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	logger.Subprocess("Launch layer is %s instead of %s (%s smaller)", formatSize(slimSize), formatSize(fullSize), formatSize(fullSize-slimSize))
+	logger.Break()
+
+	logger.GeneratingSBOM(layer.Path)
+	var sbomContent InstallationSBOM
+	duration, err = clock.Measure(func() error {
+		sbomContent, err = sbomGenerator.GenerateFromDependency(dependency, layer.Path)
+		return err
+	})
+	if err != nil {
+		return packit.Layer{}, err
+	}
+	sbomContent.Vulnerabilities = vulnerabilities
+
+	logger.Action("Completed in %s", duration.Round(time.Millisecond))
+	logger.Break()
+
+	logger.FormattingSBOM(context.BuildpackInfo.SBOMFormats...)
+	layer.SBOM, err = sbomContent.InFormats(context.BuildpackInfo.SBOMFormats...)
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	layer.Metadata = mriLayer.Metadata
+
+	// Values that refer to the MRI layer, such as GEM_PATH, are rewritten to
+	// refer to the launch layer instead.
+	for _, env := range []packit.Environment{mriLayer.SharedEnv, mriLayer.LaunchEnv} {
+		for key, value := range env {
+			layer.LaunchEnv[key] = strings.ReplaceAll(value, mriLayer.Path, layer.Path)
+		}
+	}
+	layer.ExecD = mriLayer.ExecD
+
+	logger.EnvironmentVariables(layer)
+
+	return layer, nil
+}
+
+// copySlim copies the source directory to the destination, skipping the
+// content that is not needed at launch as well as the environment and exec.d
+// directories that packit manages for the source layer.
+func copySlim(source, destination string) error {
+	return filepath.WalkDir(source, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+
+		if isSlimExcluded(filepath.ToSlash(rel), entry) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		target := filepath.Join(destination, rel)
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case entry.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+
+			return os.Symlink(link, target)
+
+		default:
+			return copyFile(path, target, info.Mode().Perm())
+		}
+	})
+}
+
+func isSlimExcluded(rel string, entry fs.DirEntry) bool {
+	if rel == "." {
+		return false
+	}
+
+	// Directories managed by packit for the MRI layer itself.
+	if !strings.Contains(rel, "/") && (strings.HasPrefix(rel, "env") || rel == "exec.d") {
+		return true
+	}
+
+	for _, excluded := range slimExcludedPaths {
+		if rel == excluded {
+			return true
+		}
+	}
+
+	if entry.IsDir() {
+		return false
+	}
+
+	name := entry.Name()
+	return strings.HasSuffix(name, ".a") || name == "mkmf.rb"
+}
+
+func copyFile(source, destination string, perm fs.FileMode) error {
+	src, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := src.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to close file: %v\n", err)
+		}
+	}()
+
+	dst, err := os.OpenFile(destination, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, src)
+	if err != nil {
+		_ = dst.Close()
+		return err
+	}
+
+	return dst.Close()
+}
+
+func directorySize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.Type().IsRegular() {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+
+		return nil
+	})
+
+	return size, err
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	value, suffix := float64(size)/unit, "KiB"
+	for _, next := range []string{"MiB", "GiB"} {
+		if value < unit {
+			break
+		}
+		value, suffix = value/unit, next
+	}
+
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
@@ -0,0 +1,240 @@
+package mri_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/mri"
+	"github.com/paketo-buildpacks/mri/fakes"
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/chronos"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testSlimLaunch(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		source      string
+		destination string
+	)
+
+	it.Before(func() {
+		source = t.TempDir()
+		destination = t.TempDir()
+
+		for _, dir := range []string{
+			"bin",
+			"env",
+			"env.launch",
+			"exec.d",
+			"include/ruby-3.4.0",
+			"lib/pkgconfig",
+			"lib/ruby/3.4.0",
+			"share/doc/ruby",
+			"share/man/man1",
+			"share/ri/3.4.0",
+			"share/other",
+		} {
+			Expect(os.MkdirAll(filepath.Join(source, dir), 0755)).To(Succeed())
+		}
+
+		for path, mode := range map[string]os.FileMode{
+			"bin/ruby":                        0755,
+			"env/GEM_PATH.default":            0644,
+			"env.launch/RUBY_YJIT_ENABLE":     0644,
+			"exec.d/optimize-memory":          0755,
+			"include/ruby-3.4.0/ruby.h":       0644,
+			"lib/libruby-static.a":            0644,
+			"lib/libruby.so.3.4.8":            0755,
+			"lib/pkgconfig/ruby-3.4.pc":       0644,
+			"lib/ruby/3.4.0/mkmf.rb":          0644,
+			"lib/ruby/3.4.0/set.rb":           0640,
+			"share/doc/ruby/README":           0644,
+			"share/man/man1/ruby.1":           0644,
+			"share/ri/3.4.0/cache.ri":         0644,
+			"share/other/environment.example": 0644,
+		} {
+			Expect(os.WriteFile(filepath.Join(source, path), []byte(path), mode)).To(Succeed())
+			Expect(os.Chmod(filepath.Join(source, path), mode)).To(Succeed())
+		}
+
+		Expect(os.Symlink("libruby.so.3.4.8", filepath.Join(source, "lib", "libruby.so"))).To(Succeed())
+	})
+
+	context("copySlim", func() {
+		it("leaves out the content only needed at build time", func() {
+			Expect(mri.CopySlim(source, destination)).To(Succeed())
+
+			for _, path := range []string{
+				"env",
+				"env.launch",
+				"exec.d",
+				"include",
+				"lib/pkgconfig",
+				"lib/libruby-static.a",
+				"lib/ruby/3.4.0/mkmf.rb",
+				"share/doc",
+				"share/man",
+				"share/ri",
+			} {
+				Expect(filepath.Join(destination, path)).NotTo(BeAnExistingFile(), path)
+			}
+
+			for _, path := range []string{
+				"bin/ruby",
+				"lib/libruby.so.3.4.8",
+				"lib/ruby/3.4.0/set.rb",
+				"share/other/environment.example",
+			} {
+				Expect(filepath.Join(destination, path)).To(BeARegularFile(), path)
+			}
+		})
+
+		it("preserves symlinks and file modes", func() {
+			Expect(mri.CopySlim(source, destination)).To(Succeed())
+
+			link, err := os.Readlink(filepath.Join(destination, "lib", "libruby.so"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(link).To(Equal("libruby.so.3.4.8"))
+
+			for path, mode := range map[string]os.FileMode{
+				"bin/ruby":              0755,
+				"lib/libruby.so.3.4.8":  0755,
+				"lib/ruby/3.4.0/set.rb": 0640,
+			} {
+				info, err := os.Stat(filepath.Join(destination, path))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.Mode().Perm()).To(Equal(mode), path)
+			}
+
+			content, err := os.ReadFile(filepath.Join(destination, "bin", "ruby"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("bin/ruby"))
+		})
+
+		context("failure cases", func() {
+			context("when the source does not exist", func() {
+				it("returns an error", func() {
+					err := mri.CopySlim(filepath.Join(source, "missing"), destination)
+					Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
+				})
+			})
+		})
+	})
+
+	context("formatSize", func() {
+		it("uses the largest unit that keeps the value at or above 1", func() {
+			for size, expected := range map[int64]string{
+				0:                  "0 B",
+				1023:               "1023 B",
+				1024:               "1.0 KiB",
+				1024*1024 - 1:      "1024.0 KiB",
+				1024 * 1024:        "1.0 MiB",
+				1536 * 1024:        "1.5 MiB",
+				1024 * 1024 * 1024: "1.0 GiB",
+				5 << 40:            "5120.0 GiB",
+			} {
+				Expect(mri.FormatSize(size)).To(Equal(expected), "%d", size)
+			}
+		})
+	})
+
+	context("slimLaunchLayer", func() {
+		var (
+			layersDir     string
+			buildContext  packit.BuildContext
+			mriLayer      packit.Layer
+			sbomGenerator *fakes.SBOMGenerator
+			buffer        *bytes.Buffer
+		)
+
+		it.Before(func() {
+			layersDir = t.TempDir()
+			buildContext = packit.BuildContext{
+				Layers: packit.Layers{Path: layersDir},
+			}
+
+			mriLayer = packit.Layer{
+				Name: "mri",
+				Path: source,
+				Metadata: map[string]interface{}{
+					"dependency-sha": "sha256:some-sha",
+					"version":        "3.4.8",
+				},
+				SharedEnv: packit.Environment{"GEM_PATH.default": filepath.Join(source, "lib", "ruby", "gems", "3.4.0")},
+				LaunchEnv: packit.Environment{},
+				ExecD:     []string{"/cnb/bin/optimize-memory"},
+			}
+
+			sbomGenerator = &fakes.SBOMGenerator{}
+			buffer = bytes.NewBuffer(nil)
+		})
+
+		slimLaunchLayer := func() (packit.Layer, error) {
+			return mri.SlimLaunchLayer(buildContext, mriLayer, postal.Dependency{Version: "3.4.8"}, nil, sbomGenerator, scribe.NewEmitter(buffer), chronos.DefaultClock)
+		}
+
+		it("creates the layer from the MRI layer", func() {
+			layer, err := slimLaunchLayer()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(layer.Name).To(Equal("mri-launch"))
+			Expect(layer.Launch).To(BeTrue())
+			Expect(layer.Build).To(BeFalse())
+			Expect(layer.Metadata).To(Equal(mriLayer.Metadata))
+			Expect(layer.ExecD).To(Equal([]string{"/cnb/bin/optimize-memory"}))
+			Expect(layer.LaunchEnv).To(HaveKeyWithValue("GEM_PATH.default", filepath.Join(layersDir, "mri-launch", "lib", "ruby", "gems", "3.4.0")))
+
+			Expect(filepath.Join(layersDir, "mri-launch", "bin", "ruby")).To(BeARegularFile())
+			Expect(sbomGenerator.GenerateFromDependencyCall.CallCount).To(Equal(1))
+			Expect(buffer.String()).To(ContainSubstring("Creating slim launch layer"))
+		})
+
+		context("when the layer was created from an MRI layer with the same metadata", func() {
+			it.Before(func() {
+				Expect(writeLayerMetadata(filepath.Join(layersDir, "mri-launch.toml"), map[string]interface{}{
+					"dependency-sha": "sha256:some-sha",
+					"version":        "3.4.8",
+				})).To(Succeed())
+			})
+
+			it("reuses it", func() {
+				layer, err := slimLaunchLayer()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(layer.Launch).To(BeTrue())
+				Expect(layer.ExecD).To(Equal([]string{"/cnb/bin/optimize-memory"}))
+
+				Expect(filepath.Join(layersDir, "mri-launch", "bin", "ruby")).NotTo(BeAnExistingFile())
+				Expect(sbomGenerator.GenerateFromDependencyCall.CallCount).To(Equal(0))
+				Expect(buffer.String()).To(ContainSubstring("Reusing cached layer %s", filepath.Join(layersDir, "mri-launch")))
+			})
+		})
+
+		context("when the layer was created from an MRI layer with different metadata", func() {
+			it.Before(func() {
+				Expect(writeLayerMetadata(filepath.Join(layersDir, "mri-launch.toml"), map[string]interface{}{
+					"dependency-sha": "sha256:other-sha",
+					"version":        "3.4.7",
+				})).To(Succeed())
+			})
+
+			it("creates it again", func() {
+				layer, err := slimLaunchLayer()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(layer.Metadata).To(Equal(mriLayer.Metadata))
+				Expect(filepath.Join(layersDir, "mri-launch", "bin", "ruby")).To(BeARegularFile())
+				Expect(sbomGenerator.GenerateFromDependencyCall.CallCount).To(Equal(1))
+				Expect(buffer.String()).To(ContainSubstring("Creating slim launch layer"))
+			})
+		})
+	})
+}
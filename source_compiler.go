@@ -0,0 +1,209 @@
+package mri
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/paketo-buildpacks/packit/v2/cargo"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/paketo-buildpacks/packit/v2/vacation"
+)
+
+// LoadCompileConfig reports whether $BP_MRI_COMPILE_FROM_SOURCE allows MRI to
+// be compiled from source when no precompiled artifact matches the stack.
+func LoadCompileConfig() (bool, error) {
+	value, ok := os.LookupEnv("BP_MRI_COMPILE_FROM_SOURCE")
+	if !ok {
+		return false, nil
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for $BP_MRI_COMPILE_FROM_SOURCE: %w", err)
+	}
+
+	return enabled, nil
+}
+
+// ConfigureFlags returns the options passed to ./configure for the given MRI
+// version, matching those used to build the precompiled artifacts in
+// dependency/actions/compile. The installation prefix is passed separately.
+// YJIT is only compiled in when it is requested, as building it needs rustc,
+// which the build images do not include.
+func ConfigureFlags(version string, yjit bool) []string {
+	flags := []string{"--enable-load-relative", "--disable-install-doc"}
+
+	v, err := semver.NewVersion(version)
+	if yjit && err == nil && !v.LessThan(semver.MustParse("3.2.0")) {
+		flags = append(flags, "--enable-yjit")
+	}
+
+	return flags
+}
+
+// SourceDependency converts a buildpack.toml dependency into one that is
+// installed by compiling its source.
+func SourceDependency(dependency cargo.ConfigMetadataDependency) postal.Dependency {
+	var licenses []string
+	for _, license := range dependency.Licenses {
+		if l, ok := license.(string); ok {
+			licenses = append(licenses, l)
+		}
+	}
+
+	sourceDependency := postal.Dependency{
+		ID:             dependency.ID,
+		Name:           dependency.Name,
+		Version:        dependency.Version,
+		CPE:            dependency.CPE,
+		PURL:           dependency.PURL,
+		Licenses:       licenses,
+		Source:         dependency.Source,
+		SourceChecksum: dependency.SourceChecksum,
+	}
+
+	//nolint Ignore SA1019, informed usage of deprecated field
+	if sourceDependency.SourceChecksum == "" && dependency.SourceSHA256 != "" {
+		sourceDependency.SourceChecksum = "sha256:" + dependency.SourceSHA256
+	}
+
+	if dependency.DeprecationDate != nil {
+		sourceDependency.DeprecationDate = *dependency.DeprecationDate
+	}
+
+	return sourceDependency
+}
+
+// SourceCompiler downloads, verifies and compiles the source of MRI into a
+// layer.
+type SourceCompiler struct {
+	transport Transport
+	configure Executable
+	make      Executable
+}
+
+func NewSourceCompiler(transport Transport, configure, make Executable) SourceCompiler {
+	return SourceCompiler{
+		transport: transport,
+		configure: configure,
+		make:      make,
+	}
+}
+
+// Compile downloads the source of the dependency, verifies it against its
+// source checksum and installs it into the layer path with the given
+// configure flags.
+func (c SourceCompiler) Compile(dependency postal.Dependency, layerPath string, flags []string) error {
+	if dependency.Source == "" || dependency.SourceChecksum == "" {
+		return fmt.Errorf("MRI %s does not list a source and source checksum", dependency.Version)
+	}
+
+	// Building YJIT fails late without rustc, so its absence is reported
+	// before the source is downloaded.
+	if slices.Contains(flags, "--enable-yjit") {
+		if _, err := exec.LookPath("rustc"); err != nil {
+			return fmt.Errorf("compiling MRI %s with YJIT requires rustc, which was not found on the $PATH: install Rust in the build image or unset $BP_MRI_YJIT", dependency.Version)
+		}
+	}
+
+	workingDir, err := os.MkdirTemp("", "mri-source")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.RemoveAll(workingDir); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to remove directory: %v\n", err)
+		}
+	}()
+
+	err = c.download(dependency, filepath.Join(workingDir, "source.tgz"))
+	if err != nil {
+		return err
+	}
+
+	sourceDir := filepath.Join(workingDir, "source")
+	err = extract(filepath.Join(workingDir, "source.tgz"), sourceDir)
+	if err != nil {
+		return fmt.Errorf("failed to extract MRI source: %w", err)
+	}
+
+	// configure is looked up on the $PATH, which must include the source.
+	env := append(os.Environ(), fmt.Sprintf("PATH=%s%c%s", sourceDir, os.PathListSeparator, os.Getenv("PATH")))
+
+	output := bytes.NewBuffer(nil)
+	err = c.configure.Execute(pexec.Execution{
+		Args:   append([]string{fmt.Sprintf("--prefix=%s", layerPath)}, flags...),
+		Dir:    sourceDir,
+		Env:    env,
+		Stdout: output,
+		Stderr: output,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to configure MRI: %w\n%s", err, strings.TrimSpace(output.String()))
+	}
+
+	output.Reset()
+	err = c.make.Execute(pexec.Execution{
+		Args:   []string{"-j", strconv.Itoa(runtime.NumCPU()), "install"},
+		Dir:    sourceDir,
+		Env:    env,
+		Stdout: output,
+		Stderr: output,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to compile MRI: %w\n%s", err, strings.TrimSpace(output.String()))
+	}
+
+	return nil
+}
+
+// download writes the source to the given path, failing when its content
+// does not match the source checksum.
+func (c SourceCompiler) download(dependency postal.Dependency, path string) error {
+	bundle, err := c.transport.Drop("", dependency.Source)
+	if err != nil {
+		return fmt.Errorf("failed to fetch MRI source: %w", err)
+	}
+	defer func() {
+		if err := bundle.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to close source: %v\n", err)
+		}
+	}()
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(file, cargo.NewValidatedReader(bundle, dependency.SourceChecksum))
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to download MRI source: %w", err)
+	}
+
+	return file.Close()
+}
+
+func extract(archive, destination string) error {
+	file, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to close file: %v\n", err)
+		}
+	}()
+
+	return vacation.NewArchive(file).StripComponents(1).Decompress(destination)
+}
@@ -0,0 +1,208 @@
+package mri_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/mri"
+	"github.com/paketo-buildpacks/mri/fakes"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testSourceCompiler(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		source     []byte
+		transport  *fakes.Transport
+		configure  *fakes.Executable
+		make       *fakes.Executable
+		dependency postal.Dependency
+		compiler   mri.SourceCompiler
+	)
+
+	it.Before(func() {
+		buffer := bytes.NewBuffer(nil)
+		gw := gzip.NewWriter(buffer)
+		tw := tar.NewWriter(gw)
+
+		Expect(tw.WriteHeader(&tar.Header{Name: "ruby-3.4.8/", Mode: 0755, Typeflag: tar.TypeDir})).To(Succeed())
+
+		content := []byte("#!/bin/sh\n")
+		Expect(tw.WriteHeader(&tar.Header{Name: "ruby-3.4.8/configure", Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg})).To(Succeed())
+		_, err := tw.Write(content)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(tw.Close()).To(Succeed())
+		Expect(gw.Close()).To(Succeed())
+		source = buffer.Bytes()
+
+		transport = &fakes.Transport{}
+		transport.DropCall.Stub = func(string, string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(source)), nil
+		}
+
+		configure = &fakes.Executable{}
+		make = &fakes.Executable{}
+
+		dependency = postal.Dependency{
+			Version:        "3.4.8",
+			Source:         "https://cache.ruby-lang.org/pub/ruby/3.4/ruby-3.4.8.tar.gz",
+			SourceChecksum: fmt.Sprintf("sha256:%x", sha256.Sum256(source)),
+		}
+
+		compiler = mri.NewSourceCompiler(transport, configure, make)
+	})
+
+	context("Compile", func() {
+		it("downloads, configures and installs MRI into the layer", func() {
+			var sourceDir string
+			configure.ExecuteCall.Stub = func(execution pexec.Execution) error {
+				sourceDir = execution.Dir
+				_, err := os.Stat(filepath.Join(execution.Dir, "configure"))
+				return err
+			}
+
+			err := compiler.Compile(dependency, "/layers/mri", []string{"--enable-load-relative", "--disable-install-doc"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(transport.DropCall.Receives.Uri).To(Equal("https://cache.ruby-lang.org/pub/ruby/3.4/ruby-3.4.8.tar.gz"))
+
+			Expect(configure.ExecuteCall.Receives.Execution.Args).To(Equal([]string{"--prefix=/layers/mri", "--enable-load-relative", "--disable-install-doc"}))
+			Expect(configure.ExecuteCall.Receives.Execution.Env).To(ContainElement(HavePrefix(fmt.Sprintf("PATH=%s:", sourceDir))))
+
+			Expect(make.ExecuteCall.Receives.Execution.Args).To(ContainElement("install"))
+			Expect(make.ExecuteCall.Receives.Execution.Dir).To(Equal(sourceDir))
+
+			Expect(sourceDir).NotTo(BeADirectory())
+		})
+
+		context("when YJIT is enabled and rustc is on the $PATH", func() {
+			it.Before(func() {
+				bin := t.TempDir()
+				Expect(os.WriteFile(filepath.Join(bin, "rustc"), []byte("#!/bin/sh\n"), 0755)).To(Succeed())
+				t.Setenv("PATH", bin)
+			})
+
+			it("compiles MRI with YJIT", func() {
+				err := compiler.Compile(dependency, "/layers/mri", []string{"--enable-yjit"})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(configure.ExecuteCall.Receives.Execution.Args).To(Equal([]string{"--prefix=/layers/mri", "--enable-yjit"}))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when YJIT is enabled and rustc is not on the $PATH", func() {
+				it.Before(func() {
+					t.Setenv("PATH", t.TempDir())
+				})
+
+				it("returns an error before downloading the source", func() {
+					err := compiler.Compile(dependency, "/layers/mri", []string{"--enable-yjit"})
+					Expect(err).To(MatchError("compiling MRI 3.4.8 with YJIT requires rustc, which was not found on the $PATH: install Rust in the build image or unset $BP_MRI_YJIT"))
+
+					Expect(transport.DropCall.CallCount).To(Equal(0))
+					Expect(configure.ExecuteCall.CallCount).To(Equal(0))
+				})
+			})
+
+			context("when the dependency does not list a source", func() {
+				it.Before(func() {
+					dependency.Source = ""
+				})
+
+				it("returns an error", func() {
+					err := compiler.Compile(dependency, "/layers/mri", nil)
+					Expect(err).To(MatchError("MRI 3.4.8 does not list a source and source checksum"))
+				})
+			})
+
+			context("when the source cannot be fetched", func() {
+				it.Before(func() {
+					transport.DropCall.Stub = nil
+					transport.DropCall.Returns.Error = errors.New("connection refused")
+				})
+
+				it("returns an error", func() {
+					err := compiler.Compile(dependency, "/layers/mri", nil)
+					Expect(err).To(MatchError("failed to fetch MRI source: connection refused"))
+				})
+			})
+
+			context("when the source does not match the checksum", func() {
+				it.Before(func() {
+					dependency.SourceChecksum = "sha256:some-other-sha"
+				})
+
+				it("returns an error", func() {
+					err := compiler.Compile(dependency, "/layers/mri", nil)
+					Expect(err).To(MatchError(ContainSubstring("failed to download MRI source: validation error: checksum does not match")))
+					Expect(configure.ExecuteCall.CallCount).To(Equal(0))
+				})
+			})
+
+			context("when the source cannot be extracted", func() {
+				it.Before(func() {
+					source = []byte("not an archive")
+					dependency.SourceChecksum = fmt.Sprintf("sha256:%x", sha256.Sum256(source))
+				})
+
+				it("returns an error", func() {
+					err := compiler.Compile(dependency, "/layers/mri", nil)
+					Expect(err).To(MatchError(ContainSubstring("failed to extract MRI source")))
+				})
+			})
+
+			context("when configure fails", func() {
+				it.Before(func() {
+					configure.ExecuteCall.Stub = func(execution pexec.Execution) error {
+						_, _ = fmt.Fprintln(execution.Stderr, "configure: error: no acceptable C compiler found in $PATH")
+						return errors.New("exit status 1")
+					}
+				})
+
+				it("returns an error that includes the output", func() {
+					err := compiler.Compile(dependency, "/layers/mri", nil)
+					Expect(err).To(MatchError("failed to configure MRI: exit status 1\nconfigure: error: no acceptable C compiler found in $PATH"))
+					Expect(make.ExecuteCall.CallCount).To(Equal(0))
+				})
+			})
+
+			context("when make fails", func() {
+				it.Before(func() {
+					make.ExecuteCall.Stub = func(execution pexec.Execution) error {
+						_, _ = fmt.Fprintln(execution.Stdout, "make: *** [all] Error 2")
+						return errors.New("exit status 2")
+					}
+				})
+
+				it("returns an error that includes the output", func() {
+					err := compiler.Compile(dependency, "/layers/mri", nil)
+					Expect(err).To(MatchError("failed to compile MRI: exit status 2\nmake: *** [all] Error 2"))
+				})
+			})
+		})
+	})
+
+	context("ConfigureFlags", func() {
+		it("enables YJIT from MRI 3.2 when it is requested", func() {
+			Expect(mri.ConfigureFlags("3.1.7", true)).To(Equal([]string{"--enable-load-relative", "--disable-install-doc"}))
+			Expect(mri.ConfigureFlags("3.2.0", true)).To(Equal([]string{"--enable-load-relative", "--disable-install-doc", "--enable-yjit"}))
+			Expect(mri.ConfigureFlags("4.0.1", true)).To(Equal([]string{"--enable-load-relative", "--disable-install-doc", "--enable-yjit"}))
+			Expect(mri.ConfigureFlags("4.0.1", false)).To(Equal([]string{"--enable-load-relative", "--disable-install-doc"}))
+		})
+	})
+}
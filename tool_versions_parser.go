@@ -0,0 +1,86 @@
+package mri
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+type ToolVersionsParser struct {
+	logger scribe.Emitter
+}
+
+func NewToolVersionsParser(logger scribe.Emitter) ToolVersionsParser {
+	return ToolVersionsParser{
+		logger: logger,
+	}
+}
+
+// ParseVersion reads the ruby entry of an asdf .tool-versions file and
+// returns it as a semver constraint. When several versions are listed asdf
+// uses the first one installed, so only the first is considered. Entries that
+// do not name an MRI version are logged and ignored.
+func (p ToolVersionsParser) ParseVersion(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to close file: %v\n", err)
+		}
+	}()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "ruby" {
+			continue
+		}
+
+		version, err := normalizeToolVersion(fields[1])
+		if err != nil {
+			p.logger.Debug.Process("Ignoring the ruby entry in %s: %s", path, err)
+			p.logger.Debug.Break()
+			return "", nil
+		}
+
+		return version, nil
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return "", nil
+}
+
+// normalizeToolVersion converts a ruby version as written for asdf or mise
+// into a semver constraint. "latest" selects the newest available version and
+// "prefix:3.3" the newest version starting with 3.3. Other forms, such as
+// "ref:<git-ref>" and "path:<dir>", cannot be satisfied by this buildpack and
+// return an error.
+func normalizeToolVersion(version string) (string, error) {
+	if version == "latest" {
+		return "*", nil
+	}
+
+	if prefix, ok := strings.CutPrefix(version, "prefix:"); ok {
+		version = prefix
+	} else if strings.Contains(version, ":") {
+		return "", fmt.Errorf("%q does not name an MRI version", version)
+	}
+
+	return normalizeRubyVersion(version)
+}
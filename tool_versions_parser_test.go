@@ -0,0 +1,140 @@
+package mri_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/mri"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testToolVersionsParser(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		workingDir string
+		path       string
+		buffer     *bytes.Buffer
+		parser     mri.ToolVersionsParser
+	)
+
+	it.Before(func() {
+		var err error
+		workingDir, err = os.MkdirTemp("", "working-dir")
+		Expect(err).NotTo(HaveOccurred())
+
+		path = filepath.Join(workingDir, ".tool-versions")
+		Expect(os.WriteFile(path, []byte(`# managed by asdf
+nodejs 20.11.0
+ruby 3.4.1 3.3.6 # fallback to 3.3
+`), 0600)).To(Succeed())
+
+		buffer = bytes.NewBuffer(nil)
+		parser = mri.NewToolVersionsParser(scribe.NewEmitter(buffer).WithLevel("DEBUG"))
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(workingDir)).To(Succeed())
+	})
+
+	context("ParseVersion", func() {
+		it("parses the first ruby version from a .tool-versions file", func() {
+			version, err := parser.ParseVersion(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal("3.4.1"))
+		})
+
+		context("when the ruby version is partial", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte("ruby 3.4\n"), 0600)).To(Succeed())
+			})
+
+			it("returns a wildcard constraint", func() {
+				version, err := parser.ParseVersion(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(Equal("3.4.*"))
+			})
+		})
+
+		context("when the ruby version is an alias", func() {
+			for entry, expected := range map[string]string{
+				"ruby latest":     "*",
+				"ruby prefix:3.3": "3.3.*",
+				"ruby prefix:3":   "3.*",
+				"ruby system":     "",
+			} {
+				it("converts "+entry+" into a semver constraint", func() {
+					Expect(os.WriteFile(path, []byte(entry+"\n"), 0600)).To(Succeed())
+
+					version, err := parser.ParseVersion(path)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(version).To(Equal(expected))
+				})
+			}
+		})
+
+		context("when the ruby version does not name an MRI version", func() {
+			for entry, reason := range map[string]string{
+				"ruby ref:v3_4_1":  `"ref:v3_4_1" does not name an MRI version`,
+				"ruby path:/opt/r": `"path:/opt/r" does not name an MRI version`,
+				"ruby three":       `"three" is not a valid MRI version`,
+			} {
+				it("ignores "+entry, func() {
+					Expect(os.WriteFile(path, []byte(entry+"\n"), 0600)).To(Succeed())
+
+					version, err := parser.ParseVersion(path)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(version).To(BeEmpty())
+					Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("Ignoring the ruby entry in %s: %s", path, reason)))
+				})
+			}
+		})
+
+		context("when the file has no ruby entry", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte("nodejs 20.11.0\n"), 0600)).To(Succeed())
+			})
+
+			it("returns an empty version", func() {
+				version, err := parser.ParseVersion(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(BeEmpty())
+			})
+		})
+
+		context("when the .tool-versions file does not exist", func() {
+			it.Before(func() {
+				Expect(os.Remove(path)).To(Succeed())
+			})
+
+			it("returns an empty version", func() {
+				version, err := parser.ParseVersion(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(BeEmpty())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the .tool-versions file cannot be read", func() {
+				it.Before(func() {
+					Expect(os.Chmod(path, 0000)).To(Succeed())
+				})
+
+				it.After(func() {
+					Expect(os.Chmod(path, 0644)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := parser.ParseVersion(path)
+					Expect(err).To(MatchError(ContainSubstring("permission denied")))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,179 @@
+package mri
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver"
+)
+
+// knownIncompatibilities lists the version ranges that are deliberately not
+// built for a stack, along with the reason why.
+var knownIncompatibilities = []struct {
+	stack      string
+	constraint string
+	reason     string
+}{
+	{
+		stack:      "io.buildpacks.stacks.jammy",
+		constraint: ">= 4.0.0-0",
+		reason:     "Ruby 4.x requires glibc 2.38 or later, which is not available on the Jammy stack (glibc 2.35)",
+	},
+}
+
+var constraintVersionPattern = regexp.MustCompile(`\d+(\.\d+){0,2}`)
+
+// UnsatisfiedVersionError explains why no MRI dependency in the
+// buildpack.toml satisfies the requested version constraint.
+type UnsatisfiedVersionError struct {
+	Constraint        string
+	Stack             string
+	OS                string
+	Arch              string
+	Available         []string
+	Incompatibilities []string
+	Suggestion        string
+
+	Err error
+}
+
+func (e UnsatisfiedVersionError) Error() string {
+	lines := []string{
+		fmt.Sprintf("failed to satisfy MRI version constraint %q on stack %q (%s/%s)", e.Constraint, e.Stack, e.OS, e.Arch),
+	}
+
+	if len(e.Available) == 0 {
+		lines = append(lines, "  No MRI versions are available for this stack and architecture")
+	} else {
+		lines = append(lines, fmt.Sprintf("  Available versions: %s", strings.Join(e.Available, ", ")))
+	}
+
+	for _, incompatibility := range e.Incompatibilities {
+		lines = append(lines, fmt.Sprintf("  %s", incompatibility))
+	}
+
+	if e.Suggestion != "" {
+		lines = append(lines, fmt.Sprintf("  Closest matching constraint: %q", e.Suggestion))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (e UnsatisfiedVersionError) Unwrap() error {
+	return e.Err
+}
+
+// Explain inspects the catalog to describe why the given constraint could
+// not be resolved. The returned error lists the versions available for the
+// current stack and architecture, any known incompatibility that rules out
+// the requested versions, and the constraint closest to the one requested.
+func (c DependencyCatalog) Explain(constraint string, cause error) error {
+	if constraint == "" || constraint == DefaultAlias {
+		constraint = c.defaultVersion
+	}
+
+	explanation := UnsatisfiedVersionError{
+		Constraint: constraint,
+		Stack:      c.Stack,
+		OS:         c.OS,
+		Arch:       c.Arch,
+		Err:        cause,
+	}
+
+	for _, dependency := range c.Dependencies {
+		if !slices.Contains(explanation.Available, dependency.Version) {
+			explanation.Available = append(explanation.Available, dependency.Version)
+		}
+	}
+
+	versionConstraint, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return explanation
+	}
+
+	var elsewhere []string
+	for _, dependency := range c.unsupported {
+		version := semver.MustParse(dependency.Version)
+		if !versionConstraint.Check(version) {
+			continue
+		}
+
+		for _, incompatibility := range knownIncompatibilities {
+			incompatible, err := semver.NewConstraint(incompatibility.constraint)
+			if err != nil {
+				return explanation
+			}
+
+			if incompatibility.stack == c.Stack && incompatible.Check(version) && !slices.Contains(explanation.Incompatibilities, incompatibility.reason) {
+				explanation.Incompatibilities = append(explanation.Incompatibilities, incompatibility.reason)
+			}
+		}
+
+		for _, stack := range dependency.Stacks {
+			target := stack
+			if dependency.OS != "" || dependency.Arch != "" {
+				target = fmt.Sprintf("%s (%s/%s)", stack, dependency.OS, dependency.Arch)
+			}
+
+			if !slices.Contains(elsewhere, target) {
+				elsewhere = append(elsewhere, target)
+			}
+		}
+	}
+
+	if len(elsewhere) > 0 {
+		explanation.Incompatibilities = append(explanation.Incompatibilities,
+			fmt.Sprintf("Versions matching %q are only built for: %s", constraint, strings.Join(elsewhere, ", ")))
+	}
+
+	explanation.Suggestion = c.closestConstraint(constraint)
+
+	return explanation
+}
+
+// closestConstraint suggests a wildcard constraint for the available release
+// branch nearest to the first version mentioned in the given constraint. Only
+// the available versions immediately below and above the requested one are
+// considered, preferring the newer of the two when they are equally close.
+func (c DependencyCatalog) closestConstraint(constraint string) string {
+	requested, err := semver.NewVersion(constraintVersionPattern.FindString(constraint))
+	if err != nil || len(c.Dependencies) == 0 {
+		return ""
+	}
+
+	var below, above *semver.Version
+	for _, dependency := range c.Dependencies {
+		version := semver.MustParse(dependency.Version)
+		if version.LessThan(requested) {
+			below = version
+			continue
+		}
+
+		above = version
+		break
+	}
+
+	closest := above
+	if above == nil || (below != nil && slices.Compare(distance(below, requested), distance(above, requested)) < 0) {
+		closest = below
+	}
+
+	return fmt.Sprintf("%d.%d.*", closest.Major(), closest.Minor())
+}
+
+func distance(a, b *semver.Version) []int64 {
+	return []int64{
+		absInt64(a.Major() - b.Major()),
+		absInt64(a.Minor() - b.Minor()),
+		absInt64(a.Patch() - b.Patch()),
+	}
+}
+
+func absInt64(i int64) int64 {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
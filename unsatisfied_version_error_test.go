@@ -0,0 +1,136 @@
+package mri_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/mri"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testUnsatisfiedVersionError(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		cnbDir  string
+		path    string
+		cause   error
+		catalog mri.DependencyCatalog
+	)
+
+	it.Before(func() {
+		var err error
+		cnbDir, err = os.MkdirTemp("", "cnb")
+		Expect(err).NotTo(HaveOccurred())
+
+		path = filepath.Join(cnbDir, "buildpack.toml")
+		Expect(os.WriteFile(path, []byte(`api = "0.7"
+
+[metadata]
+  [metadata.default-versions]
+    ruby = "3.3.*"
+
+  [[metadata.dependencies]]
+    id = "ruby"
+    version = "3.3.11"
+    stacks = ["io.buildpacks.stacks.jammy"]
+    os = "linux"
+    arch = "amd64"
+
+  [[metadata.dependencies]]
+    id = "ruby"
+    version = "3.4.8"
+    stacks = ["io.buildpacks.stacks.jammy"]
+    os = "linux"
+    arch = "amd64"
+
+  [[metadata.dependencies]]
+    id = "ruby"
+    version = "4.0.1"
+    stacks = ["io.buildpacks.stacks.noble"]
+    os = "linux"
+    arch = "amd64"
+`), 0600)).To(Succeed())
+
+		t.Setenv("CNB_TARGET_OS", "linux")
+		t.Setenv("CNB_TARGET_ARCH", "amd64")
+
+		catalog, err = mri.NewDependencyCatalog(path, "ruby", "io.buildpacks.stacks.jammy")
+		Expect(err).NotTo(HaveOccurred())
+
+		cause = errors.New("failed to resolve dependency")
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(cnbDir)).To(Succeed())
+	})
+
+	context("Explain", func() {
+		it("describes why the constraint cannot be satisfied", func() {
+			err := catalog.Explain("4.0.*", cause)
+
+			var explanation mri.UnsatisfiedVersionError
+			Expect(errors.As(err, &explanation)).To(BeTrue())
+			Expect(explanation).To(Equal(mri.UnsatisfiedVersionError{
+				Constraint: "4.0.*",
+				Stack:      "io.buildpacks.stacks.jammy",
+				OS:         "linux",
+				Arch:       "amd64",
+				Available:  []string{"3.3.11", "3.4.8"},
+				Incompatibilities: []string{
+					"Ruby 4.x requires glibc 2.38 or later, which is not available on the Jammy stack (glibc 2.35)",
+					`Versions matching "4.0.*" are only built for: io.buildpacks.stacks.noble (linux/amd64)`,
+				},
+				Suggestion: "3.4.*",
+				Err:        cause,
+			}))
+
+			Expect(err.Error()).To(Equal(`failed to satisfy MRI version constraint "4.0.*" on stack "io.buildpacks.stacks.jammy" (linux/amd64)
+  Available versions: 3.3.11, 3.4.8
+  Ruby 4.x requires glibc 2.38 or later, which is not available on the Jammy stack (glibc 2.35)
+  Versions matching "4.0.*" are only built for: io.buildpacks.stacks.noble (linux/amd64)
+  Closest matching constraint: "3.4.*"`))
+			Expect(errors.Unwrap(err)).To(Equal(cause))
+		})
+
+		context("when the constraint is older than every available version", func() {
+			it("suggests the closest release branch", func() {
+				err := catalog.Explain("~> 3.1.0", cause)
+
+				var explanation mri.UnsatisfiedVersionError
+				Expect(errors.As(err, &explanation)).To(BeTrue())
+				Expect(explanation.Incompatibilities).To(BeEmpty())
+				Expect(explanation.Suggestion).To(Equal("3.3.*"))
+			})
+		})
+
+		context("when the default version is requested", func() {
+			it("explains the default constraint", func() {
+				err := catalog.Explain("default", cause)
+
+				var explanation mri.UnsatisfiedVersionError
+				Expect(errors.As(err, &explanation)).To(BeTrue())
+				Expect(explanation.Constraint).To(Equal("3.3.*"))
+			})
+		})
+
+		context("when no versions are available for the stack", func() {
+			it.Before(func() {
+				var err error
+				catalog, err = mri.NewDependencyCatalog(path, "ruby", "missing-stack")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it("says so", func() {
+				err := catalog.Explain("3.3.*", cause)
+				Expect(err).To(MatchError(`failed to satisfy MRI version constraint "3.3.*" on stack "missing-stack" (linux/amd64)
+  No MRI versions are available for this stack and architecture
+  Versions matching "3.3.*" are only built for: io.buildpacks.stacks.jammy (linux/amd64)`))
+			})
+		})
+	})
+}
@@ -0,0 +1,69 @@
+package mri
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/Masterminds/semver"
+)
+
+// LoadDisallowDowngradeConfig reports whether $BP_MRI_DISALLOW_DOWNGRADE
+// requests that the build fail rather than install an older MRI version than
+// the cached layer holds.
+func LoadDisallowDowngradeConfig() (bool, error) {
+	value, ok := os.LookupEnv("BP_MRI_DISALLOW_DOWNGRADE")
+	if !ok {
+		return false, nil
+	}
+
+	disallowed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for $BP_MRI_DISALLOW_DOWNGRADE: %w", err)
+	}
+
+	return disallowed, nil
+}
+
+// VersionChange describes the MRI version held by the cached layer and the
+// version that replaces it. From is empty when nothing was cached.
+type VersionChange struct {
+	From string
+	To   string
+}
+
+// IsUpgrade reports whether the new version is newer than the cached one.
+func (c VersionChange) IsUpgrade() bool {
+	return c.compare() < 0
+}
+
+// IsDowngrade reports whether the new version is older than the cached one.
+func (c VersionChange) IsDowngrade() bool {
+	return c.compare() > 0
+}
+
+// compare returns the result of comparing the cached version with the new
+// one, or zero when either of them is unknown.
+func (c VersionChange) compare() int {
+	from, err := semver.NewVersion(c.From)
+	if err != nil {
+		return 0
+	}
+
+	to, err := semver.NewVersion(c.To)
+	if err != nil {
+		return 0
+	}
+
+	return from.Compare(to)
+}
+
+// DowngradeError is returned when $BP_MRI_DISALLOW_DOWNGRADE is set and the
+// resolved MRI version is older than the version in the cached layer.
+type DowngradeError struct {
+	VersionChange
+}
+
+func (e DowngradeError) Error() string {
+	return fmt.Sprintf("MRI would be downgraded from %s to %s and $BP_MRI_DISALLOW_DOWNGRADE is set: request version %s or later, or clear the build cache", e.From, e.To, e.From)
+}
@@ -0,0 +1,72 @@
+package mri_test
+
+import (
+	"testing"
+
+	"github.com/paketo-buildpacks/mri"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testVersionChange(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("IsUpgrade and IsDowngrade", func() {
+		it("compare the cached version with the new one", func() {
+			upgrade := mri.VersionChange{From: "3.3.11", To: "3.3.12"}
+			Expect(upgrade.IsUpgrade()).To(BeTrue())
+			Expect(upgrade.IsDowngrade()).To(BeFalse())
+
+			downgrade := mri.VersionChange{From: "3.4.8", To: "3.3.11"}
+			Expect(downgrade.IsUpgrade()).To(BeFalse())
+			Expect(downgrade.IsDowngrade()).To(BeTrue())
+
+			same := mri.VersionChange{From: "3.4.8", To: "3.4.8"}
+			Expect(same.IsUpgrade()).To(BeFalse())
+			Expect(same.IsDowngrade()).To(BeFalse())
+		})
+
+		context("when nothing was cached", func() {
+			it("is neither", func() {
+				change := mri.VersionChange{To: "3.4.8"}
+				Expect(change.IsUpgrade()).To(BeFalse())
+				Expect(change.IsDowngrade()).To(BeFalse())
+			})
+		})
+	})
+
+	context("DowngradeError", func() {
+		it("explains how to resolve the downgrade", func() {
+			err := mri.DowngradeError{VersionChange: mri.VersionChange{From: "3.4.8", To: "3.3.11"}}
+			Expect(err).To(MatchError("MRI would be downgraded from 3.4.8 to 3.3.11 and $BP_MRI_DISALLOW_DOWNGRADE is set: request version 3.4.8 or later, or clear the build cache"))
+		})
+	})
+
+	context("LoadDisallowDowngradeConfig", func() {
+		it("allows downgrades by default", func() {
+			disallowed, err := mri.LoadDisallowDowngradeConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(disallowed).To(BeFalse())
+		})
+
+		it("reads $BP_MRI_DISALLOW_DOWNGRADE", func() {
+			t.Setenv("BP_MRI_DISALLOW_DOWNGRADE", "true")
+
+			disallowed, err := mri.LoadDisallowDowngradeConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(disallowed).To(BeTrue())
+		})
+
+		context("when $BP_MRI_DISALLOW_DOWNGRADE is invalid", func() {
+			it.Before(func() {
+				t.Setenv("BP_MRI_DISALLOW_DOWNGRADE", "some-bad-value")
+			})
+
+			it("returns an error", func() {
+				_, err := mri.LoadDisallowDowngradeConfig()
+				Expect(err).To(MatchError(ContainSubstring("invalid value for $BP_MRI_DISALLOW_DOWNGRADE")))
+			})
+		})
+	})
+}
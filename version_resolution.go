@@ -0,0 +1,184 @@
+package mri
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/paketo-buildpacks/packit/v2"
+)
+
+// The modes for resolving the MRI version when several build plan entries
+// request one.
+const (
+	// PriorityResolution uses the version of the entry whose source has the
+	// highest priority and ignores the others.
+	PriorityResolution = "priority"
+
+	// IntersectResolution uses the newest version that satisfies every entry.
+	IntersectResolution = "intersect"
+)
+
+var pessimisticOperator = regexp.MustCompile(`~>`)
+
+// LoadVersionResolution returns the version resolution mode given by
+// $BP_MRI_VERSION_RESOLUTION, which defaults to priority.
+func LoadVersionResolution() (string, error) {
+	value, ok := os.LookupEnv("BP_MRI_VERSION_RESOLUTION")
+	if !ok || value == "" {
+		return PriorityResolution, nil
+	}
+
+	switch value {
+	case PriorityResolution, IntersectResolution:
+		return value, nil
+	}
+
+	return "", fmt.Errorf("invalid value for $BP_MRI_VERSION_RESOLUTION: %q, expected %q or %q", value, PriorityResolution, IntersectResolution)
+}
+
+// VersionRequirement is a version constraint requested by a build plan entry.
+type VersionRequirement struct {
+	Source     string
+	Constraint string
+}
+
+// VersionRequirements returns the distinct version constraints requested by
+// the given build plan entries, in their order. Entries that do not request
+// a version are left out.
+func VersionRequirements(entries []packit.BuildpackPlanEntry) []VersionRequirement {
+	var requirements []VersionRequirement
+	for _, entry := range entries {
+		constraint, _ := entry.Metadata["version"].(string)
+		if constraint == "" {
+			continue
+		}
+
+		source, _ := entry.Metadata["version-source"].(string)
+		if source == "" {
+			source = "build plan"
+		}
+
+		requirement := VersionRequirement{Source: source, Constraint: constraint}
+		if !slices.Contains(requirements, requirement) {
+			requirements = append(requirements, requirement)
+		}
+	}
+
+	return requirements
+}
+
+// IncompatibleVersionsError explains why no MRI dependency in the
+// buildpack.toml satisfies every requested version constraint.
+type IncompatibleVersionsError struct {
+	Stack        string
+	OS           string
+	Arch         string
+	Requirements []VersionRequirement
+	Matches      [][]string
+	Available    []string
+}
+
+func (e IncompatibleVersionsError) Error() string {
+	lines := []string{
+		fmt.Sprintf("no MRI version on stack %q (%s/%s) satisfies every requested version constraint", e.Stack, e.OS, e.Arch),
+	}
+
+	for i, requirement := range e.Requirements {
+		matches := "no available version"
+		if len(e.Matches[i]) > 0 {
+			matches = strings.Join(e.Matches[i], ", ")
+		}
+
+		lines = append(lines, fmt.Sprintf("  %s requests %q, satisfied by %s", requirement.Source, requirement.Constraint, matches))
+	}
+
+	if len(e.Available) == 0 {
+		lines = append(lines, "  No MRI versions are available for this stack and architecture")
+	} else {
+		lines = append(lines, fmt.Sprintf("  Available versions: %s", strings.Join(e.Available, ", ")))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Intersect returns the newest available version that satisfies every given
+// requirement. Aliases are resolved to the version they refer to and the
+// pessimistic operator (~>) is interpreted as postal does.
+func (c DependencyCatalog) Intersect(requirements []VersionRequirement, now time.Time) (string, error) {
+	var constraints []*semver.Constraints
+	for _, requirement := range requirements {
+		constraint := requirement.Constraint
+		if IsVersionAlias(constraint) {
+			version, err := c.ResolveAlias(constraint, now)
+			if err != nil {
+				return "", fmt.Errorf("failed to resolve the version requested by %s: %w", requirement.Source, err)
+			}
+			constraint = version
+		}
+
+		versionConstraint, err := parseVersionConstraint(constraint)
+		if err != nil {
+			return "", fmt.Errorf("invalid version constraint %q requested by %s: %w", requirement.Constraint, requirement.Source, err)
+		}
+
+		constraints = append(constraints, versionConstraint)
+	}
+
+	incompatible := IncompatibleVersionsError{
+		Stack:        c.Stack,
+		OS:           c.OS,
+		Arch:         c.Arch,
+		Requirements: requirements,
+		Matches:      make([][]string, len(requirements)),
+	}
+
+	var intersection []string
+	for _, dependency := range c.Dependencies {
+		if slices.Contains(incompatible.Available, dependency.Version) {
+			continue
+		}
+		incompatible.Available = append(incompatible.Available, dependency.Version)
+
+		version := semver.MustParse(dependency.Version)
+		satisfiesAll := true
+		for i, constraint := range constraints {
+			if constraint.Check(version) {
+				incompatible.Matches[i] = append(incompatible.Matches[i], dependency.Version)
+			} else {
+				satisfiesAll = false
+			}
+		}
+
+		if satisfiesAll {
+			intersection = append(intersection, dependency.Version)
+		}
+	}
+
+	if len(intersection) == 0 {
+		return "", incompatible
+	}
+
+	// The dependencies are sorted by version, so the last one is the newest.
+	return intersection[len(intersection)-1], nil
+}
+
+// parseVersionConstraint parses a semver constraint, translating the
+// pessimistic operator (~>) into a tilde range when a patch version is given
+// and a caret range otherwise.
+func parseVersionConstraint(constraint string) (*semver.Constraints, error) {
+	if pessimisticOperator.MatchString(constraint) {
+		version := strings.TrimSpace(pessimisticOperator.ReplaceAllString(constraint, ""))
+		if len(strings.Split(version, ".")) == 3 {
+			constraint = "~" + version
+		} else {
+			constraint = "^" + version
+		}
+	}
+
+	return semver.NewConstraint(constraint)
+}
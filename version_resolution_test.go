@@ -0,0 +1,165 @@
+package mri_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/paketo-buildpacks/mri"
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testVersionResolution(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		cnbDir  string
+		now     time.Time
+		catalog mri.DependencyCatalog
+	)
+
+	it.Before(func() {
+		var err error
+		cnbDir, err = os.MkdirTemp("", "cnb")
+		Expect(err).NotTo(HaveOccurred())
+
+		path := filepath.Join(cnbDir, "buildpack.toml")
+		Expect(os.WriteFile(path, []byte(`api = "0.7"
+
+[metadata]
+  [[metadata.dependencies]]
+    id = "ruby"
+    version = "3.3.10"
+    stacks = ["io.buildpacks.stacks.jammy"]
+
+  [[metadata.dependencies]]
+    id = "ruby"
+    version = "3.3.11"
+    stacks = ["io.buildpacks.stacks.jammy"]
+
+  [[metadata.dependencies]]
+    id = "ruby"
+    version = "3.4.8"
+    stacks = ["io.buildpacks.stacks.jammy"]
+`), 0600)).To(Succeed())
+
+		t.Setenv("CNB_TARGET_OS", "linux")
+		t.Setenv("CNB_TARGET_ARCH", "amd64")
+
+		catalog, err = mri.NewDependencyCatalog(path, "ruby", "io.buildpacks.stacks.jammy")
+		Expect(err).NotTo(HaveOccurred())
+
+		now = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(cnbDir)).To(Succeed())
+	})
+
+	context("LoadVersionResolution", func() {
+		it("defaults to priority", func() {
+			resolution, err := mri.LoadVersionResolution()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resolution).To(Equal(mri.PriorityResolution))
+		})
+
+		it("reads $BP_MRI_VERSION_RESOLUTION", func() {
+			t.Setenv("BP_MRI_VERSION_RESOLUTION", "intersect")
+
+			resolution, err := mri.LoadVersionResolution()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resolution).To(Equal(mri.IntersectResolution))
+		})
+
+		context("when $BP_MRI_VERSION_RESOLUTION is invalid", func() {
+			it.Before(func() {
+				t.Setenv("BP_MRI_VERSION_RESOLUTION", "union")
+			})
+
+			it("returns an error", func() {
+				_, err := mri.LoadVersionResolution()
+				Expect(err).To(MatchError(`invalid value for $BP_MRI_VERSION_RESOLUTION: "union", expected "priority" or "intersect"`))
+			})
+		})
+	})
+
+	context("VersionRequirements", func() {
+		it("returns the distinct constraints of the entries that request a version", func() {
+			requirements := mri.VersionRequirements([]packit.BuildpackPlanEntry{
+				{Name: "mri", Metadata: map[string]interface{}{"version": "3.*", "version-source": "BP_MRI_VERSION"}},
+				{Name: "mri", Metadata: map[string]interface{}{"version": "3.3.*", "version-source": "Gemfile"}},
+				{Name: "mri", Metadata: map[string]interface{}{"version": "3.3.*", "version-source": "Gemfile"}},
+				{Name: "mri", Metadata: map[string]interface{}{"version": "~> 3.3"}},
+				{Name: "mri", Metadata: map[string]interface{}{"build": true}},
+			})
+
+			Expect(requirements).To(Equal([]mri.VersionRequirement{
+				{Source: "BP_MRI_VERSION", Constraint: "3.*"},
+				{Source: "Gemfile", Constraint: "3.3.*"},
+				{Source: "build plan", Constraint: "~> 3.3"},
+			}))
+		})
+	})
+
+	context("Intersect", func() {
+		it("returns the newest version that satisfies every requirement", func() {
+			version, err := catalog.Intersect([]mri.VersionRequirement{
+				{Source: "BP_MRI_VERSION", Constraint: "3.*"},
+				{Source: "Gemfile", Constraint: "3.3.*"},
+			}, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal("3.3.11"))
+		})
+
+		it("supports the pessimistic operator and aliases", func() {
+			version, err := catalog.Intersect([]mri.VersionRequirement{
+				{Source: "BP_MRI_VERSION", Constraint: "~> 3.3.0"},
+				{Source: "build plan", Constraint: "<= 3.3.10"},
+			}, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal("3.3.10"))
+
+			version, err = catalog.Intersect([]mri.VersionRequirement{
+				{Source: "BP_MRI_VERSION", Constraint: "latest"},
+				{Source: "Gemfile", Constraint: "3.*"},
+			}, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal("3.4.8"))
+		})
+
+		context("failure cases", func() {
+			context("when no version satisfies every requirement", func() {
+				it("explains which versions satisfy each requirement", func() {
+					_, err := catalog.Intersect([]mri.VersionRequirement{
+						{Source: "BP_MRI_VERSION", Constraint: "3.4.*"},
+						{Source: "Gemfile", Constraint: "3.3.*"},
+						{Source: "build plan", Constraint: "2.7.*"},
+					}, now)
+
+					var incompatible mri.IncompatibleVersionsError
+					Expect(errors.As(err, &incompatible)).To(BeTrue())
+					Expect(err).To(MatchError(`no MRI version on stack "io.buildpacks.stacks.jammy" (linux/amd64) satisfies every requested version constraint
+  BP_MRI_VERSION requests "3.4.*", satisfied by 3.4.8
+  Gemfile requests "3.3.*", satisfied by 3.3.10, 3.3.11
+  build plan requests "2.7.*", satisfied by no available version
+  Available versions: 3.3.10, 3.3.11, 3.4.8`))
+				})
+			})
+
+			context("when a constraint is invalid", func() {
+				it("returns an error", func() {
+					_, err := catalog.Intersect([]mri.VersionRequirement{
+						{Source: "BP_MRI_VERSION", Constraint: "3.*"},
+						{Source: "Gemfile", Constraint: "not-a-constraint"},
+					}, now)
+					Expect(err).To(MatchError(ContainSubstring(`invalid version constraint "not-a-constraint" requested by Gemfile`)))
+				})
+			})
+		})
+	})
+}
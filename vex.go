@@ -0,0 +1,178 @@
+package mri
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Masterminds/semver"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+)
+
+// VEXFile is the name of the file, next to the buildpack.toml, that holds the
+// curated VEX statements about vulnerabilities in the MRI dependencies.
+const VEXFile = "vex.toml"
+
+// The analysis states and justifications of a VEX statement, as defined by
+// CycloneDX.
+var (
+	vexStates = []string{
+		"resolved",
+		"resolved_with_pedigree",
+		"exploitable",
+		"in_triage",
+		"false_positive",
+		"not_affected",
+	}
+
+	vexJustifications = []string{
+		"code_not_present",
+		"code_not_reachable",
+		"requires_configuration",
+		"requires_dependency",
+		"requires_environment",
+		"protected_by_compiler",
+		"protected_at_runtime",
+		"protected_at_perimeter",
+		"protected_by_mitigating_control",
+	}
+)
+
+// VEXStatement is the analysis of a vulnerability for the dependencies that
+// match its CPE and version constraint. An empty version constraint matches
+// every version.
+type VEXStatement struct {
+	ID            string `toml:"id" json:"id"`
+	CPE           string `toml:"cpe" json:"cpe"`
+	Versions      string `toml:"versions" json:"versions,omitempty"`
+	State         string `toml:"state" json:"state"`
+	Justification string `toml:"justification" json:"justification,omitempty"`
+	Detail        string `toml:"detail" json:"detail,omitempty"`
+	Source        string `toml:"source" json:"source,omitempty"`
+}
+
+// VEXDocument is the content of the VEX file.
+type VEXDocument struct {
+	Statements []VEXStatement `toml:"statements"`
+}
+
+// LoadVEX reads and validates the VEX file at the given path. A missing file
+// holds no statements.
+func LoadVEX(path string) (VEXDocument, error) {
+	var document VEXDocument
+	_, err := toml.DecodeFile(path, &document)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return VEXDocument{}, nil
+		}
+		return VEXDocument{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	for _, statement := range document.Statements {
+		err := statement.validate()
+		if err != nil {
+			return VEXDocument{}, fmt.Errorf("invalid VEX statement %q in %s: %w", statement.ID, path, err)
+		}
+	}
+
+	return document, nil
+}
+
+func (s VEXStatement) validate() error {
+	if s.ID == "" {
+		return fmt.Errorf("missing id")
+	}
+
+	if len(strings.Split(s.CPE, ":")) != 13 || !strings.HasPrefix(s.CPE, "cpe:2.3:") {
+		return fmt.Errorf("cpe %q is not a CPE 2.3 name", s.CPE)
+	}
+
+	if s.Versions != "" {
+		_, err := parseVersionConstraint(s.Versions)
+		if err != nil {
+			return fmt.Errorf("invalid versions %q: %w", s.Versions, err)
+		}
+	}
+
+	if !slices.Contains(vexStates, s.State) {
+		return fmt.Errorf("state %q is not one of %s", s.State, strings.Join(vexStates, ", "))
+	}
+
+	if s.Justification != "" && !slices.Contains(vexJustifications, s.Justification) {
+		return fmt.Errorf("justification %q is not one of %s", s.Justification, strings.Join(vexJustifications, ", "))
+	}
+
+	return nil
+}
+
+// Applicable returns the statements about the given dependency, sorted by
+// vulnerability ID. A statement applies when its CPE matches one of the CPEs
+// of the dependency and the version of the dependency satisfies its version
+// constraint.
+func (d VEXDocument) Applicable(dependency postal.Dependency) []VEXStatement {
+	cpes := dependency.CPEs
+	if len(cpes) == 0 {
+		//nolint Ignore SA1019, informed usage of deprecated field
+		cpes = []string{dependency.CPE}
+	}
+
+	version, err := semver.NewVersion(dependency.Version)
+	if err != nil {
+		return nil
+	}
+
+	var statements []VEXStatement
+	for _, statement := range d.Statements {
+		if !slices.ContainsFunc(cpes, func(cpe string) bool { return cpeMatches(statement.CPE, cpe) }) {
+			continue
+		}
+
+		if statement.Versions != "" {
+			constraint, err := parseVersionConstraint(statement.Versions)
+			if err != nil || !constraint.Check(version) {
+				continue
+			}
+		}
+
+		statements = append(statements, statement)
+	}
+
+	sort.SliceStable(statements, func(i, j int) bool {
+		return statements[i].ID < statements[j].ID
+	})
+
+	return statements
+}
+
+// cpeMatches reports whether the given CPE 2.3 name matches the pattern,
+// where a * component of the pattern matches any value.
+func cpeMatches(pattern, cpe string) bool {
+	patternParts := strings.Split(pattern, ":")
+	cpeParts := strings.Split(cpe, ":")
+	if len(patternParts) != len(cpeParts) {
+		return false
+	}
+
+	for i, part := range patternParts {
+		if part != "*" && part != cpeParts[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// vexInput identifies the given statements in the cache inputs of the MRI
+// layer, so that its SBOM is regenerated when they change.
+func vexInput(statements []VEXStatement) (string, error) {
+	content, err := json.Marshal(statements)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
@@ -0,0 +1,153 @@
+package mri_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/mri"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testVEX(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	it.Before(func() {
+		dir := t.TempDir()
+		path = filepath.Join(dir, "vex.toml")
+
+		Expect(os.WriteFile(path, []byte(`
+[[statements]]
+  id = "CVE-2025-0002"
+  cpe = "cpe:2.3:a:ruby-lang:ruby:*:*:*:*:*:*:*:*"
+  versions = "3.3.*"
+  state = "not_affected"
+  justification = "requires_environment"
+  detail = "Only affects Ruby on Windows."
+  source = "https://www.ruby-lang.org/en/news/some-advisory/"
+
+[[statements]]
+  id = "CVE-2025-0001"
+  cpe = "cpe:2.3:a:ruby-lang:ruby:*:*:*:*:*:*:*:*"
+  state = "in_triage"
+
+[[statements]]
+  id = "CVE-2025-0003"
+  cpe = "cpe:2.3:a:some-vendor:some-product:*:*:*:*:*:*:*:*"
+  state = "false_positive"
+`), 0600)).To(Succeed())
+	})
+
+	context("LoadVEX", func() {
+		it("reads the statements", func() {
+			document, err := mri.LoadVEX(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(document.Statements).To(HaveLen(3))
+			Expect(document.Statements[0]).To(Equal(mri.VEXStatement{
+				ID:            "CVE-2025-0002",
+				CPE:           "cpe:2.3:a:ruby-lang:ruby:*:*:*:*:*:*:*:*",
+				Versions:      "3.3.*",
+				State:         "not_affected",
+				Justification: "requires_environment",
+				Detail:        "Only affects Ruby on Windows.",
+				Source:        "https://www.ruby-lang.org/en/news/some-advisory/",
+			}))
+		})
+
+		context("when the file does not exist", func() {
+			it("holds no statements", func() {
+				document, err := mri.LoadVEX(filepath.Join(t.TempDir(), "vex.toml"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(document.Statements).To(BeEmpty())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the file is malformed", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(path, []byte("%%%"), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := mri.LoadVEX(path)
+					Expect(err).To(MatchError(ContainSubstring("failed to parse")))
+				})
+			})
+
+			for _, testCase := range []struct {
+				name      string
+				statement string
+				message   string
+			}{
+				{"the id is missing", `cpe = "cpe:2.3:a:ruby-lang:ruby:*:*:*:*:*:*:*:*"` + "\n" + `state = "in_triage"`, "missing id"},
+				{"the cpe is invalid", `id = "CVE-1"` + "\n" + `cpe = "ruby"` + "\n" + `state = "in_triage"`, `cpe "ruby" is not a CPE 2.3 name`},
+				{"the versions are invalid", `id = "CVE-1"` + "\n" + `cpe = "cpe:2.3:a:ruby-lang:ruby:*:*:*:*:*:*:*:*"` + "\n" + `versions = "not a version"` + "\n" + `state = "in_triage"`, `invalid versions "not a version"`},
+				{"the state is invalid", `id = "CVE-1"` + "\n" + `cpe = "cpe:2.3:a:ruby-lang:ruby:*:*:*:*:*:*:*:*"` + "\n" + `state = "fine"`, `state "fine" is not one of`},
+				{"the justification is invalid", `id = "CVE-1"` + "\n" + `cpe = "cpe:2.3:a:ruby-lang:ruby:*:*:*:*:*:*:*:*"` + "\n" + `state = "not_affected"` + "\n" + `justification = "trust me"`, `justification "trust me" is not one of`},
+			} {
+				testCase := testCase
+
+				context("when "+testCase.name, func() {
+					it.Before(func() {
+						Expect(os.WriteFile(path, []byte("[[statements]]\n"+testCase.statement+"\n"), 0600)).To(Succeed())
+					})
+
+					it("returns an error", func() {
+						_, err := mri.LoadVEX(path)
+						Expect(err).To(MatchError(ContainSubstring("invalid VEX statement")))
+						Expect(err).To(MatchError(ContainSubstring(testCase.message)))
+					})
+				})
+			}
+		})
+	})
+
+	context("Applicable", func() {
+		var document mri.VEXDocument
+
+		it.Before(func() {
+			var err error
+			document, err = mri.LoadVEX(path)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it("returns the statements that match the CPE and version, sorted by ID", func() {
+			statements := document.Applicable(postal.Dependency{
+				Version: "3.3.10",
+				CPEs:    []string{"cpe:2.3:a:ruby-lang:ruby:3.3.10:*:*:*:*:*:*:*"},
+			})
+
+			var ids []string
+			for _, statement := range statements {
+				ids = append(ids, statement.ID)
+			}
+			Expect(ids).To(Equal([]string{"CVE-2025-0001", "CVE-2025-0002"}))
+		})
+
+		it("leaves out statements for other versions", func() {
+			statements := document.Applicable(postal.Dependency{
+				Version: "3.4.8",
+				CPEs:    []string{"cpe:2.3:a:ruby-lang:ruby:3.4.8:*:*:*:*:*:*:*"},
+			})
+
+			Expect(statements).To(HaveLen(1))
+			Expect(statements[0].ID).To(Equal("CVE-2025-0001"))
+		})
+
+		it("leaves out statements for other CPEs", func() {
+			statements := document.Applicable(postal.Dependency{
+				Version: "3.4.8",
+				CPEs:    []string{"cpe:2.3:a:other-vendor:ruby:3.4.8:*:*:*:*:*:*:*"},
+			})
+
+			Expect(statements).To(BeEmpty())
+		})
+	})
+}
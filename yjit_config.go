@@ -0,0 +1,71 @@
+package mri
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/Masterminds/semver"
+)
+
+// YJITConfig describes whether YJIT is enabled at launch and how much
+// executable memory, in MiB, it may use.
+type YJITConfig struct {
+	Enabled     bool
+	ExecMemSize int
+}
+
+// LoadYJITConfig reads the configuration from $BP_MRI_YJIT and
+// $BP_MRI_YJIT_EXEC_MEM_SIZE.
+func LoadYJITConfig() (YJITConfig, error) {
+	var config YJITConfig
+
+	if value, ok := os.LookupEnv("BP_MRI_YJIT"); ok {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return YJITConfig{}, fmt.Errorf("invalid value for $BP_MRI_YJIT: %w", err)
+		}
+		config.Enabled = enabled
+	}
+
+	if value, ok := os.LookupEnv("BP_MRI_YJIT_EXEC_MEM_SIZE"); ok {
+		size, err := strconv.Atoi(value)
+		if err != nil || size <= 0 {
+			return YJITConfig{}, fmt.Errorf("invalid value for $BP_MRI_YJIT_EXEC_MEM_SIZE: %q is not a positive number of MiB", value)
+		}
+		config.ExecMemSize = size
+	}
+
+	return config, nil
+}
+
+// String returns a stable description of the configuration that is recorded
+// in the layer metadata.
+func (c YJITConfig) String() string {
+	switch {
+	case !c.Enabled:
+		return "disabled"
+	case c.ExecMemSize > 0:
+		return fmt.Sprintf("enabled (exec-mem-size=%d)", c.ExecMemSize)
+	default:
+		return "enabled"
+	}
+}
+
+// SupportsYJIT reports whether YJIT is available for the given MRI version
+// and architecture. YJIT is built into MRI 3.2 and later, and only supports
+// x86_64 and arm64.
+func SupportsYJIT(version, arch string) bool {
+	arch = targetArch(arch)
+
+	if arch != "amd64" && arch != "arm64" {
+		return false
+	}
+
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return false
+	}
+
+	return !v.LessThan(semver.MustParse("3.2.0"))
+}
@@ -0,0 +1,92 @@
+package mri_test
+
+import (
+	"testing"
+
+	"github.com/paketo-buildpacks/mri"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testYJITConfig(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("LoadYJITConfig", func() {
+		it("is disabled by default", func() {
+			config, err := mri.LoadYJITConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config).To(Equal(mri.YJITConfig{}))
+			Expect(config.String()).To(Equal("disabled"))
+		})
+
+		context("when YJIT is enabled", func() {
+			it.Before(func() {
+				t.Setenv("BP_MRI_YJIT", "true")
+			})
+
+			it("reads the configuration", func() {
+				config, err := mri.LoadYJITConfig()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(config).To(Equal(mri.YJITConfig{Enabled: true}))
+				Expect(config.String()).To(Equal("enabled"))
+			})
+
+			context("when the executable memory size is set", func() {
+				it.Before(func() {
+					t.Setenv("BP_MRI_YJIT_EXEC_MEM_SIZE", "128")
+				})
+
+				it("reads the size", func() {
+					config, err := mri.LoadYJITConfig()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(config).To(Equal(mri.YJITConfig{Enabled: true, ExecMemSize: 128}))
+					Expect(config.String()).To(Equal("enabled (exec-mem-size=128)"))
+				})
+			})
+		})
+
+		context("failure cases", func() {
+			context("when BP_MRI_YJIT is not a boolean", func() {
+				it.Before(func() {
+					t.Setenv("BP_MRI_YJIT", "sometimes")
+				})
+
+				it("returns an error", func() {
+					_, err := mri.LoadYJITConfig()
+					Expect(err).To(MatchError(ContainSubstring("invalid value for $BP_MRI_YJIT")))
+				})
+			})
+
+			context("when BP_MRI_YJIT_EXEC_MEM_SIZE is not a positive number", func() {
+				it.Before(func() {
+					t.Setenv("BP_MRI_YJIT_EXEC_MEM_SIZE", "0")
+				})
+
+				it("returns an error", func() {
+					_, err := mri.LoadYJITConfig()
+					Expect(err).To(MatchError(`invalid value for $BP_MRI_YJIT_EXEC_MEM_SIZE: "0" is not a positive number of MiB`))
+				})
+			})
+		})
+	})
+
+	context("SupportsYJIT", func() {
+		it("supports MRI 3.2 and later on amd64 and arm64", func() {
+			Expect(mri.SupportsYJIT("3.2.0", "amd64")).To(BeTrue())
+			Expect(mri.SupportsYJIT("3.4.8", "arm64")).To(BeTrue())
+			Expect(mri.SupportsYJIT("3.1.7", "amd64")).To(BeFalse())
+			Expect(mri.SupportsYJIT("3.4.8", "ppc64le")).To(BeFalse())
+		})
+
+		context("when the dependency does not declare an architecture", func() {
+			it.Before(func() {
+				t.Setenv("CNB_TARGET_ARCH", "s390x")
+			})
+
+			it("uses the target architecture", func() {
+				Expect(mri.SupportsYJIT("3.4.8", "")).To(BeFalse())
+			})
+		})
+	})
+}